@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"os"
 	"strconv"
 	"time"
 
@@ -10,7 +11,9 @@ import (
 	"api-employees-and-departments/internal/db"
 	"api-employees-and-departments/internal/domain/department"
 	"api-employees-and-departments/internal/domain/employee"
+	"api-employees-and-departments/internal/domain/search"
 	infraCache "api-employees-and-departments/internal/infrastructure/cache"
+	"api-employees-and-departments/internal/infrastructure/encryption"
 	ginapi "api-employees-and-departments/internal/infrastructure/http/gin"
 	"api-employees-and-departments/internal/infrastructure/logging"
 	"api-employees-and-departments/internal/infrastructure/persistence"
@@ -44,7 +47,7 @@ func main() {
 		logging.Fatal("Failed to load config", zap.Error(err))
 	}
 
-	if err := logging.InitLogger(cfg.AppEnv, cfg.LogLevel); err != nil {
+	if err := logging.InitLogger(cfg.AppEnv, cfg.LogLevel, cfg.LogRedactFields...); err != nil {
 		logging.Fatal("Failed to initialize logger", zap.Error(err))
 	}
 	defer logging.Sync()
@@ -55,6 +58,13 @@ func main() {
 		zap.String("port", cfg.Port),
 	)
 
+	// CPFs and RGs are encrypted at rest
+	keyring, err := encryption.ParseKeyring(cfg.PIIEncryptionKeys, cfg.PIIBlindIndexKey)
+	if err != nil {
+		logging.Fatal("Failed to load encryption keys", zap.Error(err))
+	}
+	persistence.RegisterEncryption(keyring)
+
 	database, err := db.Connect(cfg)
 	if err != nil {
 		logging.Fatal("Failed to connect to database", zap.Error(err))
@@ -65,6 +75,29 @@ func main() {
 		zap.String("database", cfg.DBName),
 	)
 
+	// "main reencrypt" rewrites the stored documents with the active key and exits
+	if len(os.Args) > 1 && os.Args[1] == "reencrypt" {
+		if err := reencrypt(database, keyring, os.Args[2:]); err != nil {
+			logging.Fatal("Re-encryption failed", zap.Error(err))
+		}
+		return
+	}
+
+	// V6 moved the uniqueness of CPFs and RGs to the blind indexes, so rows
+	// without them are indexed before serving rather than let duplicates in
+	unindexed, err := persistence.CountUnindexedEmployees(database)
+	if err != nil {
+		logging.Fatal("Failed to check the document indexes", zap.Error(err))
+	}
+	if unindexed > 0 {
+		logging.Warn("Employees without document indexes; encrypting and indexing them before serving",
+			zap.Int64("employees", unindexed),
+		)
+		if err := reencrypt(database, keyring, nil); err != nil {
+			logging.Fatal("Re-encryption failed", zap.Error(err))
+		}
+	}
+
 	// Connect to Redis for caching
 	redisClient, err := infraCache.NewRedisClient(cfg)
 	if err != nil {
@@ -94,24 +127,38 @@ func main() {
 	// See docker-compose.yml for Flyway configuration
 
 	// Initialize repositories
-	employeeRepo := persistence.NewEmployeeRepository(database)
+	employeeRepo := persistence.NewEmployeeRepository(database, keyring)
 	departmentRepo := persistence.NewDepartmentRepository(database)
+	searchRepo := persistence.NewSearchRepository(database, keyring)
 
-	// Create adapter for employee repository
+	// Create adapters so each domain only sees the slice of the other it needs
 	employeeAdapter := persistence.NewEmployeeAdapter(employeeRepo.(*persistence.EmployeeRepository))
+	departmentAdapter := persistence.NewDepartmentAdapter(departmentRepo.(*persistence.DepartmentRepository))
 
 	// Create domain loggers with context for each service (DIP - Dependency Inversion Principle)
 	employeeLogger := logging.NewZapLogger(logging.GetLogger().With(zap.String("service", "employee")))
 	departmentLogger := logging.NewZapLogger(logging.GetLogger().With(zap.String("service", "department")))
 
 	// Initialize services with logger and cache injection (DIP applied)
-	employeeService := employee.NewService(employeeRepo, employeeLogger)
+	employeeService := employee.NewService(employeeRepo, departmentAdapter, employeeLogger)
 	departmentService := department.NewService(departmentRepo, employeeAdapter, departmentLogger, cache, cacheTTL)
+	searchService := search.NewService(searchRepo)
+
+	// Cached department hierarchies carry headcounts, so employee changes must reach them
+	employeeService.SetHeadcountObserver(departmentService)
+
+	// Deleted employees keep their personal data for the retention period
+	retentionDays, err := strconv.Atoi(cfg.RetentionDays)
+	if err != nil || retentionDays < 0 {
+		logging.Fatal("Invalid LGPD_RETENTION_DAYS", zap.String("value", cfg.RetentionDays))
+	}
+	employeeService.SetRetentionPeriod(time.Duration(retentionDays) * 24 * time.Hour)
 
 	// Initialize handlers
 	employeeHandler := ginapi.NewEmployeeHandler(employeeService)
 	departmentHandler := ginapi.NewDepartmentHandler(departmentService)
-	managerHandler := ginapi.NewManagerHandler(departmentService, employeeService)
+	managerHandler := ginapi.NewManagerHandler(employeeService)
+	searchHandler := ginapi.NewSearchHandler(searchService)
 
 	// Setup Gin router (using New instead of Default to use custom middlewares)
 	router := gin.New()
@@ -121,6 +168,8 @@ func main() {
 		EmployeeHandler:   employeeHandler,
 		DepartmentHandler: departmentHandler,
 		ManagerHandler:    managerHandler,
+		SearchHandler:     searchHandler,
+		PIIAPIKeys:        cfg.PIIAPIKeys,
 	})
 
 	// Start server
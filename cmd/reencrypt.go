@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"
+	"flag"
+
+	"api-employees-and-departments/internal/infrastructure/encryption"
+	"api-employees-and-departments/internal/infrastructure/logging"
+	"api-employees-and-departments/internal/infrastructure/persistence"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// reencrypt rewrites the CPFs and RGs of every employee with the active key,
+// after a new key is put first in PII_ENCRYPTION_KEYS or when encryption is
+// first enabled over plaintext data:
+//
+//	main reencrypt [-batch-size 500]
+//
+// Retired keys must stay configured until it finishes.
+func reencrypt(database *gorm.DB, keyring *encryption.Keyring, args []string) error {
+	flags := flag.NewFlagSet("reencrypt", flag.ContinueOnError)
+	batchSize := flags.Int("batch-size", 500, "employees re-encrypted per transaction")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *batchSize <= 0 {
+		return errors.New("batch-size must be positive")
+	}
+
+	logging.Info("Re-encrypting employee documents",
+		zap.String("active_key", keyring.ActiveKeyID()),
+		zap.Int("batch_size", *batchSize),
+	)
+
+	stats, err := persistence.ReencryptEmployees(database, keyring, *batchSize, func(stats persistence.ReencryptStats) {
+		logging.Info("Re-encryption progress",
+			zap.Int64("scanned", stats.Scanned),
+			zap.Int64("updated", stats.Updated),
+		)
+	})
+	if err != nil {
+		return err
+	}
+
+	logging.Info("Re-encryption finished",
+		zap.Int64("scanned", stats.Scanned),
+		zap.Int64("updated", stats.Updated),
+	)
+	return nil
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"strings"
 )
 
 type Config struct {
@@ -20,6 +21,18 @@ type Config struct {
 	RedisPassword string
 	RedisDB       string
 	CacheTTL      string
+	// PIIAPIKeys are the API keys allowed to see personal data, such as CPFs, in full
+	PIIAPIKeys []string
+	// LogRedactFields are log field keys masked on top of cpf and rg
+	LogRedactFields []string
+	// PIIEncryptionKeys are the AES-256 keys encrypting CPFs and RGs at rest, as
+	// comma-separated id:base64 pairs with the active key first
+	PIIEncryptionKeys string
+	// PIIBlindIndexKey is the base64 HMAC key of the CPF and RG lookup indexes
+	PIIBlindIndexKey string
+	// RetentionDays is how long the data of a deleted employee is kept before
+	// it can be anonymized
+	RetentionDays string
 }
 
 func Load() (*Config, error) {
@@ -38,6 +51,14 @@ func Load() (*Config, error) {
 		RedisPassword: getenv("REDIS_PASSWORD", ""),
 		RedisDB:       getenv("REDIS_DB", "0"),
 		CacheTTL:      getenv("CACHE_TTL_SECONDS", "300"),
+		PIIAPIKeys:    splitList(os.Getenv("PII_API_KEYS")),
+
+		LogRedactFields: splitList(os.Getenv("LOG_REDACT_FIELDS")),
+
+		PIIEncryptionKeys: os.Getenv("PII_ENCRYPTION_KEYS"),
+		PIIBlindIndexKey:  os.Getenv("PII_BLIND_INDEX_KEY"),
+
+		RetentionDays: getenv("LGPD_RETENTION_DAYS", "0"),
 	}
 	return c, nil
 }
@@ -49,6 +70,17 @@ func getenv(key, def string) string {
 	return def
 }
 
+// splitList splits a comma-separated variable, skipping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func (c *Config) DSN() string {
 	return fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=%s",
 		c.DBHost, c.DBPort, c.DBUser, c.DBName, c.DBPassword, c.DBSSLMode)
@@ -0,0 +1,18 @@
+-- V3__partial_unique_cpf_rg.sql
+-- CPF and RG only need to be unique among active employees, so a rehired person
+-- whose previous record was soft-deleted can be registered again
+
+-- Drop the table-wide unique constraints from V1
+ALTER TABLE employees DROP CONSTRAINT IF EXISTS uk_cpf;
+ALTER TABLE employees DROP CONSTRAINT IF EXISTS uk_rg;
+
+-- Uniqueness restricted to rows that are not soft-deleted
+CREATE UNIQUE INDEX IF NOT EXISTS uk_employees_cpf_active
+    ON employees(cpf) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS uk_employees_rg_active
+    ON employees(rg) WHERE rg IS NOT NULL AND deleted_at IS NULL;
+
+-- idx_employees_cpf (V1) is kept for rehire lookups across deleted records
+
+COMMENT ON COLUMN employees.cpf IS 'Brazilian CPF - must be valid and unique among active employees';
+COMMENT ON COLUMN employees.rg IS 'Brazilian RG - optional but must be unique among active employees if provided';
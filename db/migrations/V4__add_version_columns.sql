@@ -0,0 +1,9 @@
+-- V4__add_version_columns.sql
+-- Row versions for optimistic concurrency: every write bumps the version, and
+-- the API exposes it as the ETag checked by If-Match / If-None-Match
+
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE departments ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+
+COMMENT ON COLUMN employees.version IS 'Row version, incremented on every update (optimistic locking)';
+COMMENT ON COLUMN departments.version IS 'Row version, incremented on every update (optimistic locking)';
@@ -0,0 +1,27 @@
+-- V5__search_unaccent_trgm.sql
+-- Accent-insensitive fuzzy search: names are compared as f_unaccent(name), so
+-- "Joao" finds "João", and trigram indexes serve both ILIKE '%...%' and the
+-- similarity operators of /search
+
+CREATE EXTENSION IF NOT EXISTS unaccent;
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- unaccent() is only STABLE, since it looks its dictionary up through the search
+-- path, and cannot be indexed; pinning the dictionary makes it IMMUTABLE
+CREATE OR REPLACE FUNCTION f_unaccent(text) RETURNS text
+    LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT
+    AS $$ SELECT public.unaccent('public.unaccent'::regdictionary, $1) $$;
+
+CREATE INDEX IF NOT EXISTS idx_employees_name_trgm
+    ON employees USING gin (f_unaccent(name) gin_trgm_ops) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_departments_name_trgm
+    ON departments USING gin (f_unaccent(name) gin_trgm_ops) WHERE deleted_at IS NULL;
+
+-- Prefix searches on documents; RGs are compared without their punctuation
+CREATE INDEX IF NOT EXISTS idx_employees_cpf_prefix
+    ON employees (cpf text_pattern_ops) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_employees_rg_prefix
+    ON employees (upper(regexp_replace(rg, '[^0-9A-Za-z]', '', 'g')) text_pattern_ops)
+    WHERE rg IS NOT NULL AND deleted_at IS NULL;
+
+COMMENT ON FUNCTION f_unaccent(text) IS 'Immutable unaccent(), for indexes and accent-insensitive comparisons';
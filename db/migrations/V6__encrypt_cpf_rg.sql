@@ -0,0 +1,39 @@
+-- V6__encrypt_cpf_rg.sql
+-- CPF and RG are encrypted by the application (AES-GCM, "enc:<key id>:<base64>")
+-- and looked up through keyed HMAC blind indexes, which take over the lookup
+-- and uniqueness indexes of the plaintext columns.
+--
+-- The keys only exist in the application, so existing rows are encrypted and
+-- indexed by the application itself: on startup, before serving, it runs the
+-- same pass as "main reencrypt" over every row whose cpf_hash (or rg_hash, when
+-- it has an RG) is still NULL, since until then such rows are not found by CPF
+-- or RG and not covered by the uniqueness indexes.
+
+-- Ciphertext is longer than the documents themselves
+ALTER TABLE employees ALTER COLUMN cpf TYPE TEXT;
+ALTER TABLE employees ALTER COLUMN rg TYPE TEXT;
+
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS cpf_hash VARCHAR(64);
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS rg_hash VARCHAR(64);
+
+-- Indexes on the plaintext columns (V1, V3 and V5) no longer match anything
+DROP INDEX IF EXISTS uk_employees_cpf_active;
+DROP INDEX IF EXISTS uk_employees_rg_active;
+DROP INDEX IF EXISTS idx_employees_cpf;
+DROP INDEX IF EXISTS idx_employees_rg;
+DROP INDEX IF EXISTS idx_employees_cpf_prefix;
+DROP INDEX IF EXISTS idx_employees_rg_prefix;
+
+-- Uniqueness among active employees, as in V3
+CREATE UNIQUE INDEX IF NOT EXISTS uk_employees_cpf_hash_active
+    ON employees(cpf_hash) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS uk_employees_rg_hash_active
+    ON employees(rg_hash) WHERE rg_hash IS NOT NULL AND deleted_at IS NULL;
+
+-- Rehire lookups across deleted records
+CREATE INDEX IF NOT EXISTS idx_employees_cpf_hash ON employees(cpf_hash);
+
+COMMENT ON COLUMN employees.cpf IS 'Brazilian CPF, encrypted by the application (AES-GCM)';
+COMMENT ON COLUMN employees.rg IS 'Brazilian RG, encrypted by the application (AES-GCM); optional';
+COMMENT ON COLUMN employees.cpf_hash IS 'HMAC-SHA256 blind index of the CPF - unique among active employees';
+COMMENT ON COLUMN employees.rg_hash IS 'HMAC-SHA256 blind index of the normalized RG - unique among active employees';
@@ -0,0 +1,26 @@
+-- V7__employee_audit_and_anonymization.sql
+-- Employee history and anonymization, for LGPD data subject requests.
+--
+-- The history names the fields each change touched but never their values, so
+-- it holds no personal data and is kept when an employee is anonymized. It has
+-- no foreign key: its entries outlive purged employees.
+
+CREATE TABLE IF NOT EXISTS employee_audit_events (
+    id UUID PRIMARY KEY,
+    employee_id UUID NOT NULL,
+    action VARCHAR(32) NOT NULL,
+    fields JSONB NOT NULL DEFAULT '[]',
+    department_id UUID NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_employee_audit_events_employee
+    ON employee_audit_events(employee_id, created_at);
+
+-- Anonymized employees keep their row, with the name replaced and the CPF and
+-- RG erased, so that departments and reports referring to them stay consistent
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS anonymized_at TIMESTAMP WITH TIME ZONE;
+
+COMMENT ON TABLE employee_audit_events IS 'Employee history: action, changed field names and department, without personal data';
+COMMENT ON COLUMN employee_audit_events.department_id IS 'Department of the employee after the event';
+COMMENT ON COLUMN employees.anonymized_at IS 'Set when the personal data of a deleted employee was erased (LGPD)';
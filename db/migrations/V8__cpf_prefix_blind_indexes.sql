@@ -0,0 +1,16 @@
+-- V8__cpf_prefix_blind_indexes.sql
+-- The global search finds employees by the start of their CPF, as it did on the
+-- plaintext column before V6. Encrypted CPFs can't be matched in part, so the
+-- first 3, 6 and 9 digits (the groups of a formatted CPF) get blind indexes of
+-- their own: HMAC-SHA256 values, kept as a JSON array.
+--
+-- The column starts NULL; the application fills it on startup, before serving,
+-- with the same pass as "main reencrypt".
+
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS cpf_prefix_hashes JSONB;
+
+-- Only active employees are searched
+CREATE INDEX IF NOT EXISTS idx_employees_cpf_prefix_hashes
+    ON employees USING GIN (cpf_prefix_hashes jsonb_path_ops) WHERE deleted_at IS NULL;
+
+COMMENT ON COLUMN employees.cpf_prefix_hashes IS 'HMAC-SHA256 blind indexes of the first 3, 6 and 9 digits of the CPF, for the search';
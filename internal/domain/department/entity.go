@@ -14,6 +14,7 @@ type Department struct {
 	Name               string         `gorm:"type:varchar(255);not null" json:"name"`
 	ManagerID          uuid.UUID      `gorm:"type:uuid;not null" json:"manager_id"`
 	ParentDepartmentID *uuid.UUID     `gorm:"type:uuid" json:"parent_department_id,omitempty"`
+	Version            int64          `gorm:"not null;default:1" json:"version"`
 	CreatedAt          time.Time      `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt          time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
 	DeletedAt          gorm.DeletedAt `gorm:"index" json:"-"`
@@ -23,10 +24,13 @@ func (Department) TableName() string {
 	return "departments"
 }
 
-// BeforeCreate hook to generate UUIDv7 before creating a new department
+// BeforeCreate hook to generate UUIDv7 and start the version before creating a new department
 func (d *Department) BeforeCreate(tx *gorm.DB) error {
 	if d.ID == uuid.Nil {
 		d.ID = uuidpkg.NewV7()
 	}
+	if d.Version == 0 {
+		d.Version = 1
+	}
 	return nil
 }
@@ -1,21 +1,26 @@
 package department
 
 import (
-	"errors"
+	"slices"
+	"sort"
 	"sync"
 
+	domainErrors "api-employees-and-departments/internal/domain/errors"
+
 	"github.com/google/uuid"
 )
 
 type MockEmployeeRepository struct {
 	mu           sync.RWMutex
 	employees    map[uuid.UUID]*Employee
+	deleted      map[uuid.UUID]*Employee
 	findByIDError error
 }
 
 func NewMockEmployeeRepository() *MockEmployeeRepository {
 	return &MockEmployeeRepository{
 		employees: make(map[uuid.UUID]*Employee),
+		deleted:   make(map[uuid.UUID]*Employee),
 	}
 }
 
@@ -29,11 +34,37 @@ func (m *MockEmployeeRepository) FindByID(id uuid.UUID) (Employee, error) {
 
 	emp, exists := m.employees[id]
 	if !exists {
-		return Employee{}, errors.New("employee not found")
+		return Employee{}, &domainErrors.NotFoundError{Message: "employee not found"}
 	}
 	return *emp, nil
 }
 
+func (m *MockEmployeeRepository) FindByDepartmentIDs(departmentIDs []uuid.UUID, limitPerDepartment int) ([]Employee, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]Employee, 0)
+	for _, emp := range m.employees {
+		if slices.Contains(departmentIDs, emp.DepartmentID) {
+			result = append(result, *emp)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+
+	if limitPerDepartment > 0 {
+		perDepartment := make(map[uuid.UUID]int)
+		limited := result[:0]
+		for _, emp := range result {
+			if perDepartment[emp.DepartmentID] < limitPerDepartment {
+				perDepartment[emp.DepartmentID]++
+				limited = append(limited, emp)
+			}
+		}
+		result = limited
+	}
+	return result, nil
+}
+
 func (m *MockEmployeeRepository) SetFindByIDError(err error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -49,9 +80,43 @@ func (m *MockEmployeeRepository) AddEmployee(emp *Employee) {
 	m.employees[emp.ID] = emp
 }
 
+// AddDeletedEmployee adds a soft-deleted employee
+func (m *MockEmployeeRepository) AddDeletedEmployee(emp *Employee) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if emp.ID == uuid.Nil {
+		emp.ID = uuid.New()
+	}
+	m.deleted[emp.ID] = emp
+}
+
+func (m *MockEmployeeRepository) deleteInDepartments(departmentIDs []uuid.UUID) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, emp := range m.employees {
+		if slices.Contains(departmentIDs, emp.DepartmentID) {
+			m.deleted[id] = emp
+			delete(m.employees, id)
+		}
+	}
+}
+
+func (m *MockEmployeeRepository) restore(id, departmentID uuid.UUID) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	emp, exists := m.deleted[id]
+	if !exists || emp.DepartmentID != departmentID {
+		return false
+	}
+	m.employees[id] = emp
+	delete(m.deleted, id)
+	return true
+}
+
 func (m *MockEmployeeRepository) Reset() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.employees = make(map[uuid.UUID]*Employee)
+	m.deleted = make(map[uuid.UUID]*Employee)
 	m.findByIDError = nil
 }
@@ -1,26 +1,46 @@
 package department
 
 import (
-	"errors"
+	"bytes"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
+	"time"
+
+	domainErrors "api-employees-and-departments/internal/domain/errors"
+	"api-employees-and-departments/internal/domain/pagination"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type MockRepository struct {
 	mu                 sync.RWMutex
 	departments        map[uuid.UUID]*Department
+	deleted            map[uuid.UUID]*Department
+	references         map[uuid.UUID]int64
+	employeeCounts     map[uuid.UUID]int64
 	findAllError       error
 	findByIDError      error
 	findHierarchyError error
 	createError        error
 	updateError        error
 	deleteError        error
+	TransactionCalls   int
+	// UpdatedFields holds the columns passed to the last UpdateFields call
+	UpdatedFields []string
+	// Employees, when set, has its employees deleted by DeleteEmployees and its
+	// managers restored by RestoreManager
+	Employees *MockEmployeeRepository
 }
 
 func NewMockRepository() *MockRepository {
 	return &MockRepository{
-		departments: make(map[uuid.UUID]*Department),
+		departments:    make(map[uuid.UUID]*Department),
+		deleted:        make(map[uuid.UUID]*Department),
+		references:     make(map[uuid.UUID]int64),
+		employeeCounts: make(map[uuid.UUID]int64),
 	}
 }
 
@@ -49,7 +69,7 @@ func (m *MockRepository) FindByID(id uuid.UUID) (*Department, error) {
 
 	dept, exists := m.departments[id]
 	if !exists {
-		return nil, errors.New("department not found")
+		return nil, &domainErrors.NotFoundError{Message: "department not found"}
 	}
 	return dept, nil
 }
@@ -90,14 +110,85 @@ func (m *MockRepository) FindHierarchyByID(id uuid.UUID) (*DepartmentWithHierarc
 
 	dept, exists := m.departments[id]
 	if !exists {
-		return nil, errors.New("department not found")
+		return nil, &domainErrors.NotFoundError{Message: "department not found"}
 	}
 
-	return &DepartmentWithHierarchy{
+	return m.subtree(dept, map[uuid.UUID]bool{}), nil
+}
+
+// subtree builds the hierarchy below dept, sorted by name like the CTE
+func (m *MockRepository) subtree(dept *Department, visited map[uuid.UUID]bool) *DepartmentWithHierarchy {
+	visited[dept.ID] = true
+	node := &DepartmentWithHierarchy{
 		Department:     *dept,
 		ManagerName:    "Mock Manager",
 		Subdepartments: []DepartmentWithHierarchy{},
-	}, nil
+	}
+
+	var children []*Department
+	for _, child := range m.departments {
+		if child.ParentDepartmentID != nil && *child.ParentDepartmentID == dept.ID && !visited[child.ID] {
+			children = append(children, child)
+		}
+	}
+	sort.Slice(children, func(i, j int) bool { return children[i].Name < children[j].Name })
+	for _, child := range children {
+		node.Subdepartments = append(node.Subdepartments, *m.subtree(child, visited))
+	}
+	return node
+}
+
+func (m *MockRepository) FindAncestors(id uuid.UUID) ([]DepartmentAncestor, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.findByIDError != nil {
+		return nil, m.findByIDError
+	}
+
+	if _, exists := m.departments[id]; !exists {
+		return nil, &domainErrors.NotFoundError{Message: "department not found"}
+	}
+
+	// Walk up to the root, then reverse so the root comes first
+	var path []DepartmentAncestor
+	visited := make(map[uuid.UUID]bool)
+	for current, ok := m.departments[id]; ok && !visited[current.ID]; {
+		visited[current.ID] = true
+		path = append(path, DepartmentAncestor{Department: *current, ManagerName: "Mock Manager"})
+		if current.ParentDepartmentID == nil {
+			break
+		}
+		current, ok = m.departments[*current.ParentDepartmentID]
+	}
+
+	result := make([]DepartmentAncestor, len(path))
+	for i := range path {
+		result[i] = path[len(path)-1-i]
+		result[i].Depth = i
+	}
+	return result, nil
+}
+
+func (m *MockRepository) FindSubtreeIDs(id uuid.UUID) ([]uuid.UUID, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, exists := m.departments[id]; !exists {
+		return []uuid.UUID{}, nil
+	}
+
+	result := []uuid.UUID{id}
+	seen := map[uuid.UUID]bool{id: true}
+	for i := 0; i < len(result); i++ {
+		for _, dept := range m.departments {
+			if dept.ParentDepartmentID != nil && *dept.ParentDepartmentID == result[i] && !seen[dept.ID] {
+				seen[dept.ID] = true
+				result = append(result, dept.ID)
+			}
+		}
+	}
+	return result, nil
 }
 
 func (m *MockRepository) FindWithFilters(filters ListFilters) ([]Department, int64, error) {
@@ -115,13 +206,124 @@ func (m *MockRepository) FindWithFilters(filters ListFilters) ([]Department, int
 				match = false
 			}
 		}
+		if filters.HasParent != nil && (dept.ParentDepartmentID != nil) != *filters.HasParent {
+			match = false
+		}
+		if (filters.CreatedFrom != nil && dept.CreatedAt.Before(*filters.CreatedFrom)) ||
+			(filters.CreatedTo != nil && dept.CreatedAt.After(*filters.CreatedTo)) ||
+			(filters.UpdatedFrom != nil && dept.UpdatedAt.Before(*filters.UpdatedFrom)) ||
+			(filters.UpdatedTo != nil && dept.UpdatedAt.After(*filters.UpdatedTo)) {
+			match = false
+		}
 		if match {
 			result = append(result, *dept)
 		}
 	}
+	sortDepartments(result, filters.Sort)
 	return result, int64(len(result)), nil
 }
 
+func (m *MockRepository) FindPageWithFilters(filters ListFilters, keyset pagination.Keyset) ([]Department, []pagination.Cursor, error) {
+	all, _, err := m.FindWithFilters(filters)
+	if err != nil {
+		return nil, nil, err
+	}
+	if keyset.Backward {
+		slices.Reverse(all)
+	}
+
+	start := 0
+	if keyset.Cursor != nil {
+		start = len(all)
+		for i, item := range all {
+			if item.ID == keyset.Cursor.ID {
+				start = i + 1
+				break
+			}
+		}
+	}
+	page := all[start:min(len(all), start+keyset.Limit+1)]
+
+	cursors := make([]pagination.Cursor, len(page))
+	for i, item := range page {
+		values := make([]any, len(filters.Sort))
+		for j, field := range filters.Sort {
+			values[j] = departmentSortValue(item, field.Field)
+		}
+		cursors[i] = pagination.Cursor{ID: item.ID, Values: values}
+	}
+	return page, cursors, nil
+}
+
+// sortDepartments orders departments like the repository: by the sort fields, then by
+// ID. Joined fields are not available here and compare as equal.
+func sortDepartments(departments []Department, fields []pagination.SortField) {
+	slices.SortFunc(departments, func(a, b Department) int {
+		for _, field := range fields {
+			c := compareDepartmentField(a, b, field.Field)
+			if field.Desc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return bytes.Compare(a.ID[:], b.ID[:])
+	})
+}
+
+func compareDepartmentField(a, b Department, field string) int {
+	switch field {
+	case "name":
+		return strings.Compare(a.Name, b.Name)
+	case "created_at":
+		return a.CreatedAt.Compare(b.CreatedAt)
+	case "updated_at":
+		return a.UpdatedAt.Compare(b.UpdatedAt)
+	}
+	return 0
+}
+
+func departmentSortValue(dept Department, field string) any {
+	switch field {
+	case "name":
+		return dept.Name
+	case "created_at":
+		return dept.CreatedAt
+	case "updated_at":
+		return dept.UpdatedAt
+	}
+	return ""
+}
+
+func (m *MockRepository) CountWithFilters(filters ListFilters) (int64, error) {
+	_, total, err := m.FindWithFilters(filters)
+	return total, err
+}
+
+func (m *MockRepository) StreamWithFilters(filters ListFilters, fn func(ExportRow) error) error {
+	departments, _, err := m.FindWithFilters(filters)
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(departments, func(i, j int) bool { return departments[i].Name < departments[j].Name })
+	for _, dept := range departments {
+		row := ExportRow{Department: dept}
+		if dept.ParentDepartmentID != nil {
+			m.mu.RLock()
+			if parent, ok := m.departments[*dept.ParentDepartmentID]; ok {
+				row.ParentDepartmentName = parent.Name
+			}
+			m.mu.RUnlock()
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (m *MockRepository) Create(dept *Department) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -146,14 +348,40 @@ func (m *MockRepository) Update(dept *Department) error {
 		return m.updateError
 	}
 
-	if _, exists := m.departments[dept.ID]; !exists {
-		return errors.New("department not found")
+	stored, exists := m.departments[dept.ID]
+	if !exists {
+		return &domainErrors.NotFoundError{Message: "department not found"}
+	}
+	if stored.Version != dept.Version {
+		return &domainErrors.PreconditionFailedError{Message: "department was modified by another request"}
 	}
 
+	dept.Version++
 	m.departments[dept.ID] = dept
 	return nil
 }
 
+func (m *MockRepository) UpdateFields(dept *Department, fields ...string) error {
+	m.mu.Lock()
+	m.UpdatedFields = fields
+	m.mu.Unlock()
+	return m.Update(dept)
+}
+
+func (m *MockRepository) LockVersion(id uuid.UUID, version int64) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stored, exists := m.departments[id]
+	if !exists {
+		return &domainErrors.NotFoundError{Message: "department not found"}
+	}
+	if stored.Version != version {
+		return &domainErrors.PreconditionFailedError{Message: "department was modified by another request"}
+	}
+	return nil
+}
+
 func (m *MockRepository) Delete(id uuid.UUID) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -163,13 +391,204 @@ func (m *MockRepository) Delete(id uuid.UUID) error {
 	}
 
 	if _, exists := m.departments[id]; !exists {
-		return errors.New("department not found")
+		return &domainErrors.NotFoundError{Message: "department not found"}
+	}
+
+	m.softDelete(id)
+	return nil
+}
+
+func (m *MockRepository) DeleteMany(ids []uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.deleteError != nil {
+		return m.deleteError
 	}
 
+	for _, id := range ids {
+		m.softDelete(id)
+	}
+	return nil
+}
+
+// softDelete moves a department to the deleted set; the caller holds the lock
+func (m *MockRepository) softDelete(id uuid.UUID) {
+	dept, exists := m.departments[id]
+	if !exists {
+		return
+	}
+	dept.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	m.deleted[id] = dept
 	delete(m.departments, id)
+}
+
+func (m *MockRepository) FindDeleted(page, pageSize int) ([]Department, int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]Department, 0, len(m.deleted))
+	for _, dept := range m.deleted {
+		result = append(result, *dept)
+	}
+	return result, int64(len(result)), nil
+}
+
+func (m *MockRepository) FindDeletedByID(id uuid.UUID) (*Department, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	dept, exists := m.deleted[id]
+	if !exists {
+		return nil, &domainErrors.NotFoundError{Message: "department not found"}
+	}
+	return dept, nil
+}
+
+func (m *MockRepository) Restore(id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	dept, exists := m.deleted[id]
+	if !exists {
+		return &domainErrors.NotFoundError{Message: "department not found"}
+	}
+
+	dept.DeletedAt = gorm.DeletedAt{}
+	m.departments[id] = dept
+	delete(m.deleted, id)
+	return nil
+}
+
+func (m *MockRepository) Purge(id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.deleteError != nil {
+		return m.deleteError
+	}
+
+	if _, exists := m.deleted[id]; !exists {
+		return &domainErrors.NotFoundError{Message: "department not found"}
+	}
+
+	delete(m.deleted, id)
 	return nil
 }
 
+func (m *MockRepository) CountReferences(id uuid.UUID) (int64, int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var children int64
+	for _, set := range []map[uuid.UUID]*Department{m.departments, m.deleted} {
+		for _, dept := range set {
+			if dept.ParentDepartmentID != nil && *dept.ParentDepartmentID == id {
+				children++
+			}
+		}
+	}
+	return m.employeeCounts[id] + m.references[id], children, nil
+}
+
+func (m *MockRepository) CountEmployees(departmentID uuid.UUID) (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.employeeCounts[departmentID], nil
+}
+
+func (m *MockRepository) CountEmployeesByDepartments(departmentIDs []uuid.UUID) (map[uuid.UUID]int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	counts := make(map[uuid.UUID]int64)
+	for _, id := range departmentIDs {
+		if count := m.employeeCounts[id]; count > 0 {
+			counts[id] = count
+		}
+	}
+	return counts, nil
+}
+
+func (m *MockRepository) ReassignEmployees(fromDepartmentID, toDepartmentID uuid.UUID) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	moved := m.employeeCounts[fromDepartmentID]
+	m.employeeCounts[toDepartmentID] += moved
+	delete(m.employeeCounts, fromDepartmentID)
+	return moved, nil
+}
+
+func (m *MockRepository) DeleteEmployees(departmentIDs []uuid.UUID) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var deleted int64
+	for _, id := range departmentIDs {
+		deleted += m.employeeCounts[id]
+		delete(m.employeeCounts, id)
+	}
+	if m.Employees != nil {
+		m.Employees.deleteInDepartments(departmentIDs)
+	}
+	return deleted, nil
+}
+
+func (m *MockRepository) RestoreManager(managerID, departmentID uuid.UUID) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.Employees == nil {
+		return false, nil
+	}
+	return m.Employees.restore(managerID, departmentID), nil
+}
+
+func (m *MockRepository) ReparentChildren(parentID uuid.UUID, newParentID *uuid.UUID) ([]uuid.UUID, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ids := make([]uuid.UUID, 0)
+	for _, dept := range m.departments {
+		if dept.ParentDepartmentID != nil && *dept.ParentDepartmentID == parentID {
+			dept.ParentDepartmentID = newParentID
+			dept.Version++
+			ids = append(ids, dept.ID)
+		}
+	}
+	return ids, nil
+}
+
+func (m *MockRepository) UpdateParent(id uuid.UUID, parentID *uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.updateError != nil {
+		return m.updateError
+	}
+
+	dept, exists := m.departments[id]
+	if !exists {
+		return &domainErrors.NotFoundError{Message: "department not found"}
+	}
+
+	dept.ParentDepartmentID = parentID
+	dept.Version++
+	return nil
+}
+
+func (m *MockRepository) LockHierarchy() error {
+	return nil
+}
+
+func (m *MockRepository) Transaction(fn func(repo Repository) error) error {
+	m.mu.Lock()
+	m.TransactionCalls++
+	m.mu.Unlock()
+	return fn(m)
+}
+
 func (m *MockRepository) SetFindAllError(err error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -215,14 +634,44 @@ func (m *MockRepository) AddDepartment(dept *Department) {
 	m.departments[dept.ID] = dept
 }
 
+// AddDeletedDepartment adds a soft-deleted department
+func (m *MockRepository) AddDeletedDepartment(dept *Department) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if dept.ID == uuid.Nil {
+		dept.ID = uuid.New()
+	}
+	dept.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	m.deleted[dept.ID] = dept
+}
+
+// SetDeletedEmployeeCount sets how many soft-deleted employees still reference a department
+func (m *MockRepository) SetDeletedEmployeeCount(departmentID uuid.UUID, count int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.references[departmentID] = count
+}
+
+// SetEmployeeCount sets how many active employees a department has
+func (m *MockRepository) SetEmployeeCount(departmentID uuid.UUID, count int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.employeeCounts[departmentID] = count
+}
+
 func (m *MockRepository) Reset() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.departments = make(map[uuid.UUID]*Department)
+	m.deleted = make(map[uuid.UUID]*Department)
+	m.references = make(map[uuid.UUID]int64)
+	m.employeeCounts = make(map[uuid.UUID]int64)
 	m.findAllError = nil
 	m.findByIDError = nil
 	m.findHierarchyError = nil
 	m.createError = nil
 	m.updateError = nil
 	m.deleteError = nil
+	m.TransactionCalls = 0
+	m.UpdatedFields = nil
 }
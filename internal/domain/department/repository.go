@@ -1,20 +1,60 @@
 package department
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"api-employees-and-departments/internal/domain/pagination"
+
+	"github.com/google/uuid"
+)
 
 type ListFilters struct {
 	Name               *string
-	ManagerName        *string
+	ManagerName        *string // part of the manager's name, case-insensitive
 	ParentDepartmentID *uuid.UUID
+	HasParent          *bool // false keeps only the root departments
+	MinDepth           *int  // distance from the root, which is at depth 0
+	MaxDepth           *int
+	MinHeadcount       *int64 // active employees of the department itself
+	MaxHeadcount       *int64
+	CreatedFrom        *time.Time // date bounds are inclusive
+	CreatedTo          *time.Time
+	UpdatedFrom        *time.Time
+	UpdatedTo          *time.Time
+	Sort               []pagination.SortField // fields from SortFields; ID breaks ties
 	Page               int
 	PageSize           int
 }
 
+// SortFields are the fields department listings can be ordered by
+var SortFields = []string{"name", "manager_name", "created_at", "updated_at"}
+
 // DepartmentWithHierarchy represents a department with its full hierarchical structure
 type DepartmentWithHierarchy struct {
 	Department
 	ManagerName    string
+	Headcount      int64 // active employees of the department itself
+	TotalHeadcount int64 // active employees of the department and all of its descendants
 	Subdepartments []DepartmentWithHierarchy
+
+	// Employees and MoreEmployees are only filled by GetDepartmentWithEmployees
+	Employees     []Employee
+	MoreEmployees bool // the department has more employees than listed
+}
+
+// ExportRow is a department with the names of its manager and parent, as
+// written to spreadsheet exports
+type ExportRow struct {
+	Department
+	ManagerName          string
+	ParentDepartmentName string
+}
+
+// DepartmentAncestor is one step of the path from the root down to a department
+type DepartmentAncestor struct {
+	Department
+	ManagerName string
+	Depth       int // 0 for the root
 }
 
 type Repository interface {
@@ -23,8 +63,79 @@ type Repository interface {
 	FindByManagerID(managerID uuid.UUID) ([]Department, error)
 	FindByParentID(parentID uuid.UUID) ([]Department, error)
 	FindHierarchyByID(id uuid.UUID) (*DepartmentWithHierarchy, error)
+	// FindAncestors returns the path from the root down to (and including) the department
+	FindAncestors(id uuid.UUID) ([]DepartmentAncestor, error)
+	// FindSubtreeIDs returns the department and all of its descendants
+	FindSubtreeIDs(id uuid.UUID) ([]uuid.UUID, error)
 	FindWithFilters(filters ListFilters) ([]Department, int64, error)
+	// FindPageWithFilters reads a keyset page of the departments matching the
+	// filters: up to keyset.Limit+1 of them past the cursor, in the order of
+	// filters.Sort in the direction of the traversal, each with its cursor. Page
+	// and PageSize are ignored.
+	FindPageWithFilters(filters ListFilters, keyset pagination.Keyset) ([]Department, []pagination.Cursor, error)
+	CountWithFilters(filters ListFilters) (int64, error)
+	// StreamWithFilters calls fn for every department matching the filters,
+	// ordered by name, without loading them all in memory. Pagination is ignored,
+	// and an error returned by fn stops the iteration.
+	StreamWithFilters(filters ListFilters, fn func(ExportRow) error) error
 	Create(dept *Department) error
+	// Update writes every field only if the stored version still equals the
+	// record's Version, which is then incremented. A stale version returns a
+	// PreconditionFailedError.
 	Update(dept *Department) error
+	// UpdateFields is like Update but writes only the named columns
+	UpdateFields(dept *Department, fields ...string) error
 	Delete(id uuid.UUID) error
+
+	// LockVersion locks the record for the current transaction if its version
+	// still equals version, and returns a PreconditionFailedError otherwise
+	LockVersion(id uuid.UUID, version int64) error
+
+	// DeleteMany soft-deletes several departments at once
+	DeleteMany(ids []uuid.UUID) error
+
+	// CountEmployees counts the active employees of a department
+	CountEmployees(departmentID uuid.UUID) (int64, error)
+	// CountEmployeesByDepartments counts the active employees of several
+	// departments in one query; departments without employees are left out
+	CountEmployeesByDepartments(departmentIDs []uuid.UUID) (map[uuid.UUID]int64, error)
+
+	// ReassignEmployees moves every active employee of a department to another one
+	// and records the move in the history of each of them
+	ReassignEmployees(fromDepartmentID, toDepartmentID uuid.UUID) (int64, error)
+
+	// DeleteEmployees soft-deletes every active employee of the given departments
+	// and records the deletion in the history of each of them
+	DeleteEmployees(departmentIDs []uuid.UUID) (int64, error)
+
+	// RestoreManager undeletes a manager soft-deleted while still in the given
+	// department, unless anonymized, and records it in the manager's history. It
+	// reports whether the manager was restored.
+	RestoreManager(managerID, departmentID uuid.UUID) (bool, error)
+
+	// ReparentChildren points the direct children of a department to a new parent
+	// and returns their IDs
+	ReparentChildren(parentID uuid.UUID, newParentID *uuid.UUID) ([]uuid.UUID, error)
+
+	// UpdateParent changes only the parent reference of a department, which
+	// moves its whole subtree along with it
+	UpdateParent(id uuid.UUID, parentID *uuid.UUID) error
+
+	// Soft-deleted records
+	FindDeleted(page, pageSize int) ([]Department, int64, error)
+	FindDeletedByID(id uuid.UUID) (*Department, error)
+	Restore(id uuid.UUID) error
+	Purge(id uuid.UUID) error
+
+	// CountReferences counts the employees and sub-departments pointing at a
+	// department, soft-deleted ones included
+	CountReferences(id uuid.UUID) (employees int64, children int64, err error)
+
+	// LockHierarchy serializes concurrent hierarchy changes for the duration of
+	// the current transaction
+	LockHierarchy() error
+
+	// Transaction runs fn inside a database transaction, passing a repository
+	// bound to it. Returning an error from fn rolls the transaction back.
+	Transaction(fn func(repo Repository) error) error
 }
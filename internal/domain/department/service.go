@@ -3,18 +3,23 @@ package department
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"time"
 
 	"api-employees-and-departments/internal/domain/cache"
+	domainErrors "api-employees-and-departments/internal/domain/errors"
 	"api-employees-and-departments/internal/domain/logging"
+	"api-employees-and-departments/internal/domain/pagination"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type EmployeeRepository interface {
 	FindByID(id uuid.UUID) (Employee, error)
+	// FindByDepartmentIDs returns the active employees of the departments ordered
+	// by name, at most limitPerDepartment of each one (0 for all of them)
+	FindByDepartmentIDs(departmentIDs []uuid.UUID, limitPerDepartment int) ([]Employee, error)
 }
 
 type Employee struct {
@@ -23,6 +28,58 @@ type Employee struct {
 	DepartmentID uuid.UUID
 }
 
+// DeleteStrategy decides what happens to the employees and sub-departments of a
+// department being deleted
+type DeleteStrategy string
+
+const (
+	// DeleteRestrict refuses to delete while employees or sub-departments exist
+	DeleteRestrict DeleteStrategy = "restrict"
+	// DeleteReparent moves employees and sub-departments to the parent department
+	DeleteReparent DeleteStrategy = "reparent"
+	// DeleteCascade soft-deletes the whole subtree along with its employees
+	DeleteCascade DeleteStrategy = "cascade"
+)
+
+// IsValid reports whether the strategy is one of the supported ones
+func (d DeleteStrategy) IsValid() bool {
+	switch d {
+	case DeleteRestrict, DeleteReparent, DeleteCascade:
+		return true
+	}
+	return false
+}
+
+type DeleteOptions struct {
+	Strategy DeleteStrategy // defaults to DeleteRestrict
+	// ExpectedVersion, when non-zero, makes the deletion fail with a
+	// PreconditionFailedError if the department changed since that version
+	ExpectedVersion int64
+}
+
+// DeleteSummary reports what a department deletion changed
+type DeleteSummary struct {
+	Strategy             DeleteStrategy
+	DepartmentID         uuid.UUID
+	NewParentID          *uuid.UUID // where moved employees and sub-departments went
+	DeletedDepartmentIDs []uuid.UUID
+	DeletedEmployees     int64
+	MovedDepartmentIDs   []uuid.UUID
+	MovedEmployees       int64
+}
+
+// Patch is a partial update of a department. Nil fields keep their current
+// value; the parent is only changed when ParentSet is true, and a nil
+// ParentDepartmentID then turns the department into a root. A non-zero Version
+// is the version the change was based on.
+type Patch struct {
+	Name               *string
+	ManagerID          *uuid.UUID
+	ParentDepartmentID *uuid.UUID
+	ParentSet          bool
+	Version            int64
+}
+
 type Service struct {
 	repo         Repository
 	employeeRepo EmployeeRepository
@@ -51,9 +108,34 @@ func (s *Service) ListDepartments(filters ListFilters) ([]Department, int64, err
 	return s.repo.FindWithFilters(filters)
 }
 
+// ListDepartmentsPage lists the departments matching the filters with keyset
+// pagination, counting them only when keyset.WithTotal is set
+func (s *Service) ListDepartmentsPage(filters ListFilters, keyset pagination.Keyset) ([]Department, pagination.Info, error) {
+	rows, cursors, err := s.repo.FindPageWithFilters(filters, keyset)
+	if err != nil {
+		return nil, pagination.Info{}, err
+	}
+	departments, info := pagination.Cut(rows, cursors, keyset)
+
+	if keyset.WithTotal {
+		total, err := s.repo.CountWithFilters(filters)
+		if err != nil {
+			return nil, pagination.Info{}, err
+		}
+		info.Total = &total
+	}
+	return departments, info, nil
+}
+
+// ExportDepartments passes every department matching the filters to fn, one at
+// a time, so exports do not depend on pagination
+func (s *Service) ExportDepartments(filters ListFilters, fn func(ExportRow) error) error {
+	return s.repo.StreamWithFilters(filters, fn)
+}
+
 func (s *Service) GetDepartmentByID(id uuid.UUID) (*Department, error) {
 	if id == uuid.Nil {
-		return nil, errors.New("invalid department id")
+		return nil, &domainErrors.ValidationError{Field: "id", Code: "department.invalid_id", Message: "invalid department id"}
 	}
 	return s.repo.FindByID(id)
 }
@@ -68,7 +150,7 @@ func (s *Service) GetDepartmentsByParentID(parentID uuid.UUID) ([]Department, er
 
 func (s *Service) GetDepartmentWithHierarchy(id uuid.UUID) (*DepartmentWithHierarchy, error) {
 	if id == uuid.Nil {
-		return nil, errors.New("invalid department id")
+		return nil, &domainErrors.ValidationError{Field: "id", Code: "department.invalid_id", Message: "invalid department id"}
 	}
 
 	ctx := context.Background()
@@ -104,8 +186,20 @@ func (s *Service) GetDepartmentWithHierarchy(id uuid.UUID) (*DepartmentWithHiera
 			logging.String("department_id", id.String()),
 			logging.Error(err),
 		)
-		return nil, fmt.Errorf("department not found: %w", err)
+		return nil, err
+	}
+
+	// The headcount of the whole tree comes from a second, grouped query. It is
+	// cached with the tree, so HeadcountChanged must drop it when employees move.
+	counts, err := s.repo.CountEmployeesByDepartments(subtreeIDs(result, nil))
+	if err != nil {
+		s.logger.Error("Failed to count employees of department hierarchy",
+			logging.String("department_id", id.String()),
+			logging.Error(err),
+		)
+		return nil, err
 	}
+	fillHeadcount(result, counts)
 
 	// Store in cache for future requests
 	if jsonData, err := json.Marshal(result); err == nil {
@@ -125,6 +219,145 @@ func (s *Service) GetDepartmentWithHierarchy(id uuid.UUID) (*DepartmentWithHiera
 	return result, nil
 }
 
+// GetDepartmentWithEmployees returns the hierarchy of a department listing up to
+// perDepartment employees of every node. The tree comes from the hierarchy
+// cache; the employees of all nodes are read fresh, in a single query.
+func (s *Service) GetDepartmentWithEmployees(id uuid.UUID, perDepartment int) (*DepartmentWithHierarchy, error) {
+	tree, err := s.GetDepartmentWithHierarchy(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// One employee more than shown tells whether a node has others
+	employees, err := s.employeeRepo.FindByDepartmentIDs(subtreeIDs(tree, nil), perDepartment+1)
+	if err != nil {
+		s.logger.Error("Failed to fetch employees of department hierarchy",
+			logging.String("department_id", id.String()),
+			logging.Error(err),
+		)
+		return nil, err
+	}
+
+	byDepartment := make(map[uuid.UUID][]Employee)
+	for _, emp := range employees {
+		byDepartment[emp.DepartmentID] = append(byDepartment[emp.DepartmentID], emp)
+	}
+	fillEmployees(tree, byDepartment, perDepartment)
+
+	return tree, nil
+}
+
+func fillEmployees(node *DepartmentWithHierarchy, byDepartment map[uuid.UUID][]Employee, perDepartment int) {
+	node.Employees = byDepartment[node.ID]
+	if len(node.Employees) > perDepartment {
+		node.Employees = node.Employees[:perDepartment]
+		node.MoreEmployees = true
+	}
+	for i := range node.Subdepartments {
+		fillEmployees(&node.Subdepartments[i], byDepartment, perDepartment)
+	}
+}
+
+// subtreeIDs appends the IDs of every node of the tree to ids
+func subtreeIDs(node *DepartmentWithHierarchy, ids []uuid.UUID) []uuid.UUID {
+	ids = append(ids, node.ID)
+	for i := range node.Subdepartments {
+		ids = subtreeIDs(&node.Subdepartments[i], ids)
+	}
+	return ids
+}
+
+// fillHeadcount sets the direct and total headcount of every node of the tree
+// and returns the total of node
+func fillHeadcount(node *DepartmentWithHierarchy, counts map[uuid.UUID]int64) int64 {
+	node.Headcount = counts[node.ID]
+	node.TotalHeadcount = node.Headcount
+	for i := range node.Subdepartments {
+		node.TotalHeadcount += fillHeadcount(&node.Subdepartments[i], counts)
+	}
+	return node.TotalHeadcount
+}
+
+// HeadcountChanged drops the cached hierarchy of every department whose active
+// employees changed, and of all of their ancestors, since each of them embeds
+// the headcount of the others
+func (s *Service) HeadcountChanged(departmentIDs ...uuid.UUID) {
+	seen := make(map[uuid.UUID]bool, len(departmentIDs))
+	for _, id := range departmentIDs {
+		if id == uuid.Nil || seen[id] {
+			continue
+		}
+		seen[id] = true
+		s.invalidateAncestorHierarchyCaches(&id)
+	}
+}
+
+// ManagerChanged drops the cached data showing the name of an employee who was
+// renamed, deleted, restored or anonymized: the breadcrumbs below every
+// department it manages and the hierarchies holding those departments
+func (s *Service) ManagerChanged(employeeID uuid.UUID) {
+	managed, err := s.repo.FindByManagerID(employeeID)
+	if err != nil {
+		s.logger.Warn("Failed to find managed departments for cache invalidation",
+			logging.String("employee_id", employeeID.String()),
+			logging.Error(err),
+		)
+		return
+	}
+	for _, dept := range managed {
+		s.invalidateSubtreeAncestorsCaches(dept.ID)
+		s.invalidateAncestorHierarchyCaches(&dept.ID)
+	}
+}
+
+// GetDepartmentAncestors returns the breadcrumb from the root down to the department,
+// with the manager name at each level
+func (s *Service) GetDepartmentAncestors(id uuid.UUID) ([]DepartmentAncestor, error) {
+	if id == uuid.Nil {
+		return nil, &domainErrors.ValidationError{Field: "id", Code: "department.invalid_id", Message: "invalid department id"}
+	}
+
+	ctx := context.Background()
+	cacheKey := s.cacheKeys.Build("ancestors", id.String())
+
+	// Try to get from cache first (Cache-Aside Pattern)
+	cachedData, err := s.cache.Get(ctx, cacheKey)
+	if err == nil && cachedData != "" {
+		var result []DepartmentAncestor
+		if err := json.Unmarshal([]byte(cachedData), &result); err == nil {
+			s.logger.Debug("Department ancestors retrieved from cache",
+				logging.String("department_id", id.String()),
+				logging.String("cache_key", cacheKey),
+			)
+			return result, nil
+		}
+		s.logger.Warn("Failed to unmarshal cached department ancestors",
+			logging.String("department_id", id.String()),
+			logging.Error(err),
+		)
+	}
+
+	result, err := s.repo.FindAncestors(id)
+	if err != nil {
+		s.logger.Error("Failed to fetch department ancestors",
+			logging.String("department_id", id.String()),
+			logging.Error(err),
+		)
+		return nil, err
+	}
+
+	if jsonData, err := json.Marshal(result); err == nil {
+		if err := s.cache.Set(ctx, cacheKey, string(jsonData), s.cacheTTL); err != nil {
+			s.logger.Warn("Failed to cache department ancestors",
+				logging.String("department_id", id.String()),
+				logging.Error(err),
+			)
+		}
+	}
+
+	return result, nil
+}
+
 // buildHierarchy is deprecated - replaced by PostgreSQL CTE recursive query in FindHierarchyByID
 // Kept here for reference only. This method had O(N*M) complexity with N queries.
 // The new CTE approach uses 1 single query for the entire hierarchy.
@@ -180,7 +413,7 @@ func (s *Service) CreateDepartment(dept *Department) error {
 				logging.String("parent_id", dept.ParentDepartmentID.String()),
 				logging.Error(err),
 			)
-			return errors.New("parent department not found")
+			return &domainErrors.ValidationError{Field: "parent_department_id", Code: "department.parent_not_found", Message: "parent department not found"}
 		}
 	}
 
@@ -193,10 +426,8 @@ func (s *Service) CreateDepartment(dept *Department) error {
 		return err
 	}
 
-	// Invalidate cache for parent hierarchy (if exists)
-	if dept.ParentDepartmentID != nil {
-		s.invalidateHierarchyCache(*dept.ParentDepartmentID)
-	}
+	// Invalidate cache for every ancestor hierarchy (if any)
+	s.invalidateAncestorHierarchyCaches(dept.ParentDepartmentID)
 
 	s.logger.Info("Department created successfully",
 		logging.String("department_id", dept.ID.String()),
@@ -207,9 +438,12 @@ func (s *Service) CreateDepartment(dept *Department) error {
 	return nil
 }
 
+// UpdateDepartment replaces the data of a department. A non-zero dept.Version is
+// the version the change was based on, and the update fails with a
+// PreconditionFailedError if the record changed since then.
 func (s *Service) UpdateDepartment(id uuid.UUID, dept *Department) error {
 	if id == uuid.Nil {
-		return errors.New("invalid department id")
+		return &domainErrors.ValidationError{Field: "id", Code: "department.invalid_id", Message: "invalid department id"}
 	}
 
 	existing, err := s.repo.FindByID(id)
@@ -218,7 +452,18 @@ func (s *Service) UpdateDepartment(id uuid.UUID, dept *Department) error {
 			logging.String("department_id", id.String()),
 			logging.Error(err),
 		)
-		return fmt.Errorf("department not found: %w", err)
+		return err
+	}
+
+	if dept.Version == 0 {
+		dept.Version = existing.Version
+	} else if dept.Version != existing.Version {
+		s.logger.Warn("Department update based on a stale version",
+			logging.String("department_id", id.String()),
+			logging.Int64("expected_version", dept.Version),
+			logging.Int64("current_version", existing.Version),
+		)
+		return versionMismatch()
 	}
 
 	if err := s.validateDepartment(dept); err != nil {
@@ -249,6 +494,7 @@ func (s *Service) UpdateDepartment(id uuid.UUID, dept *Department) error {
 	}
 
 	dept.ID = existing.ID
+	dept.CreatedAt = existing.CreatedAt
 	if err := s.repo.Update(dept); err != nil {
 		s.logger.Error("Failed to update department in repository",
 			logging.String("department_id", id.String()),
@@ -257,15 +503,14 @@ func (s *Service) UpdateDepartment(id uuid.UUID, dept *Department) error {
 		return err
 	}
 
-	// Invalidate cache for this department and parents
+	// Invalidate cache for this department and every ancestor of both the old
+	// and the new position (they are the same chain if the parent did not change)
 	s.invalidateHierarchyCache(id)
-	if existing.ParentDepartmentID != nil {
-		s.invalidateHierarchyCache(*existing.ParentDepartmentID)
-	}
-	if dept.ParentDepartmentID != nil && (existing.ParentDepartmentID == nil || *dept.ParentDepartmentID != *existing.ParentDepartmentID) {
-		// If parent changed, invalidate new parent too
-		s.invalidateHierarchyCache(*dept.ParentDepartmentID)
-	}
+	s.invalidateAncestorHierarchyCaches(existing.ParentDepartmentID)
+	s.invalidateAncestorHierarchyCaches(dept.ParentDepartmentID)
+
+	// Every breadcrumb below this department shows its name and manager
+	s.invalidateSubtreeAncestorsCaches(id)
 
 	s.logger.Info("Department updated successfully",
 		logging.String("department_id", id.String()),
@@ -275,48 +520,465 @@ func (s *Service) UpdateDepartment(id uuid.UUID, dept *Department) error {
 	return nil
 }
 
-func (s *Service) DeleteDepartment(id uuid.UUID) error {
+// PatchDepartment applies a partial update, validating and writing only the
+// fields present in the patch, and returns the updated department. A parent
+// change is checked for cycles under the hierarchy lock, like MoveDepartment.
+func (s *Service) PatchDepartment(id uuid.UUID, patch Patch) (*Department, error) {
 	if id == uuid.Nil {
-		return errors.New("invalid department id")
+		return nil, &domainErrors.ValidationError{Field: "id", Code: "department.invalid_id", Message: "invalid department id"}
 	}
+	if patch.ParentDepartmentID != nil && *patch.ParentDepartmentID == uuid.Nil {
+		patch.ParentDepartmentID = nil
+	}
+
+	var dept Department
+	var oldParentID *uuid.UUID
+	var fields []string
+
+	err := s.repo.Transaction(func(repo Repository) error {
+		tx := s.withRepository(repo)
+
+		if patch.ParentSet {
+			if err := repo.LockHierarchy(); err != nil {
+				return err
+			}
+		}
+
+		existing, err := repo.FindByID(id)
+		if err != nil {
+			return err
+		}
+		if patch.Version != 0 && patch.Version != existing.Version {
+			return versionMismatch()
+		}
+		dept = *existing
+		oldParentID = existing.ParentDepartmentID
+
+		if patch.Name != nil {
+			if *patch.Name == "" {
+				return &domainErrors.ValidationError{Field: "name", Code: "department.name_required", Message: "department name is required"}
+			}
+			dept.Name = *patch.Name
+			fields = append(fields, "name")
+		}
 
-	dept, err := s.repo.FindByID(id)
+		if patch.ManagerID != nil {
+			if *patch.ManagerID == uuid.Nil {
+				return &domainErrors.ValidationError{Field: "manager_id", Code: "department.manager_required", Message: "department manager is required"}
+			}
+			if err := tx.validateManagerBelongsToDepartment(*patch.ManagerID, id); err != nil {
+				return err
+			}
+			dept.ManagerID = *patch.ManagerID
+			fields = append(fields, "manager_id")
+		}
+
+		if patch.ParentSet {
+			if patch.ParentDepartmentID != nil {
+				if _, err := repo.FindByID(*patch.ParentDepartmentID); err != nil {
+					return &domainErrors.ValidationError{Field: "parent_department_id", Code: "department.parent_not_found", Message: "parent department not found"}
+				}
+			}
+			if err := tx.validateNoCycle(id, patch.ParentDepartmentID); err != nil {
+				return err
+			}
+			dept.ParentDepartmentID = patch.ParentDepartmentID
+			fields = append(fields, "parent_department_id")
+		}
+
+		if len(fields) == 0 {
+			return nil
+		}
+		return repo.UpdateFields(&dept, fields...)
+	})
 	if err != nil {
-		s.logger.Error("Department not found for deletion",
+		s.logger.Error("Failed to patch department",
 			logging.String("department_id", id.String()),
 			logging.Error(err),
 		)
-		return fmt.Errorf("department not found: %w", err)
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return &dept, nil
 	}
 
-	if err := s.repo.Delete(id); err != nil {
-		s.logger.Error("Failed to delete department in repository",
+	// Same invalidation as UpdateDepartment: the department, every ancestor of
+	// the old and new position and the breadcrumbs below it
+	s.invalidateHierarchyCache(id)
+	s.invalidateAncestorHierarchyCaches(oldParentID)
+	s.invalidateAncestorHierarchyCaches(dept.ParentDepartmentID)
+	s.invalidateSubtreeAncestorsCaches(id)
+
+	s.logger.Info("Department patched successfully",
+		logging.String("department_id", id.String()),
+		logging.Int("changed_fields", len(fields)),
+	)
+
+	return &dept, nil
+}
+
+// DeleteDepartment soft-deletes a department according to the chosen strategy, all
+// in one transaction, and reports what was deleted or moved
+func (s *Service) DeleteDepartment(id uuid.UUID, opts DeleteOptions) (*DeleteSummary, error) {
+	if id == uuid.Nil {
+		return nil, &domainErrors.ValidationError{Field: "id", Code: "department.invalid_id", Message: "invalid department id"}
+	}
+
+	strategy := opts.Strategy
+	if strategy == "" {
+		strategy = DeleteRestrict
+	}
+	if !strategy.IsValid() {
+		return nil, &domainErrors.ValidationError{
+			Field:   "strategy",
+			Code:    "department.invalid_delete_strategy",
+			Params:  domainErrors.Params{"strategy": strategy},
+			Message: fmt.Sprintf("invalid delete strategy %q (use restrict, reparent or cascade)", strategy),
+		}
+	}
+
+	var dept *Department
+	var subtreeIDs []uuid.UUID
+	summary := &DeleteSummary{Strategy: strategy, DepartmentID: id}
+
+	err := s.repo.Transaction(func(repo Repository) error {
+		if err := repo.LockHierarchy(); err != nil {
+			return err
+		}
+
+		var err error
+		dept, err = repo.FindByID(id)
+		if err != nil {
+			return err
+		}
+		if opts.ExpectedVersion != 0 {
+			if err := repo.LockVersion(id, opts.ExpectedVersion); err != nil {
+				return err
+			}
+		}
+
+		// Breadcrumbs of the subtree must be collected while the department still exists
+		subtreeIDs, err = repo.FindSubtreeIDs(id)
+		if err != nil {
+			return err
+		}
+
+		employees, err := repo.CountEmployees(id)
+		if err != nil {
+			return err
+		}
+		children := len(subtreeIDs) - 1
+
+		switch strategy {
+		case DeleteRestrict:
+			if employees > 0 || children > 0 {
+				return &domainErrors.ConflictError{
+					Field:  "department",
+					Code:   "department.delete_restricted",
+					Params: domainErrors.Params{"employees": employees, "children": children},
+					Message: fmt.Sprintf("department still has %d employees and %d sub-departments; "+
+						"use the reparent or cascade strategy", employees, children),
+				}
+			}
+			summary.DeletedDepartmentIDs = []uuid.UUID{id}
+			return repo.Delete(id)
+
+		case DeleteReparent:
+			summary.NewParentID = dept.ParentDepartmentID
+			if employees > 0 {
+				if dept.ParentDepartmentID == nil {
+					return &domainErrors.ConflictError{
+						Field:   "department",
+						Code:    "department.root_employees_not_reparentable",
+						Message: "employees of a root department cannot be reparented; move them first or use the cascade strategy",
+					}
+				}
+				if summary.MovedEmployees, err = repo.ReassignEmployees(id, *dept.ParentDepartmentID); err != nil {
+					return err
+				}
+			}
+			if summary.MovedDepartmentIDs, err = repo.ReparentChildren(id, dept.ParentDepartmentID); err != nil {
+				return err
+			}
+			summary.DeletedDepartmentIDs = []uuid.UUID{id}
+			return repo.Delete(id)
+
+		default: // DeleteCascade
+			if summary.DeletedEmployees, err = repo.DeleteEmployees(subtreeIDs); err != nil {
+				return err
+			}
+			summary.DeletedDepartmentIDs = subtreeIDs
+			return repo.DeleteMany(subtreeIDs)
+		}
+	})
+	if err != nil {
+		s.logger.Error("Failed to delete department",
 			logging.String("department_id", id.String()),
+			logging.String("strategy", string(strategy)),
 			logging.Error(err),
 		)
-		return err
+		return nil, err
 	}
 
-	// Invalidate cache for this department and parent
-	s.invalidateHierarchyCache(id)
-	if dept.ParentDepartmentID != nil {
-		s.invalidateHierarchyCache(*dept.ParentDepartmentID)
-	}
+	// Invalidate cache for the deleted departments, every ancestor and the subtree breadcrumbs
+	s.invalidateHierarchyCaches(summary.DeletedDepartmentIDs)
+	s.invalidateAncestorHierarchyCaches(dept.ParentDepartmentID)
+	s.invalidateAncestorsCaches(subtreeIDs)
 
 	s.logger.Info("Department deleted successfully",
 		logging.String("department_id", id.String()),
 		logging.String("name", dept.Name),
+		logging.String("strategy", string(strategy)),
+		logging.Int("deleted_departments", len(summary.DeletedDepartmentIDs)),
+		logging.Int64("deleted_employees", summary.DeletedEmployees),
+		logging.Int("moved_departments", len(summary.MovedDepartmentIDs)),
+		logging.Int64("moved_employees", summary.MovedEmployees),
+	)
+
+	return summary, nil
+}
+
+// MoveDepartment reparents a department, carrying its whole subtree along with it.
+// A nil newParentID turns the department into a root. The move runs in a single
+// transaction and invalidates the cached hierarchy of every ancestor of both the
+// old and the new position.
+func (s *Service) MoveDepartment(id uuid.UUID, newParentID *uuid.UUID) (*Department, error) {
+	if id == uuid.Nil {
+		return nil, &domainErrors.ValidationError{Field: "id", Code: "department.invalid_id", Message: "invalid department id"}
+	}
+	if newParentID != nil && *newParentID == uuid.Nil {
+		newParentID = nil
+	}
+
+	var moved *Department
+	var affected, subtree []uuid.UUID
+
+	err := s.repo.Transaction(func(repo Repository) error {
+		tx := s.withRepository(repo)
+
+		if err := repo.LockHierarchy(); err != nil {
+			return err
+		}
+
+		existing, err := repo.FindByID(id)
+		if err != nil {
+			return err
+		}
+
+		if newParentID != nil {
+			if _, err := repo.FindByID(*newParentID); err != nil {
+				return &domainErrors.ValidationError{Field: "parent_department_id", Code: "department.parent_not_found", Message: "parent department not found"}
+			}
+		}
+
+		if err := tx.validateNoCycle(id, newParentID); err != nil {
+			return err
+		}
+
+		// The old chain must be collected before the move, the new one after it
+		// is known to be acyclic
+		oldAncestors, err := tx.collectAncestorIDs(existing.ParentDepartmentID)
+		if err != nil {
+			return err
+		}
+		newAncestors, err := tx.collectAncestorIDs(newParentID)
+		if err != nil {
+			return err
+		}
+
+		// Every department in the moved subtree gets a new breadcrumb
+		subtree, err = repo.FindSubtreeIDs(id)
+		if err != nil {
+			return err
+		}
+
+		if err := repo.UpdateParent(id, newParentID); err != nil {
+			return err
+		}
+
+		existing.ParentDepartmentID = newParentID
+		moved = existing
+		affected = append(append([]uuid.UUID{id}, oldAncestors...), newAncestors...)
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("Failed to move department",
+			logging.String("department_id", id.String()),
+			logging.Error(err),
+		)
+		return nil, err
+	}
+
+	s.invalidateHierarchyCaches(affected)
+	s.invalidateAncestorsCaches(subtree)
+
+	newParent := ""
+	if newParentID != nil {
+		newParent = newParentID.String()
+	}
+	s.logger.Info("Department moved successfully",
+		logging.String("department_id", id.String()),
+		logging.String("new_parent_id", newParent),
+		logging.Int("invalidated_hierarchies", len(affected)),
+	)
+
+	return moved, nil
+}
+
+func (s *Service) ListDeletedDepartments(page, pageSize int) ([]Department, int64, error) {
+	return s.repo.FindDeleted(page, pageSize)
+}
+
+// RestoreDepartment undeletes a soft-deleted department. The parent must still be
+// active and the hierarchy must stay acyclic, since both may have changed while
+// the department was deleted. A manager deleted along with it comes back too, so
+// that a cascade delete can be undone; its other sub-departments and employees
+// are not restored.
+func (s *Service) RestoreDepartment(id uuid.UUID) (*Department, error) {
+	if id == uuid.Nil {
+		return nil, &domainErrors.ValidationError{Field: "id", Code: "department.invalid_id", Message: "invalid department id"}
+	}
+
+	var restored *Department
+	var managerRestored bool
+
+	err := s.repo.Transaction(func(repo Repository) error {
+		tx := s.withRepository(repo)
+
+		if err := repo.LockHierarchy(); err != nil {
+			return err
+		}
+
+		dept, err := repo.FindDeletedByID(id)
+		if err != nil {
+			return &domainErrors.NotFoundError{Code: "deleted_department.not_found", Message: "deleted department not found"}
+		}
+
+		if err := s.validateDepartment(dept); err != nil {
+			return err
+		}
+
+		managerRestored, err = repo.RestoreManager(dept.ManagerID, dept.ID)
+		if err != nil {
+			return err
+		}
+		// Otherwise the manager may have left or moved while the department was deleted
+		if !managerRestored {
+			if err := tx.validateManagerBelongsToDepartment(dept.ManagerID, dept.ID); err != nil {
+				return err
+			}
+		}
+
+		if dept.ParentDepartmentID != nil {
+			if _, err := repo.FindByID(*dept.ParentDepartmentID); err != nil {
+				return &domainErrors.ValidationError{
+					Field:   "parent_department_id",
+					Code:    "department.parent_gone",
+					Message: "parent department no longer exists; restore or move it first",
+				}
+			}
+		}
+
+		if err := tx.validateNoCycle(id, dept.ParentDepartmentID); err != nil {
+			return err
+		}
+
+		if err := repo.Restore(id); err != nil {
+			return err
+		}
+
+		dept.DeletedAt = gorm.DeletedAt{}
+		restored = dept
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("Failed to restore department",
+			logging.String("department_id", id.String()),
+			logging.Error(err),
+		)
+		return nil, err
+	}
+
+	s.invalidateHierarchyCache(id)
+	s.invalidateAncestorHierarchyCaches(restored.ParentDepartmentID)
+	s.invalidateAncestorsCaches([]uuid.UUID{id})
+	if managerRestored {
+		s.ManagerChanged(restored.ManagerID)
+	}
+
+	s.logger.Info("Department restored successfully",
+		logging.String("department_id", id.String()),
+		logging.String("name", restored.Name),
+		logging.Bool("manager_restored", managerRestored),
+	)
+
+	return restored, nil
+}
+
+// PurgeDepartment permanently removes a soft-deleted department. It refuses while
+// any employee or sub-department, soft-deleted ones included, still references it.
+func (s *Service) PurgeDepartment(id uuid.UUID) error {
+	if id == uuid.Nil {
+		return &domainErrors.ValidationError{Field: "id", Code: "department.invalid_id", Message: "invalid department id"}
+	}
+
+	err := s.repo.Transaction(func(repo Repository) error {
+		if err := repo.LockHierarchy(); err != nil {
+			return err
+		}
+
+		if _, err := repo.FindDeletedByID(id); err != nil {
+			return &domainErrors.NotFoundError{
+				Code:    "department.purge_requires_deleted",
+				Message: "deleted department not found (only soft-deleted departments can be purged)",
+			}
+		}
+
+		employees, children, err := repo.CountReferences(id)
+		if err != nil {
+			return err
+		}
+		if employees > 0 || children > 0 {
+			return &domainErrors.ConflictError{
+				Field:  "id",
+				Code:   "department.still_referenced",
+				Params: domainErrors.Params{"employees": employees, "children": children},
+				Message: fmt.Sprintf("department is still referenced by %d employees and %d sub-departments "+
+					"(including deleted ones); purge them first", employees, children),
+			}
+		}
+
+		return repo.Purge(id)
+	})
+	if err != nil {
+		s.logger.Error("Failed to purge department",
+			logging.String("department_id", id.String()),
+			logging.Error(err),
+		)
+		return err
+	}
+
+	s.logger.Info("Department purged successfully",
+		logging.String("department_id", id.String()),
 	)
 
 	return nil
 }
 
+// withRepository returns a shallow copy of the service bound to another
+// repository, typically one scoped to a transaction
+func (s *Service) withRepository(repo Repository) *Service {
+	clone := *s
+	clone.repo = repo
+	return &clone
+}
+
 func (s *Service) validateDepartment(dept *Department) error {
 	if dept.Name == "" {
-		return errors.New("department name is required")
+		return &domainErrors.ValidationError{Field: "name", Code: "department.name_required", Message: "department name is required"}
 	}
 	if dept.ManagerID == uuid.Nil {
-		return errors.New("department manager is required")
+		return &domainErrors.ValidationError{Field: "manager_id", Code: "department.manager_required", Message: "department manager is required"}
 	}
 	return nil
 }
@@ -325,12 +987,17 @@ func (s *Service) validateManagerBelongsToDepartment(managerID, departmentID uui
 	// Check if manager exists
 	manager, err := s.employeeRepo.FindByID(managerID)
 	if err != nil {
-		return errors.New("manager not found")
+		return &domainErrors.ValidationError{Field: "manager_id", Code: "department.manager_not_found", Message: "manager not found"}
 	}
 
 	// Check if manager belongs to the department
 	if manager.DepartmentID != departmentID {
-		return errors.New("manager must be linked to the same department")
+		return &domainErrors.ValidationError{
+			Field:   "manager_id",
+			Code:    "department.manager_not_in_department",
+			Message: domainErrors.ErrManagerNotInDept.Error(),
+			Err:     domainErrors.ErrManagerNotInDept,
+		}
 	}
 
 	return nil
@@ -344,7 +1011,12 @@ func (s *Service) validateNoCycle(departmentID uuid.UUID, parentDepartmentID *uu
 
 	// Check if parent is the same as current department
 	if *parentDepartmentID == departmentID {
-		return errors.New("department cannot be its own parent")
+		return &domainErrors.ValidationError{
+			Field:   "parent_department_id",
+			Code:    "department.own_parent",
+			Message: "department cannot be its own parent",
+			Err:     domainErrors.ErrCycleDetected,
+		}
 	}
 
 	// Traverse the hierarchy to detect cycles
@@ -354,12 +1026,22 @@ func (s *Service) validateNoCycle(departmentID uuid.UUID, parentDepartmentID *uu
 	for currentID != uuid.Nil {
 		// If we've already visited this department, there's a cycle
 		if visited[currentID] {
-			return errors.New("cycle detected in department hierarchy")
+			return &domainErrors.ValidationError{
+				Field:   "parent_department_id",
+				Code:    "department.cycle_detected",
+				Message: domainErrors.ErrCycleDetected.Error(),
+				Err:     domainErrors.ErrCycleDetected,
+			}
 		}
 
 		// If we reached the original department, there's a cycle
 		if currentID == departmentID {
-			return errors.New("cycle detected in department hierarchy")
+			return &domainErrors.ValidationError{
+				Field:   "parent_department_id",
+				Code:    "department.cycle_detected",
+				Message: domainErrors.ErrCycleDetected.Error(),
+				Err:     domainErrors.ErrCycleDetected,
+			}
 		}
 
 		visited[currentID] = true
@@ -380,6 +1062,85 @@ func (s *Service) validateNoCycle(departmentID uuid.UUID, parentDepartmentID *uu
 	return nil
 }
 
+// collectAncestorIDs returns startID and every department above it up to the root,
+// nearest first
+func (s *Service) collectAncestorIDs(startID *uuid.UUID) ([]uuid.UUID, error) {
+	var result []uuid.UUID
+	if startID == nil || *startID == uuid.Nil {
+		return result, nil
+	}
+
+	ancestors, err := s.repo.FindAncestors(*startID)
+	if err != nil {
+		return nil, fmt.Errorf("department not found in hierarchy: %w", err)
+	}
+
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		result = append(result, ancestors[i].ID)
+	}
+	return result, nil
+}
+
+// findSubtreeIDs returns the department and its descendants, logging (rather than
+// failing on) lookup errors since it is only used for cache invalidation
+func (s *Service) findSubtreeIDs(id uuid.UUID) []uuid.UUID {
+	ids, err := s.repo.FindSubtreeIDs(id)
+	if err != nil {
+		s.logger.Warn("Failed to collect subtree for cache invalidation",
+			logging.String("department_id", id.String()),
+			logging.Error(err),
+		)
+		return []uuid.UUID{id}
+	}
+	return ids
+}
+
+// invalidateSubtreeAncestorsCaches removes the cached breadcrumb of a department
+// and all of its descendants
+func (s *Service) invalidateSubtreeAncestorsCaches(id uuid.UUID) {
+	s.invalidateAncestorsCaches(s.findSubtreeIDs(id))
+}
+
+// invalidateAncestorsCaches removes cached breadcrumbs for a set of departments
+func (s *Service) invalidateAncestorsCaches(departmentIDs []uuid.UUID) {
+	ctx := context.Background()
+	for _, id := range departmentIDs {
+		cacheKey := s.cacheKeys.Build("ancestors", id.String())
+		if err := s.cache.Delete(ctx, cacheKey); err != nil {
+			s.logger.Warn("Failed to invalidate cache for department ancestors",
+				logging.String("department_id", id.String()),
+				logging.String("cache_key", cacheKey),
+				logging.Error(err),
+			)
+		}
+	}
+}
+
+// invalidateAncestorHierarchyCaches removes the cached hierarchy of startID and
+// all of its ancestors, since each of them embeds the changed subtree
+func (s *Service) invalidateAncestorHierarchyCaches(startID *uuid.UUID) {
+	ancestors, err := s.collectAncestorIDs(startID)
+	if err != nil {
+		s.logger.Warn("Failed to collect ancestors for cache invalidation",
+			logging.Error(err),
+		)
+	}
+	s.invalidateHierarchyCaches(ancestors)
+}
+
+// invalidateHierarchyCaches removes cached hierarchies for a set of departments,
+// skipping duplicates
+func (s *Service) invalidateHierarchyCaches(departmentIDs []uuid.UUID) {
+	seen := make(map[uuid.UUID]bool, len(departmentIDs))
+	for _, id := range departmentIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		s.invalidateHierarchyCache(id)
+	}
+}
+
 // invalidateHierarchyCache removes cached hierarchy for a department
 func (s *Service) invalidateHierarchyCache(departmentID uuid.UUID) {
 	ctx := context.Background()
@@ -398,3 +1159,11 @@ func (s *Service) invalidateHierarchyCache(departmentID uuid.UUID) {
 		)
 	}
 }
+
+// versionMismatch reports a write based on a version that is no longer current
+func versionMismatch() error {
+	return &domainErrors.PreconditionFailedError{
+		Code:    "department.version_mismatch",
+		Message: "department was modified by another request",
+	}
+}
@@ -1,11 +1,13 @@
 package department
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
 
 	"api-employees-and-departments/internal/domain/cache"
+	domainErrors "api-employees-and-departments/internal/domain/errors"
 	"api-employees-and-departments/internal/domain/logging"
 
 	"github.com/google/uuid"
@@ -137,6 +139,110 @@ func TestGetDepartmentWithHierarchy(t *testing.T) {
 	})
 }
 
+func TestHierarchyHeadcount(t *testing.T) {
+	repo := NewMockRepository()
+	mockCache := cache.NewMockCache()
+	service := NewService(repo, NewMockEmployeeRepository(), logging.NewMockLogger(), mockCache, 5*time.Minute)
+
+	// root -> a -> a1, root -> b
+	root := &Department{ID: uuid.New(), Name: "Root", ManagerID: uuid.New()}
+	a := &Department{ID: uuid.New(), Name: "A", ManagerID: uuid.New(), ParentDepartmentID: &root.ID}
+	a1 := &Department{ID: uuid.New(), Name: "A1", ManagerID: uuid.New(), ParentDepartmentID: &a.ID}
+	b := &Department{ID: uuid.New(), Name: "B", ManagerID: uuid.New(), ParentDepartmentID: &root.ID}
+	for _, d := range []*Department{root, a, a1, b} {
+		repo.AddDepartment(d)
+	}
+	repo.SetEmployeeCount(root.ID, 2)
+	repo.SetEmployeeCount(a1.ID, 7)
+	repo.SetEmployeeCount(b.ID, 1)
+
+	tree, err := service.GetDepartmentWithHierarchy(root.ID)
+	if err != nil {
+		t.Fatalf("GetDepartmentWithHierarchy() returned error: %v", err)
+	}
+	if len(tree.Subdepartments) != 2 || len(tree.Subdepartments[0].Subdepartments) != 1 {
+		t.Fatalf("GetDepartmentWithHierarchy() returned the wrong tree: %+v", tree)
+	}
+
+	counts := []struct {
+		name          string
+		node          DepartmentWithHierarchy
+		direct, total int64
+	}{
+		{"root", *tree, 2, 10},
+		{"a", tree.Subdepartments[0], 0, 7},
+		{"a1", tree.Subdepartments[0].Subdepartments[0], 7, 7},
+		{"b", tree.Subdepartments[1], 1, 1},
+	}
+	for _, c := range counts {
+		if c.node.Headcount != c.direct || c.node.TotalHeadcount != c.total {
+			t.Errorf("%s headcount = %d/%d, want %d/%d",
+				c.name, c.node.Headcount, c.node.TotalHeadcount, c.direct, c.total)
+		}
+	}
+
+	t.Run("cached until the headcount changes", func(t *testing.T) {
+		repo.SetEmployeeCount(a1.ID, 8)
+
+		cached, _ := service.GetDepartmentWithHierarchy(root.ID)
+		if cached.TotalHeadcount != 10 {
+			t.Errorf("total headcount = %d, want the cached 10", cached.TotalHeadcount)
+		}
+
+		service.HeadcountChanged(a1.ID)
+		if mockCache.HasKey(service.cacheKeys.Build("hierarchy", root.ID.String())) {
+			t.Error("HeadcountChanged() kept the cached hierarchy of the root")
+		}
+
+		fresh, _ := service.GetDepartmentWithHierarchy(root.ID)
+		if fresh.TotalHeadcount != 11 {
+			t.Errorf("total headcount = %d, want 11", fresh.TotalHeadcount)
+		}
+	})
+
+	t.Run("unknown department", func(t *testing.T) {
+		_, err := service.GetDepartmentWithHierarchy(uuid.New())
+		if !errors.Is(err, domainErrors.ErrNotFound) {
+			t.Errorf("GetDepartmentWithHierarchy() should return a not found error, got %v", err)
+		}
+	})
+}
+
+func TestGetDepartmentWithEmployees(t *testing.T) {
+	repo := NewMockRepository()
+	empRepo := NewMockEmployeeRepository()
+	service := NewService(repo, empRepo, logging.NewMockLogger(), cache.NewMockCache(), 5*time.Minute)
+
+	root := &Department{ID: uuid.New(), Name: "Root", ManagerID: uuid.New()}
+	child := &Department{ID: uuid.New(), Name: "Child", ManagerID: uuid.New(), ParentDepartmentID: &root.ID}
+	repo.AddDepartment(root)
+	repo.AddDepartment(child)
+	for _, name := range []string{"Carla", "Ana", "Bruno"} {
+		empRepo.AddEmployee(&Employee{Name: name, DepartmentID: root.ID})
+	}
+	empRepo.AddEmployee(&Employee{ID: child.ManagerID, Name: "Diego", DepartmentID: child.ID})
+
+	tree, err := service.GetDepartmentWithEmployees(root.ID, 2)
+	if err != nil {
+		t.Fatalf("GetDepartmentWithEmployees() returned error: %v", err)
+	}
+
+	if len(tree.Employees) != 2 || tree.Employees[0].Name != "Ana" || tree.Employees[1].Name != "Bruno" {
+		t.Errorf("root employees = %+v, want Ana and Bruno", tree.Employees)
+	}
+	if !tree.MoreEmployees {
+		t.Error("GetDepartmentWithEmployees() should report the employees left out of the root")
+	}
+
+	sub := tree.Subdepartments[0]
+	if len(sub.Employees) != 1 || sub.Employees[0].ID != child.ManagerID {
+		t.Errorf("child employees = %+v, want its manager", sub.Employees)
+	}
+	if sub.MoreEmployees {
+		t.Error("GetDepartmentWithEmployees() reported more employees in a department listed in full")
+	}
+}
+
 func TestCreateDepartment(t *testing.T) {
 	t.Run("valid department", func(t *testing.T) {
 		repo := NewMockRepository()
@@ -313,6 +419,25 @@ func TestUpdateDepartment(t *testing.T) {
 		}
 	})
 
+	t.Run("stale version", func(t *testing.T) {
+		repo := NewMockRepository()
+		empRepo := NewMockEmployeeRepository()
+		logger := logging.NewMockLogger()
+		mockCache := cache.NewMockCache()
+		service := NewService(repo, empRepo, logger, mockCache, 5*time.Minute)
+
+		deptID := uuid.New()
+		managerID := uuid.New()
+		repo.AddDepartment(&Department{ID: deptID, Name: "IT", ManagerID: managerID, Version: 2})
+		empRepo.AddEmployee(&Employee{ID: managerID, Name: "Manager", DepartmentID: deptID})
+
+		err := service.UpdateDepartment(deptID, &Department{Name: "IT Updated", ManagerID: managerID, Version: 1})
+
+		if !errors.Is(err, domainErrors.ErrPreconditionFailed) {
+			t.Errorf("UpdateDepartment() should return a precondition failed error, got %v", err)
+		}
+	})
+
 	t.Run("nil ID", func(t *testing.T) {
 		repo := NewMockRepository()
 		empRepo := NewMockEmployeeRepository()
@@ -389,7 +514,7 @@ func TestDeleteDepartment(t *testing.T) {
 		dept := &Department{ID: uuid.New(), Name: "IT", ManagerID: managerID}
 		repo.AddDepartment(dept)
 
-		err := service.DeleteDepartment(dept.ID)
+		_, err := service.DeleteDepartment(dept.ID, DeleteOptions{})
 
 		if err != nil {
 			t.Errorf("DeleteDepartment() returned error: %v", err)
@@ -409,7 +534,7 @@ func TestDeleteDepartment(t *testing.T) {
 		mockCache := cache.NewMockCache()
 		service := NewService(repo, empRepo, logger, mockCache, 5*time.Minute)
 
-		err := service.DeleteDepartment(uuid.Nil)
+		_, err := service.DeleteDepartment(uuid.Nil, DeleteOptions{})
 
 		if err == nil {
 			t.Error("DeleteDepartment() should return error for nil ID")
@@ -423,7 +548,7 @@ func TestDeleteDepartment(t *testing.T) {
 		mockCache := cache.NewMockCache()
 		service := NewService(repo, empRepo, logger, mockCache, 5*time.Minute)
 
-		err := service.DeleteDepartment(uuid.New())
+		_, err := service.DeleteDepartment(uuid.New(), DeleteOptions{})
 
 		if err == nil {
 			t.Error("DeleteDepartment() should return error for non-existent department")
@@ -432,6 +557,26 @@ func TestDeleteDepartment(t *testing.T) {
 			t.Error("DeleteDepartment() did not log error")
 		}
 	})
+
+	t.Run("stale version", func(t *testing.T) {
+		repo := NewMockRepository()
+		empRepo := NewMockEmployeeRepository()
+		logger := logging.NewMockLogger()
+		mockCache := cache.NewMockCache()
+		service := NewService(repo, empRepo, logger, mockCache, 5*time.Minute)
+
+		dept := &Department{ID: uuid.New(), Name: "IT", ManagerID: uuid.New(), Version: 4}
+		repo.AddDepartment(dept)
+
+		_, err := service.DeleteDepartment(dept.ID, DeleteOptions{ExpectedVersion: 3})
+
+		if !errors.Is(err, domainErrors.ErrPreconditionFailed) {
+			t.Errorf("DeleteDepartment() should return a precondition failed error, got %v", err)
+		}
+		if _, err := repo.FindByID(dept.ID); err != nil {
+			t.Error("DeleteDepartment() should keep the department on a stale version")
+		}
+	})
 }
 
 func TestValidateNoCycle(t *testing.T) {
@@ -484,6 +629,9 @@ func TestValidateNoCycle(t *testing.T) {
 		if err == nil {
 			t.Error("validateNoCycle() should detect cycle")
 		}
+		if !errors.Is(err, domainErrors.ErrCycleDetected) {
+			t.Errorf("validateNoCycle() should return ErrCycleDetected, got %v", err)
+		}
 	})
 }
 
@@ -566,3 +714,567 @@ func TestListDepartments(t *testing.T) {
 		t.Errorf("ListDepartments() returned total %d, expected 1", total)
 	}
 }
+
+func TestMoveDepartment(t *testing.T) {
+	// root -> a -> a1 and root -> b
+	setup := func() (*Service, *MockRepository, *cache.MockCache, map[string]*Department) {
+		repo := NewMockRepository()
+		empRepo := NewMockEmployeeRepository()
+		logger := logging.NewMockLogger()
+		mockCache := cache.NewMockCache()
+		service := NewService(repo, empRepo, logger, mockCache, 5*time.Minute)
+
+		root := &Department{ID: uuid.New(), Name: "Root", ManagerID: uuid.New()}
+		a := &Department{ID: uuid.New(), Name: "A", ManagerID: uuid.New(), ParentDepartmentID: &root.ID}
+		a1 := &Department{ID: uuid.New(), Name: "A1", ManagerID: uuid.New(), ParentDepartmentID: &a.ID}
+		b := &Department{ID: uuid.New(), Name: "B", ManagerID: uuid.New(), ParentDepartmentID: &root.ID}
+		for _, d := range []*Department{root, a, a1, b} {
+			repo.AddDepartment(d)
+		}
+
+		return service, repo, mockCache, map[string]*Department{"root": root, "a": a, "a1": a1, "b": b}
+	}
+
+	t.Run("valid move invalidates every ancestor", func(t *testing.T) {
+		service, repo, mockCache, depts := setup()
+		for _, d := range depts {
+			mockCache.Set(context.Background(), service.cacheKeys.Build("hierarchy", d.ID.String()), "{}", time.Minute)
+		}
+
+		moved, err := service.MoveDepartment(depts["a1"].ID, &depts["b"].ID)
+
+		if err != nil {
+			t.Fatalf("MoveDepartment() returned error: %v", err)
+		}
+		if moved.ParentDepartmentID == nil || *moved.ParentDepartmentID != depts["b"].ID {
+			t.Error("MoveDepartment() did not update the parent")
+		}
+		if repo.TransactionCalls != 1 {
+			t.Errorf("MoveDepartment() used %d transactions, expected 1", repo.TransactionCalls)
+		}
+		for name, d := range depts {
+			if mockCache.HasKey(service.cacheKeys.Build("hierarchy", d.ID.String())) {
+				t.Errorf("MoveDepartment() did not invalidate hierarchy cache of %s", name)
+			}
+		}
+	})
+
+	t.Run("move to root", func(t *testing.T) {
+		service, _, _, depts := setup()
+
+		moved, err := service.MoveDepartment(depts["a"].ID, nil)
+
+		if err != nil {
+			t.Fatalf("MoveDepartment() returned error: %v", err)
+		}
+		if moved.ParentDepartmentID != nil {
+			t.Error("MoveDepartment() should turn the department into a root")
+		}
+	})
+
+	t.Run("move under own descendant", func(t *testing.T) {
+		service, repo, _, depts := setup()
+
+		_, err := service.MoveDepartment(depts["a"].ID, &depts["a1"].ID)
+
+		if err == nil {
+			t.Error("MoveDepartment() should detect cycle")
+		}
+		dept, _ := repo.FindByID(depts["a"].ID)
+		if *dept.ParentDepartmentID != depts["root"].ID {
+			t.Error("MoveDepartment() changed the parent despite the cycle")
+		}
+	})
+
+	t.Run("parent not found", func(t *testing.T) {
+		service, _, _, depts := setup()
+		missing := uuid.New()
+
+		_, err := service.MoveDepartment(depts["a"].ID, &missing)
+
+		if err == nil {
+			t.Error("MoveDepartment() should return error for non-existent parent")
+		}
+	})
+
+	t.Run("nil ID", func(t *testing.T) {
+		service, _, _, _ := setup()
+
+		_, err := service.MoveDepartment(uuid.Nil, nil)
+
+		if err == nil {
+			t.Error("MoveDepartment() should return error for nil ID")
+		}
+	})
+}
+
+func TestPatchDepartment(t *testing.T) {
+	// root -> a -> a1
+	setup := func() (*Service, *MockRepository, *MockEmployeeRepository, map[string]*Department) {
+		repo := NewMockRepository()
+		empRepo := NewMockEmployeeRepository()
+		service := NewService(repo, empRepo, logging.NewMockLogger(), cache.NewMockCache(), 5*time.Minute)
+
+		root := &Department{ID: uuid.New(), Name: "Root", ManagerID: uuid.New(), Version: 1}
+		a := &Department{ID: uuid.New(), Name: "A", ManagerID: uuid.New(), ParentDepartmentID: &root.ID, Version: 1}
+		a1 := &Department{ID: uuid.New(), Name: "A1", ManagerID: uuid.New(), ParentDepartmentID: &a.ID, Version: 1}
+		for _, d := range []*Department{root, a, a1} {
+			repo.AddDepartment(d)
+		}
+
+		return service, repo, empRepo, map[string]*Department{"root": root, "a": a, "a1": a1}
+	}
+
+	t.Run("writes only the fields sent", func(t *testing.T) {
+		service, repo, _, depts := setup()
+		name := "A Renamed"
+
+		patched, err := service.PatchDepartment(depts["a"].ID, Patch{Name: &name})
+
+		if err != nil {
+			t.Fatalf("PatchDepartment() returned error: %v", err)
+		}
+		if patched.Name != name || patched.ParentDepartmentID == nil {
+			t.Errorf("PatchDepartment() changed fields that were not sent: %+v", patched)
+		}
+		if len(repo.UpdatedFields) != 1 || repo.UpdatedFields[0] != "name" {
+			t.Errorf("PatchDepartment() wrote %v, expected [name]", repo.UpdatedFields)
+		}
+	})
+
+	t.Run("null parent turns into a root", func(t *testing.T) {
+		service, _, _, depts := setup()
+
+		patched, err := service.PatchDepartment(depts["a"].ID, Patch{ParentSet: true})
+
+		if err != nil {
+			t.Fatalf("PatchDepartment() returned error: %v", err)
+		}
+		if patched.ParentDepartmentID != nil {
+			t.Error("PatchDepartment() should clear the parent")
+		}
+	})
+
+	t.Run("parent change is checked for cycles", func(t *testing.T) {
+		service, repo, _, depts := setup()
+
+		_, err := service.PatchDepartment(depts["a"].ID, Patch{ParentDepartmentID: &depts["a1"].ID, ParentSet: true})
+
+		if !errors.Is(err, domainErrors.ErrCycleDetected) {
+			t.Errorf("PatchDepartment() should return ErrCycleDetected, got %v", err)
+		}
+		if repo.UpdatedFields != nil {
+			t.Error("PatchDepartment() should not write a patch that creates a cycle")
+		}
+	})
+
+	t.Run("manager must belong to the department", func(t *testing.T) {
+		service, _, empRepo, depts := setup()
+		manager := &Employee{ID: uuid.New(), Name: "Manager", DepartmentID: depts["root"].ID}
+		empRepo.AddEmployee(manager)
+
+		_, err := service.PatchDepartment(depts["a"].ID, Patch{ManagerID: &manager.ID})
+
+		if !errors.Is(err, domainErrors.ErrManagerNotInDept) {
+			t.Errorf("PatchDepartment() should return ErrManagerNotInDept, got %v", err)
+		}
+	})
+
+	t.Run("stale version", func(t *testing.T) {
+		service, _, _, depts := setup()
+		name := "A Renamed"
+
+		_, err := service.PatchDepartment(depts["a"].ID, Patch{Name: &name, Version: 7})
+
+		if !errors.Is(err, domainErrors.ErrPreconditionFailed) {
+			t.Errorf("PatchDepartment() should return a precondition failed error, got %v", err)
+		}
+	})
+}
+
+func TestGetDepartmentAncestors(t *testing.T) {
+	repo := NewMockRepository()
+	empRepo := NewMockEmployeeRepository()
+	logger := logging.NewMockLogger()
+	mockCache := cache.NewMockCache()
+	service := NewService(repo, empRepo, logger, mockCache, 5*time.Minute)
+
+	root := &Department{ID: uuid.New(), Name: "Root", ManagerID: uuid.New()}
+	child := &Department{ID: uuid.New(), Name: "Child", ManagerID: uuid.New(), ParentDepartmentID: &root.ID}
+	grandchild := &Department{ID: uuid.New(), Name: "Grandchild", ManagerID: uuid.New(), ParentDepartmentID: &child.ID}
+	other := &Department{ID: uuid.New(), Name: "Other", ManagerID: uuid.New()}
+	for _, d := range []*Department{root, child, grandchild, other} {
+		repo.AddDepartment(d)
+	}
+
+	t.Run("path from root", func(t *testing.T) {
+		mockCache.Reset()
+		ancestors, err := service.GetDepartmentAncestors(grandchild.ID)
+		if err != nil {
+			t.Fatalf("GetDepartmentAncestors() returned error: %v", err)
+		}
+		if len(ancestors) != 3 {
+			t.Fatalf("GetDepartmentAncestors() returned %d departments, expected 3", len(ancestors))
+		}
+		if ancestors[0].ID != root.ID || ancestors[2].ID != grandchild.ID {
+			t.Error("GetDepartmentAncestors() should be ordered from the root down")
+		}
+		if ancestors[2].Depth != 2 {
+			t.Errorf("GetDepartmentAncestors() returned depth %d, expected 2", ancestors[2].Depth)
+		}
+		if mockCache.SetCalls == 0 {
+			t.Error("GetDepartmentAncestors() did not cache result")
+		}
+	})
+
+	t.Run("move invalidates subtree breadcrumbs", func(t *testing.T) {
+		mockCache.Reset()
+		service.GetDepartmentAncestors(grandchild.ID)
+
+		if _, err := service.MoveDepartment(child.ID, &other.ID); err != nil {
+			t.Fatalf("MoveDepartment() returned error: %v", err)
+		}
+		if mockCache.HasKey(service.cacheKeys.Build("ancestors", grandchild.ID.String())) {
+			t.Error("MoveDepartment() did not invalidate descendant breadcrumb")
+		}
+
+		ancestors, _ := service.GetDepartmentAncestors(grandchild.ID)
+		if ancestors[0].ID != other.ID {
+			t.Error("GetDepartmentAncestors() returned stale path after move")
+		}
+	})
+
+	t.Run("manager change invalidates the breadcrumbs below", func(t *testing.T) {
+		mockCache.Reset()
+		service.GetDepartmentAncestors(grandchild.ID)
+		service.GetDepartmentAncestors(other.ID)
+
+		service.ManagerChanged(child.ManagerID)
+		if mockCache.HasKey(service.cacheKeys.Build("ancestors", grandchild.ID.String())) {
+			t.Error("ManagerChanged() kept a breadcrumb showing the manager")
+		}
+		if !mockCache.HasKey(service.cacheKeys.Build("ancestors", other.ID.String())) {
+			t.Error("ManagerChanged() dropped an unrelated breadcrumb")
+		}
+	})
+
+	t.Run("nil ID", func(t *testing.T) {
+		_, err := service.GetDepartmentAncestors(uuid.Nil)
+		if err == nil {
+			t.Error("GetDepartmentAncestors() should return error for nil ID")
+		}
+	})
+
+	t.Run("non-existent ID", func(t *testing.T) {
+		_, err := service.GetDepartmentAncestors(uuid.New())
+		if err == nil {
+			t.Error("GetDepartmentAncestors() should return error for non-existent ID")
+		}
+	})
+}
+
+func TestDeleteDepartmentStrategies(t *testing.T) {
+	// parent -> dept -> child, with employees in dept and child
+	setup := func() (*Service, *MockRepository, *Department, *Department, *Department) {
+		repo := NewMockRepository()
+		empRepo := NewMockEmployeeRepository()
+		logger := logging.NewMockLogger()
+		mockCache := cache.NewMockCache()
+		service := NewService(repo, empRepo, logger, mockCache, 5*time.Minute)
+
+		parent := &Department{ID: uuid.New(), Name: "Parent", ManagerID: uuid.New()}
+		dept := &Department{ID: uuid.New(), Name: "Dept", ManagerID: uuid.New(), ParentDepartmentID: &parent.ID}
+		child := &Department{ID: uuid.New(), Name: "Child", ManagerID: uuid.New(), ParentDepartmentID: &dept.ID}
+		repo.AddDepartment(parent)
+		repo.AddDepartment(dept)
+		repo.AddDepartment(child)
+		repo.SetEmployeeCount(dept.ID, 3)
+		repo.SetEmployeeCount(child.ID, 2)
+
+		return service, repo, parent, dept, child
+	}
+
+	t.Run("restrict refuses with employees and children", func(t *testing.T) {
+		service, repo, _, dept, _ := setup()
+
+		_, err := service.DeleteDepartment(dept.ID, DeleteOptions{Strategy: DeleteRestrict})
+
+		var conflict *domainErrors.ConflictError
+		if !errors.As(err, &conflict) {
+			t.Fatalf("DeleteDepartment() should return ConflictError, got %v", err)
+		}
+		if _, err := repo.FindByID(dept.ID); err != nil {
+			t.Error("DeleteDepartment() deleted the department despite the conflict")
+		}
+	})
+
+	t.Run("reparent moves children and employees to parent", func(t *testing.T) {
+		service, repo, parent, dept, child := setup()
+
+		summary, err := service.DeleteDepartment(dept.ID, DeleteOptions{Strategy: DeleteReparent})
+
+		if err != nil {
+			t.Fatalf("DeleteDepartment() returned error: %v", err)
+		}
+		if summary.MovedEmployees != 3 {
+			t.Errorf("DeleteDepartment() moved %d employees, expected 3", summary.MovedEmployees)
+		}
+		if len(summary.MovedDepartmentIDs) != 1 || summary.MovedDepartmentIDs[0] != child.ID {
+			t.Error("DeleteDepartment() did not report the moved child")
+		}
+		moved, _ := repo.FindByID(child.ID)
+		if *moved.ParentDepartmentID != parent.ID {
+			t.Error("DeleteDepartment() did not reparent the child")
+		}
+		if count, _ := repo.CountEmployees(parent.ID); count != 3 {
+			t.Errorf("parent has %d employees, expected 3", count)
+		}
+	})
+
+	t.Run("reparent refuses employees of a root department", func(t *testing.T) {
+		service, repo, parent, _, _ := setup()
+		repo.SetEmployeeCount(parent.ID, 1)
+
+		_, err := service.DeleteDepartment(parent.ID, DeleteOptions{Strategy: DeleteReparent})
+
+		var conflict *domainErrors.ConflictError
+		if !errors.As(err, &conflict) {
+			t.Errorf("DeleteDepartment() should return ConflictError, got %v", err)
+		}
+	})
+
+	t.Run("cascade deletes the whole subtree", func(t *testing.T) {
+		service, repo, parent, dept, child := setup()
+
+		summary, err := service.DeleteDepartment(dept.ID, DeleteOptions{Strategy: DeleteCascade})
+
+		if err != nil {
+			t.Fatalf("DeleteDepartment() returned error: %v", err)
+		}
+		if len(summary.DeletedDepartmentIDs) != 2 {
+			t.Errorf("DeleteDepartment() deleted %d departments, expected 2", len(summary.DeletedDepartmentIDs))
+		}
+		if summary.DeletedEmployees != 5 {
+			t.Errorf("DeleteDepartment() deleted %d employees, expected 5", summary.DeletedEmployees)
+		}
+		if _, err := repo.FindByID(child.ID); err == nil {
+			t.Error("DeleteDepartment() did not delete the child")
+		}
+		if _, err := repo.FindByID(parent.ID); err != nil {
+			t.Error("DeleteDepartment() deleted the parent")
+		}
+	})
+
+	t.Run("invalid strategy", func(t *testing.T) {
+		service, _, _, dept, _ := setup()
+
+		_, err := service.DeleteDepartment(dept.ID, DeleteOptions{Strategy: "explode"})
+
+		var validation *domainErrors.ValidationError
+		if !errors.As(err, &validation) {
+			t.Errorf("DeleteDepartment() should return ValidationError, got %v", err)
+		}
+	})
+}
+
+func TestRestoreDepartment(t *testing.T) {
+	setup := func() (*Service, *MockRepository, *MockEmployeeRepository, *Department, *Department) {
+		repo := NewMockRepository()
+		empRepo := NewMockEmployeeRepository()
+		logger := logging.NewMockLogger()
+		mockCache := cache.NewMockCache()
+		service := NewService(repo, empRepo, logger, mockCache, 5*time.Minute)
+
+		parent := &Department{ID: uuid.New(), Name: "Parent", ManagerID: uuid.New()}
+		dept := &Department{ID: uuid.New(), Name: "Dept", ManagerID: uuid.New(), ParentDepartmentID: &parent.ID}
+		repo.AddDepartment(parent)
+		repo.AddDeletedDepartment(dept)
+		empRepo.AddEmployee(&Employee{ID: dept.ManagerID, Name: "Manager", DepartmentID: dept.ID})
+
+		return service, repo, empRepo, parent, dept
+	}
+
+	t.Run("valid restore", func(t *testing.T) {
+		service, repo, _, _, dept := setup()
+
+		restored, err := service.RestoreDepartment(dept.ID)
+		if err != nil {
+			t.Fatalf("RestoreDepartment() returned error: %v", err)
+		}
+		if restored.DeletedAt.Valid {
+			t.Error("RestoreDepartment() should clear DeletedAt")
+		}
+		if _, err := repo.FindByID(dept.ID); err != nil {
+			t.Error("RestoreDepartment() did not make the department active again")
+		}
+		if repo.TransactionCalls != 1 {
+			t.Errorf("RestoreDepartment() used %d transactions, expected 1", repo.TransactionCalls)
+		}
+	})
+
+	t.Run("parent deleted meanwhile", func(t *testing.T) {
+		service, repo, _, parent, dept := setup()
+		if err := repo.Delete(parent.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := service.RestoreDepartment(dept.ID)
+
+		var validation *domainErrors.ValidationError
+		if !errors.As(err, &validation) || validation.Field != "parent_department_id" {
+			t.Errorf("RestoreDepartment() should reject a deleted parent, got %v", err)
+		}
+	})
+
+	t.Run("parent moved under the deleted department", func(t *testing.T) {
+		service, repo, _, parent, dept := setup()
+		// Legal while dept was deleted, but would close a cycle once it is back
+		parent.ParentDepartmentID = &dept.ID
+
+		_, err := service.RestoreDepartment(dept.ID)
+
+		var validation *domainErrors.ValidationError
+		if !errors.As(err, &validation) {
+			t.Errorf("RestoreDepartment() should reject a cycle, got %v", err)
+		}
+		if _, err := repo.FindDeletedByID(dept.ID); err != nil {
+			t.Error("RestoreDepartment() restored the department despite the cycle")
+		}
+	})
+
+	t.Run("manager gone meanwhile", func(t *testing.T) {
+		service, repo, empRepo, _, dept := setup()
+		empRepo.Reset()
+
+		_, err := service.RestoreDepartment(dept.ID)
+
+		var validation *domainErrors.ValidationError
+		if !errors.As(err, &validation) || validation.Field != "manager_id" {
+			t.Errorf("RestoreDepartment() should reject a missing manager, got %v", err)
+		}
+		if _, err := repo.FindDeletedByID(dept.ID); err != nil {
+			t.Error("RestoreDepartment() restored the department without its manager")
+		}
+	})
+
+	t.Run("cascade delete round trip", func(t *testing.T) {
+		repo := NewMockRepository()
+		empRepo := NewMockEmployeeRepository()
+		repo.Employees = empRepo
+		service := NewService(repo, empRepo, logging.NewMockLogger(), cache.NewMockCache(), 5*time.Minute)
+
+		dept := &Department{ID: uuid.New(), Name: "Dept", ManagerID: uuid.New()}
+		repo.AddDepartment(dept)
+		empRepo.AddEmployee(&Employee{ID: dept.ManagerID, Name: "Manager", DepartmentID: dept.ID})
+
+		if _, err := service.DeleteDepartment(dept.ID, DeleteOptions{Strategy: DeleteCascade}); err != nil {
+			t.Fatalf("DeleteDepartment() returned error: %v", err)
+		}
+		if _, err := empRepo.FindByID(dept.ManagerID); err == nil {
+			t.Fatal("DeleteDepartment() did not delete the manager")
+		}
+
+		if _, err := service.RestoreDepartment(dept.ID); err != nil {
+			t.Fatalf("RestoreDepartment() returned error: %v", err)
+		}
+		if _, err := repo.FindByID(dept.ID); err != nil {
+			t.Error("RestoreDepartment() did not make the department active again")
+		}
+		if _, err := empRepo.FindByID(dept.ManagerID); err != nil {
+			t.Error("RestoreDepartment() did not restore the manager deleted with the department")
+		}
+	})
+
+	t.Run("manager deleted in another department", func(t *testing.T) {
+		service, repo, empRepo, _, dept := setup()
+		repo.Employees = empRepo
+		empRepo.Reset()
+		empRepo.AddDeletedEmployee(&Employee{ID: dept.ManagerID, Name: "Manager", DepartmentID: uuid.New()})
+
+		_, err := service.RestoreDepartment(dept.ID)
+
+		var validation *domainErrors.ValidationError
+		if !errors.As(err, &validation) || validation.Field != "manager_id" {
+			t.Errorf("RestoreDepartment() should reject a manager deleted elsewhere, got %v", err)
+		}
+		if _, err := empRepo.FindByID(dept.ManagerID); err == nil {
+			t.Error("RestoreDepartment() restored a manager from another department")
+		}
+	})
+
+	t.Run("department not deleted", func(t *testing.T) {
+		service, _, _, parent, _ := setup()
+
+		_, err := service.RestoreDepartment(parent.ID)
+
+		var notFound *domainErrors.NotFoundError
+		if !errors.As(err, &notFound) {
+			t.Errorf("RestoreDepartment() should return not found for an active department, got %v", err)
+		}
+	})
+}
+
+func TestPurgeDepartment(t *testing.T) {
+	setup := func() (*Service, *MockRepository) {
+		repo := NewMockRepository()
+		empRepo := NewMockEmployeeRepository()
+		logger := logging.NewMockLogger()
+		mockCache := cache.NewMockCache()
+		return NewService(repo, empRepo, logger, mockCache, 5*time.Minute), repo
+	}
+
+	t.Run("valid purge", func(t *testing.T) {
+		service, repo := setup()
+		dept := &Department{ID: uuid.New(), Name: "Dept", ManagerID: uuid.New()}
+		repo.AddDeletedDepartment(dept)
+
+		if err := service.PurgeDepartment(dept.ID); err != nil {
+			t.Fatalf("PurgeDepartment() returned error: %v", err)
+		}
+		if _, err := repo.FindDeletedByID(dept.ID); err == nil {
+			t.Error("PurgeDepartment() did not remove the department")
+		}
+	})
+
+	t.Run("still referenced by deleted employees", func(t *testing.T) {
+		service, repo := setup()
+		dept := &Department{ID: uuid.New(), Name: "Dept", ManagerID: uuid.New()}
+		repo.AddDeletedDepartment(dept)
+		repo.SetDeletedEmployeeCount(dept.ID, 2)
+
+		err := service.PurgeDepartment(dept.ID)
+
+		var conflict *domainErrors.ConflictError
+		if !errors.As(err, &conflict) {
+			t.Errorf("PurgeDepartment() should refuse while employees reference it, got %v", err)
+		}
+	})
+
+	t.Run("still has deleted sub-departments", func(t *testing.T) {
+		service, repo := setup()
+		dept := &Department{ID: uuid.New(), Name: "Dept", ManagerID: uuid.New()}
+		child := &Department{ID: uuid.New(), Name: "Child", ManagerID: uuid.New(), ParentDepartmentID: &dept.ID}
+		repo.AddDeletedDepartment(dept)
+		repo.AddDeletedDepartment(child)
+
+		err := service.PurgeDepartment(dept.ID)
+
+		var conflict *domainErrors.ConflictError
+		if !errors.As(err, &conflict) {
+			t.Errorf("PurgeDepartment() should refuse while sub-departments reference it, got %v", err)
+		}
+	})
+
+	t.Run("active department", func(t *testing.T) {
+		service, repo := setup()
+		dept := &Department{ID: uuid.New(), Name: "Dept", ManagerID: uuid.New()}
+		repo.AddDepartment(dept)
+
+		err := service.PurgeDepartment(dept.ID)
+
+		var notFound *domainErrors.NotFoundError
+		if !errors.As(err, &notFound) {
+			t.Errorf("PurgeDepartment() should refuse active departments, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,131 @@
+package employee
+
+import (
+	"slices"
+	"time"
+
+	uuidpkg "api-employees-and-departments/internal/domain/uuid"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AuditAction is what happened to an employee record
+type AuditAction string
+
+const (
+	AuditCreated      AuditAction = "created"
+	AuditRehired      AuditAction = "rehired"
+	AuditUpdated      AuditAction = "updated"
+	AuditDeleted      AuditAction = "deleted"
+	AuditRestored     AuditAction = "restored"
+	AuditAnonymized   AuditAction = "anonymized"
+	AuditDataExported AuditAction = "data_exported"
+)
+
+// AuditEvent is an entry of an employee's history. It names the fields that
+// changed but never holds their values, so the history keeps no personal data
+// and survives anonymization. DepartmentID is the department the employee was
+// in after the event.
+type AuditEvent struct {
+	ID           uuid.UUID   `gorm:"type:uuid;primary_key" json:"id"`
+	EmployeeID   uuid.UUID   `gorm:"type:uuid;not null;index:idx_employee_audit_events_employee" json:"employee_id"`
+	Action       AuditAction `gorm:"type:varchar(32);not null" json:"action"`
+	Fields       []string    `gorm:"type:jsonb;not null;serializer:json" json:"fields,omitempty"`
+	DepartmentID uuid.UUID   `gorm:"type:uuid;not null" json:"department_id"`
+	CreatedAt    time.Time   `gorm:"autoCreateTime;index:idx_employee_audit_events_employee" json:"created_at"`
+}
+
+func (AuditEvent) TableName() string {
+	return "employee_audit_events"
+}
+
+// BeforeCreate hook to generate UUIDv7 before recording an event
+func (e *AuditEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuidpkg.NewV7()
+	}
+	if e.Fields == nil {
+		e.Fields = []string{}
+	}
+	return nil
+}
+
+// newAuditEvent describes an action on emp, as it stands after the action
+func newAuditEvent(action AuditAction, emp *Employee, fields ...string) *AuditEvent {
+	return &AuditEvent{EmployeeID: emp.ID, Action: action, Fields: fields, DepartmentID: emp.DepartmentID}
+}
+
+// writeRecorded runs write and appends the event describing it to the history
+// of emp in the same transaction, so that no change is committed without its
+// entry. The event is built after write, which may assign the ID of emp.
+func (s *Service) writeRecorded(action AuditAction, emp *Employee, write func(repo Repository) error, fields ...string) error {
+	return s.repo.Transaction(func(repo Repository) error {
+		if err := write(repo); err != nil {
+			return err
+		}
+		return repo.RecordEvent(newAuditEvent(action, emp, fields...))
+	})
+}
+
+// Membership is a period an employee spent in a department. To is nil while it
+// lasts.
+type Membership struct {
+	DepartmentID   uuid.UUID
+	DepartmentName string // empty when the department no longer exists
+	From           time.Time
+	To             *time.Time
+}
+
+// memberships rebuilds the departments emp belonged to from its history, oldest
+// first. Only moves recorded since the history began are known: a record older
+// than its history is taken to have been in the department of its first event
+// since it was created, unless that event moved it.
+func memberships(emp *Employee, history []AuditEvent) []Membership {
+	var result []Membership
+	var current *Membership
+	end := func(at time.Time) {
+		if current != nil {
+			current.To = &at
+			result = append(result, *current)
+			current = nil
+		}
+	}
+
+	for i, event := range history {
+		switch event.Action {
+		case AuditDeleted:
+			end(event.CreatedAt)
+			continue
+		case AuditAnonymized, AuditDataExported:
+			continue
+		}
+
+		if current != nil && current.DepartmentID == event.DepartmentID {
+			continue
+		}
+		from := event.CreatedAt
+		if i == 0 && event.Action == AuditUpdated && !slices.Contains(event.Fields, "department_id") {
+			from = emp.CreatedAt
+		}
+		end(from)
+		current = &Membership{DepartmentID: event.DepartmentID, From: from}
+	}
+
+	if current != nil {
+		if emp.DeletedAt.Valid {
+			end(emp.DeletedAt.Time)
+		} else {
+			result = append(result, *current)
+		}
+	}
+	if len(result) > 0 {
+		return result
+	}
+
+	only := Membership{DepartmentID: emp.DepartmentID, From: emp.CreatedAt}
+	if emp.DeletedAt.Valid {
+		only.To = &emp.DeletedAt.Time
+	}
+	return []Membership{only}
+}
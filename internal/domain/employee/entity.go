@@ -12,22 +12,37 @@ import (
 type Employee struct {
 	ID           uuid.UUID      `gorm:"type:uuid;primary_key" json:"id"`
 	Name         string         `gorm:"type:varchar(255);not null" json:"name"`
-	CPF          string         `gorm:"type:varchar(11);uniqueIndex;not null" json:"cpf"`
-	RG           *string        `gorm:"type:varchar(20);uniqueIndex" json:"rg,omitempty"`
+	CPF          string         `gorm:"type:text;not null;serializer:encrypted" json:"cpf"`
+	RG           *string        `gorm:"type:text;serializer:encrypted" json:"rg,omitempty"`
 	DepartmentID uuid.UUID      `gorm:"type:uuid;not null" json:"department_id"`
+	Version      int64          `gorm:"not null;default:1" json:"version"`
 	CreatedAt    time.Time      `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt    time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
 	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+	// AnonymizedAt is set once the personal data of a deleted employee has been
+	// erased; such a record can no longer be restored
+	AnonymizedAt *time.Time `json:"anonymized_at,omitempty"`
+
+	// CPF and RG are stored encrypted; their blind indexes are filled by the
+	// repository and stand in for them in lookups and uniqueness constraints
+	CPFHash string  `gorm:"column:cpf_hash;type:varchar(64);index:uk_employees_cpf_hash_active,unique,where:deleted_at IS NULL" json:"-"`
+	RGHash  *string `gorm:"column:rg_hash;type:varchar(64);index:uk_employees_rg_hash_active,unique,where:rg_hash IS NOT NULL AND deleted_at IS NULL" json:"-"`
+	// CPFPrefixHashes are the blind indexes of the first digits of the CPF, so
+	// the global search can find it by a prefix
+	CPFPrefixHashes []string `gorm:"column:cpf_prefix_hashes;type:jsonb;serializer:json" json:"-"`
 }
 
 func (Employee) TableName() string {
 	return "employees"
 }
 
-// BeforeCreate hook to generate UUIDv7 before creating a new employee
+// BeforeCreate hook to generate UUIDv7 and start the version before creating a new employee
 func (e *Employee) BeforeCreate(tx *gorm.DB) error {
 	if e.ID == uuid.Nil {
 		e.ID = uuidpkg.NewV7()
 	}
+	if e.Version == 0 {
+		e.Version = 1
+	}
 	return nil
 }
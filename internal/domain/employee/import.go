@@ -0,0 +1,339 @@
+package employee
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	domainErrors "api-employees-and-departments/internal/domain/errors"
+	"api-employees-and-departments/internal/domain/logging"
+	"api-employees-and-departments/internal/domain/validators"
+
+	"github.com/google/uuid"
+)
+
+// ImportRow is one employee of a bulk import. Department holds either the ID or
+// the name of the department; a name must match exactly one department.
+type ImportRow struct {
+	Line       int // position in the source file, reported back with the errors
+	Name       string
+	CPF        string
+	RG         *string
+	Department string
+}
+
+// ImportOptions controls how a bulk import is applied
+type ImportOptions struct {
+	// DryRun validates every row without writing anything
+	DryRun bool
+	// Partial creates the valid rows even if others are rejected. By default the
+	// import is all-or-nothing: one invalid row rejects the whole file.
+	Partial bool
+}
+
+// ImportRowResult is the outcome of a single row. Employee is set once the row
+// passed validation, and Created tells whether it was actually written.
+type ImportRowResult struct {
+	Line     int
+	Employee *Employee
+	Created  bool
+	Errors   []error
+}
+
+// ImportReport summarizes a bulk import, row by row
+type ImportReport struct {
+	Rows    []ImportRowResult
+	Valid   int
+	Invalid int
+	Created int
+}
+
+// ImportEmployees validates every row with the same rules as a single creation,
+// plus CPF and RG uniqueness against both the stored employees and the other
+// rows, and then creates the valid ones according to opts. Rows always become
+// new records; deleted employees with the same CPF are left untouched.
+//
+// Row-level problems are reported in the ImportReport; the returned error is
+// only set when the import could not run at all.
+func (s *Service) ImportEmployees(rows []ImportRow, opts ImportOptions) (*ImportReport, error) {
+	report := &ImportReport{Rows: make([]ImportRowResult, len(rows))}
+
+	cpfLines := make(map[string]int)
+	rgLines := make(map[string]int)
+	departments := make(map[string]departmentLookup)
+
+	for i, row := range rows {
+		result := ImportRowResult{Line: row.Line}
+		emp := &Employee{Name: strings.TrimSpace(row.Name), CPF: strings.TrimSpace(row.CPF), RG: row.RG}
+		if emp.RG != nil {
+			rg := strings.TrimSpace(*emp.RG)
+			emp.RG = &rg
+			if rg == "" {
+				emp.RG = nil
+			}
+		}
+
+		ref := strings.TrimSpace(row.Department)
+		lookup, cached := departments[strings.ToLower(ref)]
+		if !cached {
+			lookup.id, lookup.err = s.resolveDepartment(ref)
+			if lookup.err != nil && !isRowError(lookup.err) {
+				return nil, lookup.err
+			}
+			departments[strings.ToLower(ref)] = lookup
+		}
+		emp.DepartmentID = lookup.id
+
+		result.Errors = validatePersonalData(emp)
+		if lookup.err != nil {
+			result.Errors = append(result.Errors, lookup.err)
+		}
+
+		uniqueness, err := s.checkImportUniqueness(emp, row.Line, cpfLines, rgLines)
+		if err != nil {
+			return nil, err
+		}
+		result.Errors = append(result.Errors, uniqueness...)
+
+		if len(result.Errors) == 0 {
+			result.Employee = emp
+			report.Valid++
+		} else {
+			report.Invalid++
+		}
+		report.Rows[i] = result
+	}
+
+	if opts.DryRun || report.Valid == 0 || (report.Invalid > 0 && !opts.Partial) {
+		s.logger.Info("Employee import not written",
+			logging.Int("rows", len(rows)),
+			logging.Int("invalid", report.Invalid),
+			logging.Bool("dry_run", opts.DryRun),
+		)
+		return report, nil
+	}
+
+	var err error
+	if opts.Partial {
+		err = s.createImportedEach(report)
+	} else {
+		err = s.createImportedAtomically(report)
+	}
+	s.headcount.HeadcountChanged(createdDepartments(report)...)
+	if err != nil {
+		s.logger.Error("Failed to import employees",
+			logging.Int("rows", len(rows)),
+			logging.Error(err),
+		)
+		return nil, err
+	}
+
+	s.logger.Info("Employees imported",
+		logging.Int("rows", len(rows)),
+		logging.Int("created", report.Created),
+		logging.Int("invalid", report.Invalid),
+	)
+
+	return report, nil
+}
+
+// departmentLookup caches the resolution of a department reference, since an
+// import usually repeats the same few departments on every row
+type departmentLookup struct {
+	id  uuid.UUID
+	err error
+}
+
+// resolveDepartment turns a department ID or name into an ID
+func (s *Service) resolveDepartment(ref string) (uuid.UUID, error) {
+	if ref == "" {
+		return uuid.Nil, &domainErrors.ValidationError{Field: "department", Code: "employee.department_required", Message: "employee department is required"}
+	}
+
+	if id, err := uuid.Parse(ref); err == nil {
+		if _, err := s.deptRepo.FindByID(id); err != nil {
+			if errors.Is(err, domainErrors.ErrNotFound) {
+				return uuid.Nil, departmentNotFound(ref)
+			}
+			return uuid.Nil, err
+		}
+		return id, nil
+	}
+
+	matches, err := s.deptRepo.FindByName(ref)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	switch len(matches) {
+	case 0:
+		return uuid.Nil, departmentNotFound(ref)
+	case 1:
+		return matches[0].ID, nil
+	}
+	return uuid.Nil, &domainErrors.ValidationError{
+		Field:   "department",
+		Code:    "import.department_ambiguous",
+		Params:  domainErrors.Params{"department": ref, "count": len(matches)},
+		Message: fmt.Sprintf("%d departments are named %s; use the department ID", len(matches), ref),
+	}
+}
+
+func departmentNotFound(ref string) error {
+	return &domainErrors.ValidationError{
+		Field:   "department",
+		Code:    "import.department_not_found",
+		Params:  domainErrors.Params{"department": ref},
+		Message: "department " + ref + " not found",
+	}
+}
+
+// checkImportUniqueness reports a CPF or RG already used by a stored employee or
+// by an earlier row, recording the row's values for the next ones. Rows are
+// compared by the normalized documents, as the uniqueness indexes do, so the
+// same RG typed with and without punctuation is caught here rather than when
+// the file is written.
+func (s *Service) checkImportUniqueness(emp *Employee, line int, cpfLines, rgLines map[string]int) ([]error, error) {
+	var errs []error
+
+	if emp.CPF != "" {
+		cpf := validators.NormalizeCPF(emp.CPF)
+		if first, seen := cpfLines[cpf]; seen {
+			errs = append(errs, duplicateInFile("cpf", first))
+		} else {
+			cpfLines[cpf] = line
+			exists, err := s.repo.ExistsByCPF(emp.CPF, uuid.Nil)
+			if err != nil {
+				return nil, err
+			}
+			if exists {
+				errs = append(errs, &domainErrors.ConflictError{
+					Field:   "cpf",
+					Code:    "cpf.duplicate",
+					Message: domainErrors.ErrDuplicateCPF.Error(),
+					Err:     domainErrors.ErrDuplicateCPF,
+				})
+			}
+		}
+	}
+
+	if emp.RG != nil {
+		rg := validators.NormalizeRG(*emp.RG)
+		if first, seen := rgLines[rg]; seen {
+			errs = append(errs, duplicateInFile("rg", first))
+		} else {
+			rgLines[rg] = line
+			exists, err := s.repo.ExistsByRG(*emp.RG, uuid.Nil)
+			if err != nil {
+				return nil, err
+			}
+			if exists {
+				errs = append(errs, &domainErrors.ConflictError{
+					Field:   "rg",
+					Code:    "rg.duplicate",
+					Message: domainErrors.ErrDuplicateRG.Error(),
+					Err:     domainErrors.ErrDuplicateRG,
+				})
+			}
+		}
+	}
+
+	return errs, nil
+}
+
+func duplicateInFile(field string, firstLine int) error {
+	return &domainErrors.ConflictError{
+		Field:   field,
+		Code:    "import." + field + "_repeated",
+		Params:  domainErrors.Params{"line": firstLine},
+		Message: fmt.Sprintf("same %s as line %d", strings.ToUpper(field), firstLine),
+	}
+}
+
+// createImportedAtomically writes every valid row in one transaction. A row
+// failing at write time (e.g. a CPF taken concurrently) rolls all of them back
+// and is reported on its row.
+func (s *Service) createImportedAtomically(report *ImportReport) error {
+	var failed *ImportRowResult
+	err := s.repo.Transaction(func(repo Repository) error {
+		for i := range report.Rows {
+			row := &report.Rows[i]
+			if row.Employee == nil {
+				continue
+			}
+			if err := repo.Create(row.Employee); err != nil {
+				failed = row
+				return err
+			}
+			if err := repo.RecordEvent(newAuditEvent(AuditCreated, row.Employee)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	if err == nil {
+		for i := range report.Rows {
+			if report.Rows[i].Employee != nil {
+				report.Rows[i].Created = true
+				report.Created++
+			}
+		}
+		return nil
+	}
+
+	if failed == nil || !isRowError(err) {
+		return err
+	}
+	failed.Employee = nil
+	failed.Errors = append(failed.Errors, err)
+	report.Valid--
+	report.Invalid++
+	return nil
+}
+
+// createImportedEach writes the valid rows one by one, so a failure only affects
+// its own row
+func (s *Service) createImportedEach(report *ImportReport) error {
+	for i := range report.Rows {
+		row := &report.Rows[i]
+		if row.Employee == nil {
+			continue
+		}
+		err := s.writeRecorded(AuditCreated, row.Employee, func(repo Repository) error {
+			return repo.Create(row.Employee)
+		})
+		if err != nil {
+			if !isRowError(err) {
+				return err
+			}
+			row.Employee = nil
+			row.Errors = append(row.Errors, err)
+			report.Valid--
+			report.Invalid++
+			continue
+		}
+		row.Created = true
+		report.Created++
+	}
+	return nil
+}
+
+// createdDepartments lists the departments of the rows written by an import
+func createdDepartments(report *ImportReport) []uuid.UUID {
+	var ids []uuid.UUID
+	for _, row := range report.Rows {
+		if row.Created {
+			ids = append(ids, row.Employee.DepartmentID)
+		}
+	}
+	return ids
+}
+
+// isRowError tells the problems caused by the data of a row apart from failures
+// of the import itself
+func isRowError(err error) bool {
+	var validation *domainErrors.ValidationError
+	var conflict *domainErrors.ConflictError
+	var notFound *domainErrors.NotFoundError
+	return errors.As(err, &validation) || errors.As(err, &conflict) || errors.As(err, &notFound)
+}
@@ -0,0 +1,113 @@
+package employee
+
+import (
+	"strings"
+	"sync"
+
+	domainErrors "api-employees-and-departments/internal/domain/errors"
+
+	"github.com/google/uuid"
+)
+
+type MockDepartmentRepository struct {
+	mu            sync.RWMutex
+	departments   map[uuid.UUID]*Department
+	deleted       map[uuid.UUID]*Department
+	findByIDError error
+}
+
+func NewMockDepartmentRepository() *MockDepartmentRepository {
+	return &MockDepartmentRepository{
+		departments: make(map[uuid.UUID]*Department),
+		deleted:     make(map[uuid.UUID]*Department),
+	}
+}
+
+func (m *MockDepartmentRepository) FindByID(id uuid.UUID) (Department, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.findByIDError != nil {
+		return Department{}, m.findByIDError
+	}
+
+	dept, exists := m.departments[id]
+	if !exists {
+		return Department{}, &domainErrors.NotFoundError{Message: "department not found"}
+	}
+	return *dept, nil
+}
+
+func (m *MockDepartmentRepository) FindByManagerID(managerID uuid.UUID) ([]Department, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]Department, 0)
+	for _, dept := range m.departments {
+		if dept.ManagerID == managerID {
+			result = append(result, *dept)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockDepartmentRepository) CountAllByManagerID(managerID uuid.UUID) (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var count int64
+	for _, departments := range []map[uuid.UUID]*Department{m.departments, m.deleted} {
+		for _, dept := range departments {
+			if dept.ManagerID == managerID {
+				count++
+			}
+		}
+	}
+	return count, nil
+}
+
+func (m *MockDepartmentRepository) FindByName(name string) ([]Department, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]Department, 0)
+	for _, dept := range m.departments {
+		if strings.EqualFold(dept.Name, name) {
+			result = append(result, *dept)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockDepartmentRepository) SetFindByIDError(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.findByIDError = err
+}
+
+func (m *MockDepartmentRepository) AddDepartment(dept *Department) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if dept.ID == uuid.Nil {
+		dept.ID = uuid.New()
+	}
+	m.departments[dept.ID] = dept
+}
+
+// AddDeletedDepartment adds a soft-deleted department
+func (m *MockDepartmentRepository) AddDeletedDepartment(dept *Department) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if dept.ID == uuid.Nil {
+		dept.ID = uuid.New()
+	}
+	m.deleted[dept.ID] = dept
+}
+
+func (m *MockDepartmentRepository) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.departments = make(map[uuid.UUID]*Department)
+	m.deleted = make(map[uuid.UUID]*Department)
+	m.findByIDError = nil
+}
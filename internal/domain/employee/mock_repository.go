@@ -1,25 +1,45 @@
 package employee
 
 import (
-	"errors"
+	"bytes"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
+	"time"
+
+	domainErrors "api-employees-and-departments/internal/domain/errors"
+	"api-employees-and-departments/internal/domain/pagination"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type MockRepository struct {
-	mu             sync.RWMutex
-	employees      map[uuid.UUID]*Employee
-	findAllError   error
-	findByIDError  error
-	createError    error
-	updateError    error
-	deleteError    error
+	mu               sync.RWMutex
+	employees        map[uuid.UUID]*Employee
+	deleted          map[uuid.UUID]*Employee
+	managers         map[uuid.UUID][]ReportingChainEntry
+	subordinates     map[uuid.UUID][]Subordinate
+	events           map[uuid.UUID][]AuditEvent
+	findAllError     error
+	findByIDError    error
+	createError      error
+	updateError      error
+	deleteError      error
+	recordError      error
+	TransactionCalls int
+	// UpdatedFields holds the columns passed to the last UpdateFields call
+	UpdatedFields []string
 }
 
 func NewMockRepository() *MockRepository {
 	return &MockRepository{
-		employees: make(map[uuid.UUID]*Employee),
+		employees:    make(map[uuid.UUID]*Employee),
+		deleted:      make(map[uuid.UUID]*Employee),
+		managers:     make(map[uuid.UUID][]ReportingChainEntry),
+		subordinates: make(map[uuid.UUID][]Subordinate),
+		events:       make(map[uuid.UUID][]AuditEvent),
 	}
 }
 
@@ -48,7 +68,7 @@ func (m *MockRepository) FindByID(id uuid.UUID) (*Employee, error) {
 
 	emp, exists := m.employees[id]
 	if !exists {
-		return nil, errors.New("employee not found")
+		return nil, &domainErrors.NotFoundError{Message: "employee not found"}
 	}
 	return emp, nil
 }
@@ -59,7 +79,7 @@ func (m *MockRepository) FindByIDWithManager(id uuid.UUID) (*EmployeeWithManager
 
 	emp, exists := m.employees[id]
 	if !exists {
-		return nil, errors.New("employee not found")
+		return nil, &domainErrors.NotFoundError{Message: "employee not found"}
 	}
 
 	return &EmployeeWithManager{
@@ -68,7 +88,48 @@ func (m *MockRepository) FindByIDWithManager(id uuid.UUID) (*EmployeeWithManager
 	}, nil
 }
 
-func (m *MockRepository) FindByDepartmentIDs(departmentIDs []uuid.UUID) ([]Employee, error) {
+func (m *MockRepository) FindDepartmentManagers(id uuid.UUID) ([]ReportingChainEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.findByIDError != nil {
+		return nil, m.findByIDError
+	}
+
+	return m.managers[id], nil
+}
+
+func (m *MockRepository) FindSubordinates(managerID uuid.UUID, filters SubordinateFilters) ([]Subordinate, int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]Subordinate, 0)
+	for _, sub := range m.subordinates[managerID] {
+		if filters.DirectOnly && !sub.Direct {
+			continue
+		}
+		if filters.Name != nil && sub.Name != *filters.Name {
+			continue
+		}
+		if filters.DepartmentID != nil && sub.DepartmentID != *filters.DepartmentID {
+			continue
+		}
+		result = append(result, sub)
+	}
+	total := int64(len(result))
+
+	offset := (filters.Page - 1) * filters.PageSize
+	if offset >= len(result) {
+		return []Subordinate{}, total, nil
+	}
+	end := offset + filters.PageSize
+	if end > len(result) {
+		end = len(result)
+	}
+	return result[offset:end], total, nil
+}
+
+func (m *MockRepository) FindByDepartmentIDs(departmentIDs []uuid.UUID, limitPerDepartment int) ([]Employee, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -81,6 +142,19 @@ func (m *MockRepository) FindByDepartmentIDs(departmentIDs []uuid.UUID) ([]Emplo
 			}
 		}
 	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+
+	if limitPerDepartment > 0 {
+		perDepartment := make(map[uuid.UUID]int)
+		limited := result[:0]
+		for _, emp := range result {
+			if perDepartment[emp.DepartmentID] < limitPerDepartment {
+				perDepartment[emp.DepartmentID]++
+				limited = append(limited, emp)
+			}
+		}
+		result = limited
+	}
 	return result, nil
 }
 
@@ -104,9 +178,107 @@ func (m *MockRepository) FindWithFilters(filters ListFilters) ([]Employee, int64
 			result = append(result, *emp)
 		}
 	}
+	sortEmployees(result, filters.Sort)
 	return result, int64(len(result)), nil
 }
 
+func (m *MockRepository) FindPageWithFilters(filters ListFilters, keyset pagination.Keyset) ([]Employee, []pagination.Cursor, error) {
+	all, _, err := m.FindWithFilters(filters)
+	if err != nil {
+		return nil, nil, err
+	}
+	if keyset.Backward {
+		slices.Reverse(all)
+	}
+
+	start := 0
+	if keyset.Cursor != nil {
+		start = len(all)
+		for i, item := range all {
+			if item.ID == keyset.Cursor.ID {
+				start = i + 1
+				break
+			}
+		}
+	}
+	page := all[start:min(len(all), start+keyset.Limit+1)]
+
+	cursors := make([]pagination.Cursor, len(page))
+	for i, item := range page {
+		values := make([]any, len(filters.Sort))
+		for j, field := range filters.Sort {
+			values[j] = employeeSortValue(item, field.Field)
+		}
+		cursors[i] = pagination.Cursor{ID: item.ID, Values: values}
+	}
+	return page, cursors, nil
+}
+
+// sortEmployees orders employees like the repository: by the sort fields, then by
+// ID. Joined fields are not available here and compare as equal.
+func sortEmployees(employees []Employee, fields []pagination.SortField) {
+	slices.SortFunc(employees, func(a, b Employee) int {
+		for _, field := range fields {
+			c := compareEmployeeField(a, b, field.Field)
+			if field.Desc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return bytes.Compare(a.ID[:], b.ID[:])
+	})
+}
+
+func compareEmployeeField(a, b Employee, field string) int {
+	switch field {
+	case "name":
+		return strings.Compare(a.Name, b.Name)
+	case "cpf":
+		return strings.Compare(a.CPF, b.CPF)
+	case "created_at":
+		return a.CreatedAt.Compare(b.CreatedAt)
+	case "updated_at":
+		return a.UpdatedAt.Compare(b.UpdatedAt)
+	}
+	return 0
+}
+
+func employeeSortValue(emp Employee, field string) any {
+	switch field {
+	case "name":
+		return emp.Name
+	case "cpf":
+		return emp.CPF
+	case "created_at":
+		return emp.CreatedAt
+	case "updated_at":
+		return emp.UpdatedAt
+	}
+	return ""
+}
+
+func (m *MockRepository) CountWithFilters(filters ListFilters) (int64, error) {
+	_, total, err := m.FindWithFilters(filters)
+	return total, err
+}
+
+func (m *MockRepository) StreamWithFilters(filters ListFilters, fn func(ExportRow) error) error {
+	employees, _, err := m.FindWithFilters(filters)
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(employees, func(i, j int) bool { return employees[i].Name < employees[j].Name })
+	for _, emp := range employees {
+		if err := fn(ExportRow{Employee: emp}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (m *MockRepository) Create(emp *Employee) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -131,14 +303,40 @@ func (m *MockRepository) Update(emp *Employee) error {
 		return m.updateError
 	}
 
-	if _, exists := m.employees[emp.ID]; !exists {
-		return errors.New("employee not found")
+	stored, exists := m.employees[emp.ID]
+	if !exists {
+		return &domainErrors.NotFoundError{Message: "employee not found"}
+	}
+	if stored.Version != emp.Version {
+		return &domainErrors.PreconditionFailedError{Message: "employee was modified by another request"}
 	}
 
+	emp.Version++
 	m.employees[emp.ID] = emp
 	return nil
 }
 
+func (m *MockRepository) UpdateFields(emp *Employee, fields ...string) error {
+	m.mu.Lock()
+	m.UpdatedFields = fields
+	m.mu.Unlock()
+	return m.Update(emp)
+}
+
+func (m *MockRepository) LockVersion(id uuid.UUID, version int64) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stored, exists := m.employees[id]
+	if !exists {
+		return &domainErrors.NotFoundError{Message: "employee not found"}
+	}
+	if stored.Version != version {
+		return &domainErrors.PreconditionFailedError{Message: "employee was modified by another request"}
+	}
+	return nil
+}
+
 func (m *MockRepository) Delete(id uuid.UUID) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -147,14 +345,162 @@ func (m *MockRepository) Delete(id uuid.UUID) error {
 		return m.deleteError
 	}
 
-	if _, exists := m.employees[id]; !exists {
-		return errors.New("employee not found")
+	emp, exists := m.employees[id]
+	if !exists {
+		return &domainErrors.NotFoundError{Message: "employee not found"}
 	}
 
+	emp.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	m.deleted[id] = emp
 	delete(m.employees, id)
 	return nil
 }
 
+func (m *MockRepository) ExistsByCPF(cpf string, excludeID uuid.UUID) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, emp := range m.employees {
+		if emp.CPF == cpf && emp.ID != excludeID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *MockRepository) ExistsByRG(rg string, excludeID uuid.UUID) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, emp := range m.employees {
+		if emp.RG != nil && *emp.RG == rg && emp.ID != excludeID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *MockRepository) FindDeleted(page, pageSize int) ([]Employee, int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]Employee, 0, len(m.deleted))
+	for _, emp := range m.deleted {
+		result = append(result, *emp)
+	}
+	return result, int64(len(result)), nil
+}
+
+func (m *MockRepository) FindDeletedByID(id uuid.UUID) (*Employee, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	emp, exists := m.deleted[id]
+	if !exists {
+		return nil, &domainErrors.NotFoundError{Message: "employee not found"}
+	}
+	return emp, nil
+}
+
+func (m *MockRepository) FindDeletedByCPF(cpf string) (*Employee, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var latest *Employee
+	for _, emp := range m.deleted {
+		if emp.CPF == cpf && (latest == nil || emp.DeletedAt.Time.After(latest.DeletedAt.Time)) {
+			latest = emp
+		}
+	}
+	if latest == nil {
+		return nil, &domainErrors.NotFoundError{Message: "employee not found"}
+	}
+	return latest, nil
+}
+
+func (m *MockRepository) Restore(id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	emp, exists := m.deleted[id]
+	if !exists {
+		return &domainErrors.NotFoundError{Message: "employee not found"}
+	}
+
+	emp.DeletedAt = gorm.DeletedAt{}
+	m.employees[id] = emp
+	delete(m.deleted, id)
+	return nil
+}
+
+func (m *MockRepository) Purge(id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.deleteError != nil {
+		return m.deleteError
+	}
+
+	if _, exists := m.deleted[id]; !exists {
+		return &domainErrors.NotFoundError{Message: "employee not found"}
+	}
+
+	delete(m.deleted, id)
+	return nil
+}
+
+func (m *MockRepository) Anonymize(emp *Employee) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.updateError != nil {
+		return m.updateError
+	}
+
+	stored, exists := m.deleted[emp.ID]
+	if !exists {
+		return &domainErrors.NotFoundError{Message: "employee not found"}
+	}
+	if stored.Version != emp.Version {
+		return &domainErrors.PreconditionFailedError{Message: "employee was modified by another request"}
+	}
+
+	emp.Version++
+	m.deleted[emp.ID] = emp
+	return nil
+}
+
+func (m *MockRepository) RecordEvent(event *AuditEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.recordError != nil {
+		return m.recordError
+	}
+	if event.ID == uuid.Nil {
+		event.ID = uuid.New()
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	m.events[event.EmployeeID] = append(m.events[event.EmployeeID], *event)
+	return nil
+}
+
+func (m *MockRepository) FindEvents(employeeID uuid.UUID) ([]AuditEvent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return append([]AuditEvent(nil), m.events[employeeID]...), nil
+}
+
+func (m *MockRepository) Transaction(fn func(repo Repository) error) error {
+	m.mu.Lock()
+	m.TransactionCalls++
+	m.mu.Unlock()
+	return fn(m)
+}
+
 func (m *MockRepository) SetFindAllError(err error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -185,6 +531,12 @@ func (m *MockRepository) SetDeleteError(err error) {
 	m.deleteError = err
 }
 
+func (m *MockRepository) SetRecordEventError(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recordError = err
+}
+
 func (m *MockRepository) AddEmployee(emp *Employee) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -194,13 +546,51 @@ func (m *MockRepository) AddEmployee(emp *Employee) {
 	m.employees[emp.ID] = emp
 }
 
+// AddDeletedEmployee adds a soft-deleted employee
+func (m *MockRepository) AddDeletedEmployee(emp *Employee) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if emp.ID == uuid.Nil {
+		emp.ID = uuid.New()
+	}
+	emp.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	m.deleted[emp.ID] = emp
+}
+
+// SetDepartmentManagers sets the raw department manager rows returned for an employee
+func (m *MockRepository) SetDepartmentManagers(id uuid.UUID, managers []ReportingChainEntry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.managers[id] = managers
+}
+
+// SetSubordinates sets the subordinates returned for a manager
+func (m *MockRepository) SetSubordinates(managerID uuid.UUID, subordinates []Subordinate) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subordinates[managerID] = subordinates
+}
+
+// AddEvents sets events as the start of an employee's history
+func (m *MockRepository) AddEvents(employeeID uuid.UUID, events ...AuditEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events[employeeID] = append(events, m.events[employeeID]...)
+}
+
 func (m *MockRepository) Reset() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.employees = make(map[uuid.UUID]*Employee)
+	m.deleted = make(map[uuid.UUID]*Employee)
+	m.managers = make(map[uuid.UUID][]ReportingChainEntry)
+	m.subordinates = make(map[uuid.UUID][]Subordinate)
+	m.events = make(map[uuid.UUID][]AuditEvent)
 	m.findAllError = nil
 	m.findByIDError = nil
 	m.createError = nil
 	m.updateError = nil
 	m.deleteError = nil
+	m.TransactionCalls = 0
+	m.UpdatedFields = nil
 }
@@ -0,0 +1,164 @@
+package employee
+
+import (
+	"errors"
+	"time"
+
+	domainErrors "api-employees-and-departments/internal/domain/errors"
+	"api-employees-and-departments/internal/domain/logging"
+
+	"github.com/google/uuid"
+)
+
+// AnonymizedName replaces the name of anonymized employees
+const AnonymizedName = "Anonymized employee"
+
+// DataExport is everything held about an employee, as handed over on a data
+// subject access request under the LGPD
+type DataExport struct {
+	Employee Employee
+	// Memberships are the departments the employee belonged to, oldest first
+	Memberships []Membership
+	// ManagedDepartments are the departments the employee manages now
+	ManagedDepartments []Department
+	History            []AuditEvent
+	GeneratedAt        time.Time
+}
+
+// SetRetentionPeriod sets how long the data of a deleted employee must be kept
+// before it can be anonymized. It is zero by default.
+func (s *Service) SetRetentionPeriod(d time.Duration) {
+	s.retention = d
+}
+
+// ExportEmployeeData gathers everything held about an employee, deleted or not,
+// and records the export in its history
+func (s *Service) ExportEmployeeData(id uuid.UUID) (*DataExport, error) {
+	if id == uuid.Nil {
+		return nil, &domainErrors.ValidationError{Field: "id", Code: "employee.invalid_id", Message: "invalid employee id"}
+	}
+
+	emp, err := s.findAnyEmployee(id)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := s.repo.FindEvents(id)
+	if err != nil {
+		return nil, err
+	}
+	managed, err := s.deptRepo.FindByManagerID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &DataExport{
+		Employee:           *emp,
+		Memberships:        memberships(emp, history),
+		ManagedDepartments: managed,
+		History:            history,
+		GeneratedAt:        time.Now(),
+	}
+	names := make(map[uuid.UUID]string)
+	for i := range export.Memberships {
+		m := &export.Memberships[i]
+		name, ok := names[m.DepartmentID]
+		if !ok {
+			if dept, err := s.deptRepo.FindByID(m.DepartmentID); err == nil {
+				name = dept.Name
+			}
+			names[m.DepartmentID] = name
+		}
+		m.DepartmentName = name
+	}
+
+	// An export that can't be recorded is not handed over
+	if err := s.repo.RecordEvent(newAuditEvent(AuditDataExported, emp)); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Employee data exported",
+		logging.String("employee_id", id.String()),
+		logging.Int("history_events", len(history)),
+	)
+
+	return export, nil
+}
+
+// findAnyEmployee finds an employee whether it is active or soft-deleted
+func (s *Service) findAnyEmployee(id uuid.UUID) (*Employee, error) {
+	emp, err := s.repo.FindByID(id)
+	if err == nil || !errors.Is(err, domainErrors.ErrNotFound) {
+		return emp, err
+	}
+	if emp, err := s.repo.FindDeletedByID(id); err == nil {
+		return emp, nil
+	}
+	return nil, &domainErrors.NotFoundError{Code: "employee.not_found", Message: "employee not found"}
+}
+
+// AnonymizeEmployee irreversibly replaces the personal data of a deleted
+// employee whose retention period is over: the name becomes AnonymizedName and
+// the CPF and RG are erased. The record itself is kept, still deleted, so that
+// the departments it managed and the reports counting it stay consistent.
+func (s *Service) AnonymizeEmployee(id uuid.UUID) (*Employee, error) {
+	if id == uuid.Nil {
+		return nil, &domainErrors.ValidationError{Field: "id", Code: "employee.invalid_id", Message: "invalid employee id"}
+	}
+
+	emp, err := s.repo.FindDeletedByID(id)
+	if err != nil {
+		if _, activeErr := s.repo.FindByID(id); activeErr == nil {
+			return nil, &domainErrors.ConflictError{
+				Field:   "id",
+				Code:    "employee.anonymize_requires_deleted",
+				Message: "only deleted employees can be anonymized; delete the employee first",
+			}
+		}
+		return nil, &domainErrors.NotFoundError{Code: "employee.not_found", Message: "employee not found"}
+	}
+
+	if emp.AnonymizedAt != nil {
+		return nil, &domainErrors.ConflictError{
+			Field:   "id",
+			Code:    "employee.already_anonymized",
+			Message: "employee is already anonymized",
+		}
+	}
+	if until := emp.DeletedAt.Time.Add(s.retention); time.Now().Before(until) {
+		return nil, &domainErrors.ConflictError{
+			Field:   "id",
+			Code:    "employee.retention_period",
+			Params:  domainErrors.Params{"until": until.Format(time.RFC3339)},
+			Message: "the retention period of the employee data ends at " + until.Format(time.RFC3339),
+		}
+	}
+
+	now := time.Now()
+	emp.Name = AnonymizedName
+	emp.CPF = ""
+	emp.RG = nil
+	emp.AnonymizedAt = &now
+
+	err = s.repo.Transaction(func(repo Repository) error {
+		if err := repo.Anonymize(emp); err != nil {
+			return err
+		}
+		return repo.RecordEvent(newAuditEvent(AuditAnonymized, emp, "name", "cpf", "rg"))
+	})
+	if err != nil {
+		s.logger.Error("Failed to anonymize employee in repository",
+			logging.String("employee_id", id.String()),
+			logging.Error(err),
+		)
+		return nil, err
+	}
+	// Only the departments of a deleted manager remain, but they may still be cached
+	s.headcount.ManagerChanged(emp.ID)
+
+	s.logger.Info("Employee anonymized",
+		logging.String("employee_id", id.String()),
+	)
+
+	return emp, nil
+}
@@ -1,28 +1,121 @@
 package employee
 
-import "github.com/google/uuid"
+import (
+	"api-employees-and-departments/internal/domain/pagination"
+
+	"github.com/google/uuid"
+)
 
 type ListFilters struct {
 	Name         *string
 	CPF          *string
 	RG           *string
 	DepartmentID *uuid.UUID
+	Sort         []pagination.SortField // fields from SortFields; ID breaks ties
 	Page         int
 	PageSize     int
 }
 
+// SortFields are the fields employee listings can be ordered by
+var SortFields = []string{"name", "department_name", "created_at", "updated_at"}
+
+// SubordinateFilters narrows down the employees under a manager
+type SubordinateFilters struct {
+	Name         *string
+	DepartmentID *uuid.UUID
+	DirectOnly   bool
+	Page         int
+	PageSize     int
+}
+
+// Subordinate is an employee under a manager, either reporting directly to them
+// or indirectly through one of their sub-departments
+type Subordinate struct {
+	Employee
+	DepartmentName string
+	Direct         bool
+}
+
+// ExportRow is an employee with the names of its department and manager, as
+// written to spreadsheet exports
+type ExportRow struct {
+	Employee
+	DepartmentName string
+	ManagerName    string
+}
+
 type EmployeeWithManager struct {
 	Employee
 	ManagerName string
 }
 
+// ReportingChainEntry is a manager in an employee's chain of command
+type ReportingChainEntry struct {
+	Employee
+	DepartmentName string
+	Level          int // 1 for the direct manager
+}
+
 type Repository interface {
 	FindAll() ([]Employee, error)
 	FindByID(id uuid.UUID) (*Employee, error)
 	FindByIDWithManager(id uuid.UUID) (*EmployeeWithManager, error)
-	FindByDepartmentIDs(departmentIDs []uuid.UUID) ([]Employee, error)
+	// FindDepartmentManagers returns the manager of the employee's department and of
+	// every department above it, nearest first (Level is the department distance).
+	// The same person may appear more than once, including the employee itself.
+	FindDepartmentManagers(id uuid.UUID) ([]ReportingChainEntry, error)
+	// FindByDepartmentIDs returns the active employees of the departments ordered
+	// by name, at most limitPerDepartment of each one (0 for all of them)
+	FindByDepartmentIDs(departmentIDs []uuid.UUID, limitPerDepartment int) ([]Employee, error)
+	// FindSubordinates returns every employee in the departments managed by the
+	// manager and their descendants, in a single round trip
+	FindSubordinates(managerID uuid.UUID, filters SubordinateFilters) ([]Subordinate, int64, error)
 	FindWithFilters(filters ListFilters) ([]Employee, int64, error)
+	// FindPageWithFilters reads a keyset page of the employees matching the
+	// filters: up to keyset.Limit+1 of them past the cursor, in the order of
+	// filters.Sort in the direction of the traversal, each with its cursor. Page
+	// and PageSize are ignored.
+	FindPageWithFilters(filters ListFilters, keyset pagination.Keyset) ([]Employee, []pagination.Cursor, error)
+	CountWithFilters(filters ListFilters) (int64, error)
+	// StreamWithFilters calls fn for every employee matching the filters, ordered
+	// by name, without loading them all in memory. Pagination is ignored, and an
+	// error returned by fn stops the iteration.
+	StreamWithFilters(filters ListFilters, fn func(ExportRow) error) error
 	Create(emp *Employee) error
+	// Update writes every field only if the stored version still equals the
+	// record's Version, which is then incremented. A stale version returns a
+	// PreconditionFailedError.
 	Update(emp *Employee) error
+	// UpdateFields is like Update but writes only the named columns
+	UpdateFields(emp *Employee, fields ...string) error
 	Delete(id uuid.UUID) error
+
+	// LockVersion locks the record for the current transaction if its version
+	// still equals version, and returns a PreconditionFailedError otherwise
+	LockVersion(id uuid.UUID, version int64) error
+
+	// ExistsByCPF reports whether an active employee other than excludeID has the CPF
+	ExistsByCPF(cpf string, excludeID uuid.UUID) (bool, error)
+	// ExistsByRG reports whether an active employee other than excludeID has the RG
+	ExistsByRG(rg string, excludeID uuid.UUID) (bool, error)
+
+	// Soft-deleted records
+	FindDeleted(page, pageSize int) ([]Employee, int64, error)
+	FindDeletedByID(id uuid.UUID) (*Employee, error)
+	// FindDeletedByCPF returns the most recently soft-deleted record with the CPF
+	FindDeletedByCPF(cpf string) (*Employee, error)
+	Restore(id uuid.UUID) error
+	Purge(id uuid.UUID) error
+	// Anonymize writes the anonymized personal data of a soft-deleted record
+	// under the same version check as Update
+	Anonymize(emp *Employee) error
+
+	// RecordEvent appends an event to the history of an employee
+	RecordEvent(event *AuditEvent) error
+	// FindEvents returns the history of an employee, oldest first
+	FindEvents(employeeID uuid.UUID) ([]AuditEvent, error)
+
+	// Transaction runs fn inside a database transaction, passing a repository
+	// bound to it. Returning an error from fn rolls the transaction back.
+	Transaction(fn func(repo Repository) error) error
 }
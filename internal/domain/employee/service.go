@@ -3,25 +3,111 @@ package employee
 import (
 	"errors"
 	"fmt"
+	"time"
 
+	domainErrors "api-employees-and-departments/internal/domain/errors"
 	"api-employees-and-departments/internal/domain/logging"
+	"api-employees-and-departments/internal/domain/pagination"
 	"api-employees-and-departments/internal/domain/validators"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
+type DepartmentRepository interface {
+	FindByID(id uuid.UUID) (Department, error)
+	FindByManagerID(managerID uuid.UUID) ([]Department, error)
+	// CountAllByManagerID counts the departments managed by managerID, including
+	// soft-deleted ones, which keep their manager and may be restored
+	CountAllByManagerID(managerID uuid.UUID) (int64, error)
+	// FindByName returns the active departments named name, ignoring case
+	FindByName(name string) ([]Department, error)
+}
+
+type Department struct {
+	ID                 uuid.UUID
+	Name               string
+	ManagerID          uuid.UUID
+	ParentDepartmentID *uuid.UUID
+}
+
+// RehirePolicy decides what creating an employee does when a soft-deleted record
+// with the same CPF exists
+type RehirePolicy string
+
+const (
+	// RehireOffer refuses to create and reports the previous record so the caller
+	// can choose between the other two policies
+	RehireOffer RehirePolicy = "offer"
+	// RehireRestore undeletes the previous record and applies the new data to it
+	RehireRestore RehirePolicy = "restore"
+	// RehireIgnore creates a new record, leaving the previous one deleted
+	RehireIgnore RehirePolicy = "ignore"
+)
+
+// PreviousRecordError is returned under RehireOffer when the CPF belongs to a
+// soft-deleted employee
+type PreviousRecordError struct {
+	PreviousID uuid.UUID
+	DeletedAt  time.Time
+}
+
+func (e *PreviousRecordError) Error() string {
+	return fmt.Sprintf("a deleted employee with this CPF already exists (%s); restore it or create a new record", e.PreviousID)
+}
+
+// Patch is a partial update of an employee. Nil fields keep their current value;
+// RG is only changed when RGSet is true, and a nil RG then clears it. A non-zero
+// Version is the version the change was based on.
+type Patch struct {
+	Name         *string
+	CPF          *string
+	RG           *string
+	RGSet        bool
+	DepartmentID *uuid.UUID
+	Version      int64
+}
+
+// HeadcountObserver is told which departments gained or lost active employees,
+// so that data derived from their headcount can be refreshed, and which
+// employees were renamed, deleted, restored or anonymized, since departments
+// show the names of their managers
+type HeadcountObserver interface {
+	HeadcountChanged(departmentIDs ...uuid.UUID)
+	ManagerChanged(employeeID uuid.UUID)
+}
+
+type noHeadcountObserver struct{}
+
+func (noHeadcountObserver) HeadcountChanged(...uuid.UUID) {}
+
+func (noHeadcountObserver) ManagerChanged(uuid.UUID) {}
+
 type Service struct {
-	repo   Repository
-	logger logging.Logger
+	repo      Repository
+	deptRepo  DepartmentRepository
+	logger    logging.Logger
+	headcount HeadcountObserver
+	retention time.Duration
 }
 
-func NewService(r Repository, logger logging.Logger) *Service {
+func NewService(r Repository, deptRepo DepartmentRepository, logger logging.Logger) *Service {
 	return &Service{
-		repo:   r,
-		logger: logger,
+		repo:      r,
+		deptRepo:  deptRepo,
+		logger:    logger,
+		headcount: noHeadcountObserver{},
 	}
 }
 
+// SetHeadcountObserver registers the observer notified after employees are
+// created, moved between departments, deleted or restored. It is set after
+// construction because the department service, its usual implementation, is
+// built after this one.
+func (s *Service) SetHeadcountObserver(o HeadcountObserver) {
+	s.headcount = o
+}
+
 func (s *Service) GetAllEmployees() ([]Employee, error) {
 	return s.repo.FindAll()
 }
@@ -30,35 +116,185 @@ func (s *Service) ListEmployees(filters ListFilters) ([]Employee, int64, error)
 	return s.repo.FindWithFilters(filters)
 }
 
+// ListEmployeesPage lists the employees matching the filters with keyset
+// pagination, counting them only when keyset.WithTotal is set
+func (s *Service) ListEmployeesPage(filters ListFilters, keyset pagination.Keyset) ([]Employee, pagination.Info, error) {
+	rows, cursors, err := s.repo.FindPageWithFilters(filters, keyset)
+	if err != nil {
+		return nil, pagination.Info{}, err
+	}
+	employees, info := pagination.Cut(rows, cursors, keyset)
+
+	if keyset.WithTotal {
+		total, err := s.repo.CountWithFilters(filters)
+		if err != nil {
+			return nil, pagination.Info{}, err
+		}
+		info.Total = &total
+	}
+	return employees, info, nil
+}
+
+// ExportEmployees passes every employee matching the filters to fn, one at a
+// time, so exports do not depend on pagination
+func (s *Service) ExportEmployees(filters ListFilters, fn func(ExportRow) error) error {
+	return s.repo.StreamWithFilters(filters, fn)
+}
+
 func (s *Service) GetEmployeeByID(id uuid.UUID) (*Employee, error) {
 	if id == uuid.Nil {
-		return nil, errors.New("invalid employee id")
+		return nil, &domainErrors.ValidationError{Field: "id", Code: "employee.invalid_id", Message: "invalid employee id"}
 	}
 	return s.repo.FindByID(id)
 }
 
 func (s *Service) GetEmployeeWithManager(id uuid.UUID) (*EmployeeWithManager, error) {
 	if id == uuid.Nil {
-		return nil, errors.New("invalid employee id")
+		return nil, &domainErrors.ValidationError{Field: "id", Code: "employee.invalid_id", Message: "invalid employee id"}
 	}
 	return s.repo.FindByIDWithManager(id)
 }
 
+// GetReportingChain returns the chain of command of an employee, from the direct
+// manager up to the top of the department tree. An employee who manages their own
+// department reports to the manager of the parent department.
+func (s *Service) GetReportingChain(id uuid.UUID) ([]ReportingChainEntry, error) {
+	if id == uuid.Nil {
+		return nil, &domainErrors.ValidationError{Field: "id", Code: "employee.invalid_id", Message: "invalid employee id"}
+	}
+
+	if _, err := s.repo.FindByID(id); err != nil {
+		return nil, err
+	}
+
+	managers, err := s.repo.FindDepartmentManagers(id)
+	if err != nil {
+		s.logger.Error("Failed to fetch department managers",
+			logging.String("employee_id", id.String()),
+			logging.Error(err),
+		)
+		return nil, err
+	}
+
+	// A person managing several consecutive levels (or the employee's own
+	// department) must only appear once in the chain
+	seen := map[uuid.UUID]bool{id: true}
+	chain := make([]ReportingChainEntry, 0, len(managers))
+	for _, manager := range managers {
+		if seen[manager.ID] {
+			continue
+		}
+		seen[manager.ID] = true
+		manager.Level = len(chain) + 1
+		chain = append(chain, manager)
+	}
+
+	return chain, nil
+}
+
+// ListSubordinates returns a page of the employees under a manager, recursively
+// including every sub-department of the departments they manage
+func (s *Service) ListSubordinates(managerID uuid.UUID, filters SubordinateFilters) ([]Subordinate, int64, error) {
+	if managerID == uuid.Nil {
+		return nil, 0, &domainErrors.ValidationError{Field: "id", Code: "manager.invalid_id", Message: "invalid manager id"}
+	}
+
+	if _, err := s.repo.FindByID(managerID); err != nil {
+		return nil, 0, err
+	}
+
+	return s.repo.FindSubordinates(managerID, filters)
+}
+
 func (s *Service) GetEmployeesByDepartmentIDs(departmentIDs []uuid.UUID) ([]Employee, error) {
-	return s.repo.FindByDepartmentIDs(departmentIDs)
+	return s.repo.FindByDepartmentIDs(departmentIDs, 0)
 }
 
 func (s *Service) CreateEmployee(emp *Employee) error {
+	_, err := s.CreateEmployeeWithPolicy(emp, RehireOffer)
+	return err
+}
+
+// CreateEmployeeWithPolicy creates an employee, handling a soft-deleted record
+// with the same CPF according to policy. It reports whether the previous record
+// was restored instead of a new one created; either way emp ends up holding the
+// stored employee.
+func (s *Service) CreateEmployeeWithPolicy(emp *Employee, policy RehirePolicy) (bool, error) {
+	if policy == "" {
+		policy = RehireOffer
+	}
+
 	if err := s.validateEmployee(emp); err != nil {
 		s.logger.Warn("Employee validation failed",
 			logging.String("name", emp.Name),
-			logging.String("cpf", emp.CPF),
+			logging.Error(err),
+		)
+		return false, err
+	}
+
+	if policy != RehireIgnore {
+		previous, err := s.repo.FindDeletedByCPF(emp.CPF)
+		switch {
+		case err == nil:
+			if policy == RehireOffer {
+				return false, &PreviousRecordError{PreviousID: previous.ID, DeletedAt: previous.DeletedAt.Time}
+			}
+			return true, s.rehire(previous, emp)
+		case !errors.Is(err, domainErrors.ErrNotFound):
+			return false, err
+		}
+	}
+
+	return false, s.create(emp)
+}
+
+// rehire restores a previous record with the data of a new hire
+func (s *Service) rehire(previous, emp *Employee) error {
+	previous.Name = emp.Name
+	previous.RG = emp.RG
+	previous.DepartmentID = emp.DepartmentID
+
+	if err := s.validateRestorable(previous); err != nil {
+		s.logger.Warn("Employee rehire validation failed",
+			logging.String("employee_id", previous.ID.String()),
 			logging.Error(err),
 		)
 		return err
 	}
 
-	if err := s.repo.Create(emp); err != nil {
+	err := s.writeRecorded(AuditRehired, previous, func(repo Repository) error {
+		if err := repo.Restore(previous.ID); err != nil {
+			return err
+		}
+		previous.DeletedAt = gorm.DeletedAt{}
+		return repo.Update(previous)
+	}, "name", "rg", "department_id")
+	if err != nil {
+		s.logger.Error("Failed to rehire employee in repository",
+			logging.String("employee_id", previous.ID.String()),
+			logging.Error(err),
+		)
+		return err
+	}
+
+	*emp = *previous
+	s.headcount.HeadcountChanged(emp.DepartmentID)
+	s.headcount.ManagerChanged(emp.ID)
+
+	s.logger.Info("Employee rehired from previous record",
+		logging.String("employee_id", emp.ID.String()),
+		logging.String("name", emp.Name),
+		logging.String("department_id", emp.DepartmentID.String()),
+	)
+
+	return nil
+}
+
+func (s *Service) create(emp *Employee) error {
+	err := s.writeRecorded(AuditCreated, emp, func(repo Repository) error {
+		return repo.Create(emp)
+	})
+	if err != nil {
 		s.logger.Error("Failed to create employee in repository",
 			logging.String("employee_id", emp.ID.String()),
 			logging.String("name", emp.Name),
@@ -66,20 +302,23 @@ func (s *Service) CreateEmployee(emp *Employee) error {
 		)
 		return err
 	}
+	s.headcount.HeadcountChanged(emp.DepartmentID)
 
 	s.logger.Info("Employee created successfully",
 		logging.String("employee_id", emp.ID.String()),
 		logging.String("name", emp.Name),
-		logging.String("cpf", emp.CPF),
 		logging.String("department_id", emp.DepartmentID.String()),
 	)
 
 	return nil
 }
 
+// UpdateEmployee replaces the data of an employee. A non-zero emp.Version is the
+// version the change was based on, and the update fails with a
+// PreconditionFailedError if the record changed since then.
 func (s *Service) UpdateEmployee(id uuid.UUID, emp *Employee) error {
 	if id == uuid.Nil {
-		return errors.New("invalid employee id")
+		return &domainErrors.ValidationError{Field: "id", Code: "employee.invalid_id", Message: "invalid employee id"}
 	}
 
 	existing, err := s.repo.FindByID(id)
@@ -88,7 +327,18 @@ func (s *Service) UpdateEmployee(id uuid.UUID, emp *Employee) error {
 			logging.String("employee_id", id.String()),
 			logging.Error(err),
 		)
-		return fmt.Errorf("employee not found: %w", err)
+		return err
+	}
+
+	if emp.Version == 0 {
+		emp.Version = existing.Version
+	} else if emp.Version != existing.Version {
+		s.logger.Warn("Employee update based on a stale version",
+			logging.String("employee_id", id.String()),
+			logging.Int64("expected_version", emp.Version),
+			logging.Int64("current_version", existing.Version),
+		)
+		return versionMismatch()
 	}
 
 	if err := s.validateEmployee(emp); err != nil {
@@ -98,15 +348,34 @@ func (s *Service) UpdateEmployee(id uuid.UUID, emp *Employee) error {
 		)
 		return err
 	}
+	if emp.DepartmentID != existing.DepartmentID {
+		if err := s.validateDepartmentActive(emp.DepartmentID); err != nil {
+			s.logger.Warn("Employee update validation failed",
+				logging.String("employee_id", id.String()),
+				logging.Error(err),
+			)
+			return err
+		}
+	}
 
 	emp.ID = existing.ID
-	if err := s.repo.Update(emp); err != nil {
+	emp.CreatedAt = existing.CreatedAt
+	err = s.writeRecorded(AuditUpdated, emp, func(repo Repository) error {
+		return repo.Update(emp)
+	}, changedFields(existing, emp)...)
+	if err != nil {
 		s.logger.Error("Failed to update employee in repository",
 			logging.String("employee_id", id.String()),
 			logging.Error(err),
 		)
 		return err
 	}
+	if emp.DepartmentID != existing.DepartmentID {
+		s.headcount.HeadcountChanged(existing.DepartmentID, emp.DepartmentID)
+	}
+	if emp.Name != existing.Name {
+		s.headcount.ManagerChanged(emp.ID)
+	}
 
 	s.logger.Info("Employee updated successfully",
 		logging.String("employee_id", id.String()),
@@ -116,9 +385,124 @@ func (s *Service) UpdateEmployee(id uuid.UUID, emp *Employee) error {
 	return nil
 }
 
-func (s *Service) DeleteEmployee(id uuid.UUID) error {
+// PatchEmployee applies a partial update, validating and writing only the fields
+// present in the patch, and returns the updated employee
+func (s *Service) PatchEmployee(id uuid.UUID, patch Patch) (*Employee, error) {
 	if id == uuid.Nil {
-		return errors.New("invalid employee id")
+		return nil, &domainErrors.ValidationError{Field: "id", Code: "employee.invalid_id", Message: "invalid employee id"}
+	}
+
+	existing, err := s.repo.FindByID(id)
+	if err != nil {
+		s.logger.Error("Employee not found for patch",
+			logging.String("employee_id", id.String()),
+			logging.Error(err),
+		)
+		return nil, err
+	}
+
+	if patch.Version != 0 && patch.Version != existing.Version {
+		s.logger.Warn("Employee patch based on a stale version",
+			logging.String("employee_id", id.String()),
+			logging.Int64("expected_version", patch.Version),
+			logging.Int64("current_version", existing.Version),
+		)
+		return nil, versionMismatch()
+	}
+
+	emp := *existing
+	fields, err := applyPatch(&emp, patch)
+	if err != nil {
+		s.logger.Warn("Employee patch validation failed",
+			logging.String("employee_id", id.String()),
+			logging.Error(err),
+		)
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return existing, nil
+	}
+	if emp.DepartmentID != existing.DepartmentID {
+		if err := s.validateDepartmentActive(emp.DepartmentID); err != nil {
+			s.logger.Warn("Employee patch validation failed",
+				logging.String("employee_id", id.String()),
+				logging.Error(err),
+			)
+			return nil, err
+		}
+	}
+
+	err = s.writeRecorded(AuditUpdated, &emp, func(repo Repository) error {
+		return repo.UpdateFields(&emp, fields...)
+	}, fields...)
+	if err != nil {
+		s.logger.Error("Failed to patch employee in repository",
+			logging.String("employee_id", id.String()),
+			logging.Error(err),
+		)
+		return nil, err
+	}
+	if emp.DepartmentID != existing.DepartmentID {
+		s.headcount.HeadcountChanged(existing.DepartmentID, emp.DepartmentID)
+	}
+	if emp.Name != existing.Name {
+		s.headcount.ManagerChanged(emp.ID)
+	}
+
+	s.logger.Info("Employee patched successfully",
+		logging.String("employee_id", id.String()),
+		logging.Int("changed_fields", len(fields)),
+	)
+
+	return &emp, nil
+}
+
+// applyPatch copies the fields present in the patch into emp, validating each of
+// them, and returns the columns that changed
+func applyPatch(emp *Employee, patch Patch) ([]string, error) {
+	var fields []string
+
+	if patch.Name != nil {
+		if *patch.Name == "" {
+			return nil, &domainErrors.ValidationError{Field: "name", Code: "employee.name_required", Message: "employee name is required"}
+		}
+		emp.Name = *patch.Name
+		fields = append(fields, "name")
+	}
+
+	if patch.CPF != nil {
+		cpf := validators.NormalizeCPF(*patch.CPF)
+		if cpf == "" {
+			return nil, &domainErrors.ValidationError{Field: "cpf", Code: "employee.cpf_required", Message: "employee CPF is required"}
+		}
+		if !validCPF(cpf) {
+			return nil, &domainErrors.ValidationError{Field: "cpf", Code: "cpf.invalid", Message: "invalid CPF", Err: domainErrors.ErrInvalidCPF}
+		}
+		emp.CPF = cpf
+		fields = append(fields, "cpf")
+	}
+
+	if patch.RGSet {
+		emp.RG = patch.RG
+		fields = append(fields, "rg")
+	}
+
+	if patch.DepartmentID != nil {
+		if *patch.DepartmentID == uuid.Nil {
+			return nil, &domainErrors.ValidationError{Field: "department_id", Code: "employee.department_required", Message: "employee department is required"}
+		}
+		emp.DepartmentID = *patch.DepartmentID
+		fields = append(fields, "department_id")
+	}
+
+	return fields, nil
+}
+
+// DeleteEmployee soft-deletes an employee. A non-zero expectedVersion makes the
+// deletion fail with a PreconditionFailedError if the record changed since then.
+func (s *Service) DeleteEmployee(id uuid.UUID, expectedVersion int64) error {
+	if id == uuid.Nil {
+		return &domainErrors.ValidationError{Field: "id", Code: "employee.invalid_id", Message: "invalid employee id"}
 	}
 
 	employee, err := s.repo.FindByID(id)
@@ -127,16 +511,26 @@ func (s *Service) DeleteEmployee(id uuid.UUID) error {
 			logging.String("employee_id", id.String()),
 			logging.Error(err),
 		)
-		return fmt.Errorf("employee not found: %w", err)
+		return err
 	}
 
-	if err := s.repo.Delete(id); err != nil {
+	err = s.writeRecorded(AuditDeleted, employee, func(repo Repository) error {
+		if expectedVersion != 0 {
+			if err := repo.LockVersion(id, expectedVersion); err != nil {
+				return err
+			}
+		}
+		return repo.Delete(id)
+	})
+	if err != nil {
 		s.logger.Error("Failed to delete employee in repository",
 			logging.String("employee_id", id.String()),
 			logging.Error(err),
 		)
 		return err
 	}
+	s.headcount.HeadcountChanged(employee.DepartmentID)
+	s.headcount.ManagerChanged(employee.ID)
 
 	s.logger.Info("Employee deleted successfully",
 		logging.String("employee_id", id.String()),
@@ -146,18 +540,232 @@ func (s *Service) DeleteEmployee(id uuid.UUID) error {
 	return nil
 }
 
-func (s *Service) validateEmployee(emp *Employee) error {
-	if emp.Name == "" {
-		return errors.New("employee name is required")
+func (s *Service) ListDeletedEmployees(page, pageSize int) ([]Employee, int64, error) {
+	return s.repo.FindDeleted(page, pageSize)
+}
+
+// RestoreEmployee undeletes a soft-deleted employee after re-running the domain
+// validations, since the world may have changed while the record was deleted
+func (s *Service) RestoreEmployee(id uuid.UUID) (*Employee, error) {
+	if id == uuid.Nil {
+		return nil, &domainErrors.ValidationError{Field: "id", Code: "employee.invalid_id", Message: "invalid employee id"}
 	}
-	if emp.CPF == "" {
-		return errors.New("employee CPF is required")
+
+	emp, err := s.repo.FindDeletedByID(id)
+	if err != nil {
+		return nil, &domainErrors.NotFoundError{Code: "deleted_employee.not_found", Message: "deleted employee not found"}
+	}
+
+	if emp.AnonymizedAt != nil {
+		return nil, &domainErrors.ConflictError{
+			Field:   "id",
+			Code:    "employee.anonymized",
+			Message: "anonymized employees cannot be restored",
+		}
+	}
+
+	if err := s.validateEmployee(emp); err != nil {
+		return nil, err
+	}
+
+	if err := s.validateRestorable(emp); err != nil {
+		s.logger.Warn("Employee restore validation failed",
+			logging.String("employee_id", id.String()),
+			logging.Error(err),
+		)
+		return nil, err
+	}
+
+	err = s.writeRecorded(AuditRestored, emp, func(repo Repository) error {
+		if err := repo.Restore(id); err != nil {
+			return err
+		}
+		emp.DeletedAt = gorm.DeletedAt{}
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("Failed to restore employee in repository",
+			logging.String("employee_id", id.String()),
+			logging.Error(err),
+		)
+		return nil, err
+	}
+
+	s.headcount.HeadcountChanged(emp.DepartmentID)
+	s.headcount.ManagerChanged(emp.ID)
+
+	s.logger.Info("Employee restored successfully",
+		logging.String("employee_id", id.String()),
+		logging.String("name", emp.Name),
+	)
+
+	return emp, nil
+}
+
+// PurgeEmployee permanently removes a soft-deleted employee
+func (s *Service) PurgeEmployee(id uuid.UUID) error {
+	if id == uuid.Nil {
+		return &domainErrors.ValidationError{Field: "id", Code: "employee.invalid_id", Message: "invalid employee id"}
+	}
+
+	emp, err := s.repo.FindDeletedByID(id)
+	if err != nil {
+		return &domainErrors.NotFoundError{
+			Code:    "employee.purge_requires_deleted",
+			Message: "deleted employee not found (only soft-deleted employees can be purged)",
+		}
 	}
-	if !validators.ValidateCPF(emp.CPF) {
-		return errors.New("invalid CPF")
+
+	// departments.manager_id has no foreign key, so the reference must be checked
+	// here, deleted departments included since restoring one brings it back
+	managed, err := s.deptRepo.CountAllByManagerID(id)
+	if err != nil {
+		return err
+	}
+	if managed > 0 {
+		return &domainErrors.ConflictError{
+			Field:   "id",
+			Code:    "employee.still_manages_departments",
+			Params:  domainErrors.Params{"count": managed},
+			Message: fmt.Sprintf("employee still manages %d departments, deleted ones included", managed),
+		}
+	}
+
+	if err := s.repo.Purge(id); err != nil {
+		s.logger.Error("Failed to purge employee in repository",
+			logging.String("employee_id", id.String()),
+			logging.Error(err),
+		)
+		return err
+	}
+
+	s.logger.Info("Employee purged successfully",
+		logging.String("employee_id", id.String()),
+		logging.String("name", emp.Name),
+	)
+
+	return nil
+}
+
+// changedFields names the columns an update changed, for the audit history
+func changedFields(before, after *Employee) []string {
+	var fields []string
+	if after.Name != before.Name {
+		fields = append(fields, "name")
+	}
+	if after.CPF != before.CPF {
+		fields = append(fields, "cpf")
+	}
+	if !equalRG(after.RG, before.RG) {
+		fields = append(fields, "rg")
+	}
+	if after.DepartmentID != before.DepartmentID {
+		fields = append(fields, "department_id")
+	}
+	return fields
+}
+
+func equalRG(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// validateRestorable checks the constraints a soft-deleted record may now violate
+func (s *Service) validateRestorable(emp *Employee) error {
+	exists, err := s.repo.ExistsByCPF(emp.CPF, emp.ID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return &domainErrors.ConflictError{
+			Field:   "cpf",
+			Code:    "cpf.duplicate",
+			Message: domainErrors.ErrDuplicateCPF.Error(),
+			Err:     domainErrors.ErrDuplicateCPF,
+		}
+	}
+
+	if emp.RG != nil && *emp.RG != "" {
+		exists, err := s.repo.ExistsByRG(*emp.RG, emp.ID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return &domainErrors.ConflictError{
+				Field:   "rg",
+				Code:    "rg.duplicate",
+				Message: domainErrors.ErrDuplicateRG.Error(),
+				Err:     domainErrors.ErrDuplicateRG,
+			}
+		}
+	}
+
+	if _, err := s.deptRepo.FindByID(emp.DepartmentID); err != nil {
+		return &domainErrors.ValidationError{
+			Field:   "department_id",
+			Code:    "employee.department_gone",
+			Message: "department no longer exists",
+		}
+	}
+
+	return nil
+}
+
+// validateDepartmentActive checks that an employee is moved into an active
+// department; the foreign key alone lets a soft-deleted one through
+func (s *Service) validateDepartmentActive(departmentID uuid.UUID) error {
+	if _, err := s.deptRepo.FindByID(departmentID); err != nil {
+		if errors.Is(err, domainErrors.ErrNotFound) {
+			return &domainErrors.ValidationError{
+				Field:   "department_id",
+				Code:    "employee.department_not_found",
+				Message: "department not found",
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *Service) validateEmployee(emp *Employee) error {
+	if errs := validatePersonalData(emp); len(errs) > 0 {
+		return errs[0]
 	}
 	if emp.DepartmentID == uuid.Nil {
-		return errors.New("employee department is required")
+		return &domainErrors.ValidationError{Field: "department_id", Code: "employee.department_required", Message: "employee department is required"}
 	}
 	return nil
 }
+
+// validatePersonalData checks the name and CPF, reporting every invalid field.
+// The CPF is normalized first, so it is stored as its 11 digits however it was
+// typed.
+func validatePersonalData(emp *Employee) []error {
+	var errs []error
+	if emp.Name == "" {
+		errs = append(errs, &domainErrors.ValidationError{Field: "name", Code: "employee.name_required", Message: "employee name is required"})
+	}
+	emp.CPF = validators.NormalizeCPF(emp.CPF)
+	if emp.CPF == "" {
+		errs = append(errs, &domainErrors.ValidationError{Field: "cpf", Code: "employee.cpf_required", Message: "employee CPF is required"})
+	} else if !validCPF(emp.CPF) {
+		errs = append(errs, &domainErrors.ValidationError{Field: "cpf", Code: "cpf.invalid", Message: "invalid CPF", Err: domainErrors.ErrInvalidCPF})
+	}
+	return errs
+}
+
+// validCPF reports whether a normalized CPF is its 11 digits with valid check
+// digits; ValidateCPF alone would also accept other separators
+func validCPF(cpf string) bool {
+	return len(cpf) == 11 && validators.ValidateCPF(cpf)
+}
+
+// versionMismatch reports a write based on a version that is no longer current
+func versionMismatch() error {
+	return &domainErrors.PreconditionFailedError{
+		Code:    "employee.version_mismatch",
+		Message: "employee was modified by another request",
+	}
+}
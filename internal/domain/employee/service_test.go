@@ -2,9 +2,13 @@ package employee
 
 import (
 	"errors"
+	"slices"
 	"testing"
+	"time"
 
+	domainErrors "api-employees-and-departments/internal/domain/errors"
 	"api-employees-and-departments/internal/domain/logging"
+	"api-employees-and-departments/internal/domain/pagination"
 
 	"github.com/google/uuid"
 )
@@ -13,7 +17,7 @@ func TestNewService(t *testing.T) {
 	repo := NewMockRepository()
 	logger := logging.NewMockLogger()
 
-	service := NewService(repo, logger)
+	service := NewService(repo, NewMockDepartmentRepository(), logger)
 
 	if service == nil {
 		t.Error("NewService() returned nil")
@@ -21,6 +25,9 @@ func TestNewService(t *testing.T) {
 	if service.repo == nil {
 		t.Error("Service repository is nil")
 	}
+	if service.deptRepo == nil {
+		t.Error("Service department repository is nil")
+	}
 	if service.logger == nil {
 		t.Error("Service logger is nil")
 	}
@@ -29,7 +36,7 @@ func TestNewService(t *testing.T) {
 func TestGetAllEmployees(t *testing.T) {
 	repo := NewMockRepository()
 	logger := logging.NewMockLogger()
-	service := NewService(repo, logger)
+	service := NewService(repo, NewMockDepartmentRepository(), logger)
 
 	deptID := uuid.New()
 	emp1 := &Employee{ID: uuid.New(), Name: "John Doe", CPF: "12345678909", DepartmentID: deptID}
@@ -51,7 +58,7 @@ func TestGetAllEmployees(t *testing.T) {
 func TestGetEmployeeByID(t *testing.T) {
 	repo := NewMockRepository()
 	logger := logging.NewMockLogger()
-	service := NewService(repo, logger)
+	service := NewService(repo, NewMockDepartmentRepository(), logger)
 
 	deptID := uuid.New()
 	emp := &Employee{ID: uuid.New(), Name: "John Doe", CPF: "12345678909", DepartmentID: deptID}
@@ -86,7 +93,7 @@ func TestCreateEmployee(t *testing.T) {
 	t.Run("valid employee", func(t *testing.T) {
 		repo := NewMockRepository()
 		logger := logging.NewMockLogger()
-		service := NewService(repo, logger)
+		service := NewService(repo, NewMockDepartmentRepository(), logger)
 
 		emp := &Employee{
 			Name:         "John Doe",
@@ -110,7 +117,7 @@ func TestCreateEmployee(t *testing.T) {
 	t.Run("missing name", func(t *testing.T) {
 		repo := NewMockRepository()
 		logger := logging.NewMockLogger()
-		service := NewService(repo, logger)
+		service := NewService(repo, NewMockDepartmentRepository(), logger)
 
 		emp := &Employee{
 			CPF:          "12345678909",
@@ -130,7 +137,7 @@ func TestCreateEmployee(t *testing.T) {
 	t.Run("missing CPF", func(t *testing.T) {
 		repo := NewMockRepository()
 		logger := logging.NewMockLogger()
-		service := NewService(repo, logger)
+		service := NewService(repo, NewMockDepartmentRepository(), logger)
 
 		emp := &Employee{
 			Name:         "John Doe",
@@ -147,7 +154,7 @@ func TestCreateEmployee(t *testing.T) {
 	t.Run("invalid CPF", func(t *testing.T) {
 		repo := NewMockRepository()
 		logger := logging.NewMockLogger()
-		service := NewService(repo, logger)
+		service := NewService(repo, NewMockDepartmentRepository(), logger)
 
 		emp := &Employee{
 			Name:         "John Doe",
@@ -160,12 +167,54 @@ func TestCreateEmployee(t *testing.T) {
 		if err == nil {
 			t.Error("CreateEmployee() should return error for invalid CPF")
 		}
+		var validation *domainErrors.ValidationError
+		if !errors.As(err, &validation) || validation.Field != "cpf" {
+			t.Errorf("CreateEmployee() should return a ValidationError on cpf, got %v", err)
+		}
+		if !errors.Is(err, domainErrors.ErrInvalidCPF) {
+			t.Error("CreateEmployee() error should wrap ErrInvalidCPF")
+		}
+	})
+
+	t.Run("formatted CPF is stored as digits", func(t *testing.T) {
+		repo := NewMockRepository()
+		logger := logging.NewMockLogger()
+		service := NewService(repo, NewMockDepartmentRepository(), logger)
+
+		emp := &Employee{
+			Name:         "John Doe",
+			CPF:          " 123.456.789-09 ",
+			DepartmentID: uuid.New(),
+		}
+
+		if err := service.CreateEmployee(emp); err != nil {
+			t.Fatalf("CreateEmployee() returned error: %v", err)
+		}
+		if emp.CPF != "12345678909" {
+			t.Errorf("CreateEmployee() stored CPF %q, want 12345678909", emp.CPF)
+		}
+	})
+
+	t.Run("CPF with other separators", func(t *testing.T) {
+		repo := NewMockRepository()
+		logger := logging.NewMockLogger()
+		service := NewService(repo, NewMockDepartmentRepository(), logger)
+
+		emp := &Employee{
+			Name:         "John Doe",
+			CPF:          "123/456/789-09",
+			DepartmentID: uuid.New(),
+		}
+
+		if err := service.CreateEmployee(emp); !errors.Is(err, domainErrors.ErrInvalidCPF) {
+			t.Errorf("CreateEmployee() error = %v, want ErrInvalidCPF", err)
+		}
 	})
 
 	t.Run("missing department", func(t *testing.T) {
 		repo := NewMockRepository()
 		logger := logging.NewMockLogger()
-		service := NewService(repo, logger)
+		service := NewService(repo, NewMockDepartmentRepository(), logger)
 
 		emp := &Employee{
 			Name: "John Doe",
@@ -182,7 +231,7 @@ func TestCreateEmployee(t *testing.T) {
 	t.Run("repository error", func(t *testing.T) {
 		repo := NewMockRepository()
 		logger := logging.NewMockLogger()
-		service := NewService(repo, logger)
+		service := NewService(repo, NewMockDepartmentRepository(), logger)
 
 		repo.SetCreateError(errors.New("database error"))
 
@@ -207,7 +256,7 @@ func TestUpdateEmployee(t *testing.T) {
 	t.Run("valid update", func(t *testing.T) {
 		repo := NewMockRepository()
 		logger := logging.NewMockLogger()
-		service := NewService(repo, logger)
+		service := NewService(repo, NewMockDepartmentRepository(), logger)
 
 		deptID := uuid.New()
 		emp := &Employee{ID: uuid.New(), Name: "John Doe", CPF: "12345678909", DepartmentID: deptID}
@@ -229,10 +278,27 @@ func TestUpdateEmployee(t *testing.T) {
 		}
 	})
 
+	t.Run("move into a deleted department", func(t *testing.T) {
+		repo := NewMockRepository()
+		service := NewService(repo, NewMockDepartmentRepository(), logging.NewMockLogger())
+
+		emp := &Employee{ID: uuid.New(), Name: "John Doe", CPF: "12345678909", DepartmentID: uuid.New()}
+		repo.AddEmployee(emp)
+
+		// The department repository only finds active departments
+		update := &Employee{Name: "John Doe", CPF: "12345678909", DepartmentID: uuid.New()}
+		err := service.UpdateEmployee(emp.ID, update)
+
+		var validationErr *domainErrors.ValidationError
+		if !errors.As(err, &validationErr) || validationErr.Code != "employee.department_not_found" {
+			t.Errorf("UpdateEmployee() should reject a deleted department, got %v", err)
+		}
+	})
+
 	t.Run("nil ID", func(t *testing.T) {
 		repo := NewMockRepository()
 		logger := logging.NewMockLogger()
-		service := NewService(repo, logger)
+		service := NewService(repo, NewMockDepartmentRepository(), logger)
 
 		emp := &Employee{Name: "John Doe", CPF: "12345678909", DepartmentID: uuid.New()}
 
@@ -246,7 +312,7 @@ func TestUpdateEmployee(t *testing.T) {
 	t.Run("non-existent employee", func(t *testing.T) {
 		repo := NewMockRepository()
 		logger := logging.NewMockLogger()
-		service := NewService(repo, logger)
+		service := NewService(repo, NewMockDepartmentRepository(), logger)
 
 		emp := &Employee{Name: "John Doe", CPF: "12345678909", DepartmentID: uuid.New()}
 
@@ -263,7 +329,7 @@ func TestUpdateEmployee(t *testing.T) {
 	t.Run("validation error", func(t *testing.T) {
 		repo := NewMockRepository()
 		logger := logging.NewMockLogger()
-		service := NewService(repo, logger)
+		service := NewService(repo, NewMockDepartmentRepository(), logger)
 
 		deptID := uuid.New()
 		emp := &Employee{ID: uuid.New(), Name: "John Doe", CPF: "12345678909", DepartmentID: deptID}
@@ -280,19 +346,186 @@ func TestUpdateEmployee(t *testing.T) {
 			t.Error("UpdateEmployee() did not log validation warning")
 		}
 	})
+
+	t.Run("matching version", func(t *testing.T) {
+		repo := NewMockRepository()
+		logger := logging.NewMockLogger()
+		service := NewService(repo, NewMockDepartmentRepository(), logger)
+
+		deptID := uuid.New()
+		emp := &Employee{ID: uuid.New(), Name: "John Doe", CPF: "12345678909", DepartmentID: deptID, Version: 3}
+		repo.AddEmployee(emp)
+
+		updatedEmp := &Employee{Name: "John Updated", CPF: "12345678909", DepartmentID: deptID, Version: 3}
+
+		if err := service.UpdateEmployee(emp.ID, updatedEmp); err != nil {
+			t.Fatalf("UpdateEmployee() returned error: %v", err)
+		}
+		if updatedEmp.Version != 4 {
+			t.Errorf("UpdateEmployee() version = %d, want 4", updatedEmp.Version)
+		}
+	})
+
+	t.Run("stale version", func(t *testing.T) {
+		repo := NewMockRepository()
+		logger := logging.NewMockLogger()
+		service := NewService(repo, NewMockDepartmentRepository(), logger)
+
+		deptID := uuid.New()
+		emp := &Employee{ID: uuid.New(), Name: "John Doe", CPF: "12345678909", DepartmentID: deptID, Version: 3}
+		repo.AddEmployee(emp)
+
+		updatedEmp := &Employee{Name: "John Updated", CPF: "12345678909", DepartmentID: deptID, Version: 2}
+
+		err := service.UpdateEmployee(emp.ID, updatedEmp)
+
+		if !errors.Is(err, domainErrors.ErrPreconditionFailed) {
+			t.Errorf("UpdateEmployee() should return a precondition failed error, got %v", err)
+		}
+		if stored, _ := repo.FindByID(emp.ID); stored.Name != "John Doe" {
+			t.Error("UpdateEmployee() should not change the employee on a stale version")
+		}
+	})
+}
+
+func TestPatchEmployee(t *testing.T) {
+	setup := func() (*Service, *MockRepository, *Employee) {
+		repo := NewMockRepository()
+		service := NewService(repo, NewMockDepartmentRepository(), logging.NewMockLogger())
+
+		rg := "123456789"
+		emp := &Employee{ID: uuid.New(), Name: "John Doe", CPF: "12345678909", RG: &rg, DepartmentID: uuid.New(), Version: 1}
+		repo.AddEmployee(emp)
+		return service, repo, emp
+	}
+
+	t.Run("writes only the fields sent", func(t *testing.T) {
+		service, repo, emp := setup()
+		name := "John Updated"
+
+		patched, err := service.PatchEmployee(emp.ID, Patch{Name: &name})
+
+		if err != nil {
+			t.Fatalf("PatchEmployee() returned error: %v", err)
+		}
+		if patched.Name != name || patched.CPF != emp.CPF || patched.RG == nil {
+			t.Errorf("PatchEmployee() changed fields that were not sent: %+v", patched)
+		}
+		if len(repo.UpdatedFields) != 1 || repo.UpdatedFields[0] != "name" {
+			t.Errorf("PatchEmployee() wrote %v, expected [name]", repo.UpdatedFields)
+		}
+		if patched.Version != 2 {
+			t.Errorf("PatchEmployee() version = %d, want 2", patched.Version)
+		}
+	})
+
+	t.Run("null clears rg", func(t *testing.T) {
+		service, _, emp := setup()
+
+		patched, err := service.PatchEmployee(emp.ID, Patch{RGSet: true})
+
+		if err != nil {
+			t.Fatalf("PatchEmployee() returned error: %v", err)
+		}
+		if patched.RG != nil {
+			t.Errorf("PatchEmployee() should clear the RG, got %q", *patched.RG)
+		}
+	})
+
+	t.Run("invalid CPF", func(t *testing.T) {
+		service, repo, emp := setup()
+		cpf := "12345678900"
+
+		_, err := service.PatchEmployee(emp.ID, Patch{CPF: &cpf})
+
+		if !errors.Is(err, domainErrors.ErrInvalidCPF) {
+			t.Errorf("PatchEmployee() should return ErrInvalidCPF, got %v", err)
+		}
+		if repo.UpdatedFields != nil {
+			t.Error("PatchEmployee() should not write an invalid patch")
+		}
+	})
+
+	t.Run("punctuated CPF", func(t *testing.T) {
+		service, repo, emp := setup()
+		cpf := "111.444.777-35"
+
+		patched, err := service.PatchEmployee(emp.ID, Patch{CPF: &cpf})
+
+		if err != nil {
+			t.Fatalf("PatchEmployee() returned error: %v", err)
+		}
+		if patched.CPF != "11144477735" {
+			t.Errorf("PatchEmployee() stored CPF %q, want its digits", patched.CPF)
+		}
+		if len(repo.UpdatedFields) != 1 || repo.UpdatedFields[0] != "cpf" {
+			t.Errorf("PatchEmployee() wrote %v, expected [cpf]", repo.UpdatedFields)
+		}
+	})
+
+	t.Run("null on a required field", func(t *testing.T) {
+		service, _, emp := setup()
+		nilDepartment := uuid.Nil
+
+		_, err := service.PatchEmployee(emp.ID, Patch{DepartmentID: &nilDepartment})
+
+		var validationErr *domainErrors.ValidationError
+		if !errors.As(err, &validationErr) || validationErr.Field != "department_id" {
+			t.Errorf("PatchEmployee() should reject a null department, got %v", err)
+		}
+	})
+
+	t.Run("deleted department", func(t *testing.T) {
+		service, repo, emp := setup()
+		deleted := uuid.New()
+
+		_, err := service.PatchEmployee(emp.ID, Patch{DepartmentID: &deleted})
+
+		var validationErr *domainErrors.ValidationError
+		if !errors.As(err, &validationErr) || validationErr.Code != "employee.department_not_found" {
+			t.Errorf("PatchEmployee() should reject a deleted department, got %v", err)
+		}
+		if repo.UpdatedFields != nil {
+			t.Error("PatchEmployee() should not write the move")
+		}
+	})
+
+	t.Run("empty patch", func(t *testing.T) {
+		service, repo, emp := setup()
+
+		patched, err := service.PatchEmployee(emp.ID, Patch{})
+
+		if err != nil {
+			t.Fatalf("PatchEmployee() returned error: %v", err)
+		}
+		if repo.UpdatedFields != nil || patched.Version != 1 {
+			t.Error("PatchEmployee() should not write an empty patch")
+		}
+	})
+
+	t.Run("stale version", func(t *testing.T) {
+		service, _, emp := setup()
+		name := "John Updated"
+
+		_, err := service.PatchEmployee(emp.ID, Patch{Name: &name, Version: 5})
+
+		if !errors.Is(err, domainErrors.ErrPreconditionFailed) {
+			t.Errorf("PatchEmployee() should return a precondition failed error, got %v", err)
+		}
+	})
 }
 
 func TestDeleteEmployee(t *testing.T) {
 	t.Run("valid deletion", func(t *testing.T) {
 		repo := NewMockRepository()
 		logger := logging.NewMockLogger()
-		service := NewService(repo, logger)
+		service := NewService(repo, NewMockDepartmentRepository(), logger)
 
 		deptID := uuid.New()
 		emp := &Employee{ID: uuid.New(), Name: "John Doe", CPF: "12345678909", DepartmentID: deptID}
 		repo.AddEmployee(emp)
 
-		err := service.DeleteEmployee(emp.ID)
+		err := service.DeleteEmployee(emp.ID, 0)
 
 		if err != nil {
 			t.Errorf("DeleteEmployee() returned error: %v", err)
@@ -305,9 +538,9 @@ func TestDeleteEmployee(t *testing.T) {
 	t.Run("nil ID", func(t *testing.T) {
 		repo := NewMockRepository()
 		logger := logging.NewMockLogger()
-		service := NewService(repo, logger)
+		service := NewService(repo, NewMockDepartmentRepository(), logger)
 
-		err := service.DeleteEmployee(uuid.Nil)
+		err := service.DeleteEmployee(uuid.Nil, 0)
 
 		if err == nil {
 			t.Error("DeleteEmployee() should return error for nil ID")
@@ -317,13 +550,16 @@ func TestDeleteEmployee(t *testing.T) {
 	t.Run("non-existent employee", func(t *testing.T) {
 		repo := NewMockRepository()
 		logger := logging.NewMockLogger()
-		service := NewService(repo, logger)
+		service := NewService(repo, NewMockDepartmentRepository(), logger)
 
-		err := service.DeleteEmployee(uuid.New())
+		err := service.DeleteEmployee(uuid.New(), 0)
 
 		if err == nil {
 			t.Error("DeleteEmployee() should return error for non-existent employee")
 		}
+		if !errors.Is(err, domainErrors.ErrNotFound) {
+			t.Errorf("DeleteEmployee() should return a not found error, got %v", err)
+		}
 		if logger.CountByLevel("ERROR") == 0 {
 			t.Error("DeleteEmployee() did not log error")
 		}
@@ -332,14 +568,14 @@ func TestDeleteEmployee(t *testing.T) {
 	t.Run("repository error", func(t *testing.T) {
 		repo := NewMockRepository()
 		logger := logging.NewMockLogger()
-		service := NewService(repo, logger)
+		service := NewService(repo, NewMockDepartmentRepository(), logger)
 
 		deptID := uuid.New()
 		emp := &Employee{ID: uuid.New(), Name: "John Doe", CPF: "12345678909", DepartmentID: deptID}
 		repo.AddEmployee(emp)
 		repo.SetDeleteError(errors.New("database error"))
 
-		err := service.DeleteEmployee(emp.ID)
+		err := service.DeleteEmployee(emp.ID, 0)
 
 		if err == nil {
 			t.Error("DeleteEmployee() should return error when repository fails")
@@ -348,12 +584,46 @@ func TestDeleteEmployee(t *testing.T) {
 			t.Error("DeleteEmployee() did not log repository error")
 		}
 	})
+
+	t.Run("matching version", func(t *testing.T) {
+		repo := NewMockRepository()
+		logger := logging.NewMockLogger()
+		service := NewService(repo, NewMockDepartmentRepository(), logger)
+
+		emp := &Employee{ID: uuid.New(), Name: "John Doe", CPF: "12345678909", DepartmentID: uuid.New(), Version: 2}
+		repo.AddEmployee(emp)
+
+		if err := service.DeleteEmployee(emp.ID, 2); err != nil {
+			t.Errorf("DeleteEmployee() returned error: %v", err)
+		}
+		if repo.TransactionCalls != 1 {
+			t.Errorf("DeleteEmployee() should run in a transaction, got %d", repo.TransactionCalls)
+		}
+	})
+
+	t.Run("stale version", func(t *testing.T) {
+		repo := NewMockRepository()
+		logger := logging.NewMockLogger()
+		service := NewService(repo, NewMockDepartmentRepository(), logger)
+
+		emp := &Employee{ID: uuid.New(), Name: "John Doe", CPF: "12345678909", DepartmentID: uuid.New(), Version: 2}
+		repo.AddEmployee(emp)
+
+		err := service.DeleteEmployee(emp.ID, 1)
+
+		if !errors.Is(err, domainErrors.ErrPreconditionFailed) {
+			t.Errorf("DeleteEmployee() should return a precondition failed error, got %v", err)
+		}
+		if _, err := repo.FindByID(emp.ID); err != nil {
+			t.Error("DeleteEmployee() should keep the employee on a stale version")
+		}
+	})
 }
 
 func TestGetEmployeeWithManager(t *testing.T) {
 	repo := NewMockRepository()
 	logger := logging.NewMockLogger()
-	service := NewService(repo, logger)
+	service := NewService(repo, NewMockDepartmentRepository(), logger)
 
 	deptID := uuid.New()
 	emp := &Employee{ID: uuid.New(), Name: "John Doe", CPF: "12345678909", DepartmentID: deptID}
@@ -380,7 +650,7 @@ func TestGetEmployeeWithManager(t *testing.T) {
 func TestGetEmployeesByDepartmentIDs(t *testing.T) {
 	repo := NewMockRepository()
 	logger := logging.NewMockLogger()
-	service := NewService(repo, logger)
+	service := NewService(repo, NewMockDepartmentRepository(), logger)
 
 	deptID1 := uuid.New()
 	deptID2 := uuid.New()
@@ -406,7 +676,7 @@ func TestGetEmployeesByDepartmentIDs(t *testing.T) {
 func TestListEmployees(t *testing.T) {
 	repo := NewMockRepository()
 	logger := logging.NewMockLogger()
-	service := NewService(repo, logger)
+	service := NewService(repo, NewMockDepartmentRepository(), logger)
 
 	deptID := uuid.New()
 	emp1 := &Employee{ID: uuid.New(), Name: "John Doe", CPF: "12345678909", DepartmentID: deptID}
@@ -432,3 +702,981 @@ func TestListEmployees(t *testing.T) {
 		t.Errorf("ListEmployees() returned total %d, expected 1", total)
 	}
 }
+
+func TestListEmployeesPage(t *testing.T) {
+	repo := NewMockRepository()
+	service := NewService(repo, NewMockDepartmentRepository(), logging.NewMockLogger())
+
+	// UUIDv7 IDs sort in creation order
+	var ids []uuid.UUID
+	for _, name := range []string{"Eva", "Caio", "Ana", "Duda", "Bia"} {
+		id, _ := uuid.NewV7()
+		ids = append(ids, id)
+		repo.AddEmployee(&Employee{ID: id, Name: name, CPF: "12345678909", DepartmentID: uuid.New()})
+	}
+
+	t.Run("creation order", func(t *testing.T) {
+		first, info, err := service.ListEmployeesPage(ListFilters{}, pagination.Keyset{Limit: 2, WithTotal: true})
+		if err != nil {
+			t.Fatalf("ListEmployeesPage() returned error: %v", err)
+		}
+		if len(first) != 2 || first[0].ID != ids[0] || first[1].ID != ids[1] {
+			t.Errorf("first page = %v, want the two oldest employees", first)
+		}
+		if info.Next == nil || info.Next.ID != ids[1] || info.Prev != nil {
+			t.Errorf("first page info = %+v, want only a next page", info)
+		}
+		if info.Total == nil || *info.Total != 5 {
+			t.Errorf("first page total = %v, want 5", info.Total)
+		}
+
+		last, info, _ := service.ListEmployeesPage(ListFilters{}, pagination.Keyset{Cursor: &pagination.Cursor{ID: ids[3]}, Limit: 2})
+		if len(last) != 1 || last[0].ID != ids[4] || info.Next != nil || info.Prev == nil {
+			t.Errorf("page after the fourth = %v (%+v), want only the last employee", last, info)
+		}
+		if info.Total != nil {
+			t.Error("ListEmployeesPage() counted the employees without WithTotal")
+		}
+
+		back, info, _ := service.ListEmployeesPage(ListFilters{}, pagination.Keyset{Cursor: &pagination.Cursor{ID: ids[4]}, Backward: true, Limit: 2})
+		if len(back) != 2 || back[0].ID != ids[2] || back[1].ID != ids[3] || info.Next == nil || info.Prev == nil {
+			t.Errorf("page before the last = %v (%+v), want the third and fourth employees", back, info)
+		}
+	})
+
+	t.Run("sorted by name descending", func(t *testing.T) {
+		filters := ListFilters{Sort: []pagination.SortField{{Field: "name", Desc: true}}}
+
+		first, info, _ := service.ListEmployeesPage(filters, pagination.Keyset{Limit: 2})
+		if len(first) != 2 || first[0].Name != "Eva" || first[1].Name != "Duda" {
+			t.Errorf("first page = %v, want Eva and Duda", first)
+		}
+		if info.Next == nil || len(info.Next.Values) != 1 || info.Next.Values[0] != "Duda" {
+			t.Fatalf("next cursor = %+v, want the values of Duda", info.Next)
+		}
+
+		second, _, _ := service.ListEmployeesPage(filters, pagination.Keyset{Cursor: info.Next, Limit: 2})
+		if len(second) != 2 || second[0].Name != "Caio" || second[1].Name != "Bia" {
+			t.Errorf("second page = %v, want Caio and Bia", second)
+		}
+	})
+}
+
+func TestGetReportingChain(t *testing.T) {
+	repo := NewMockRepository()
+	logger := logging.NewMockLogger()
+	service := NewService(repo, NewMockDepartmentRepository(), logger)
+
+	devDept := uuid.New()
+	tiDept := uuid.New()
+	dev := &Employee{ID: uuid.New(), Name: "Developer", CPF: "11144477735", DepartmentID: devDept}
+	devManager := &Employee{ID: uuid.New(), Name: "Dev Manager", CPF: "12345678909", DepartmentID: devDept}
+	cto := &Employee{ID: uuid.New(), Name: "CTO", CPF: "52998224725", DepartmentID: tiDept}
+	for _, e := range []*Employee{dev, devManager, cto} {
+		repo.AddEmployee(e)
+	}
+
+	levels := func(managers ...*Employee) []ReportingChainEntry {
+		result := make([]ReportingChainEntry, len(managers))
+		for i, m := range managers {
+			result[i] = ReportingChainEntry{Employee: *m, Level: i}
+		}
+		return result
+	}
+
+	t.Run("regular employee", func(t *testing.T) {
+		repo.SetDepartmentManagers(dev.ID, levels(devManager, cto))
+
+		chain, err := service.GetReportingChain(dev.ID)
+		if err != nil {
+			t.Fatalf("GetReportingChain() returned error: %v", err)
+		}
+		if len(chain) != 2 || chain[0].ID != devManager.ID || chain[1].ID != cto.ID {
+			t.Fatalf("GetReportingChain() returned wrong chain: %+v", chain)
+		}
+		if chain[0].Level != 1 || chain[1].Level != 2 {
+			t.Error("GetReportingChain() returned wrong levels")
+		}
+	})
+
+	t.Run("employee manages own department", func(t *testing.T) {
+		repo.SetDepartmentManagers(devManager.ID, levels(devManager, cto))
+
+		chain, err := service.GetReportingChain(devManager.ID)
+		if err != nil {
+			t.Fatalf("GetReportingChain() returned error: %v", err)
+		}
+		if len(chain) != 1 || chain[0].ID != cto.ID || chain[0].Level != 1 {
+			t.Errorf("GetReportingChain() should report to the parent department manager, got %+v", chain)
+		}
+	})
+
+	t.Run("same manager on consecutive levels", func(t *testing.T) {
+		repo.SetDepartmentManagers(dev.ID, levels(cto, cto))
+
+		chain, _ := service.GetReportingChain(dev.ID)
+		if len(chain) != 1 {
+			t.Errorf("GetReportingChain() returned %d entries, expected 1", len(chain))
+		}
+	})
+
+	t.Run("top of the tree", func(t *testing.T) {
+		repo.SetDepartmentManagers(cto.ID, levels(cto))
+
+		chain, err := service.GetReportingChain(cto.ID)
+		if err != nil {
+			t.Fatalf("GetReportingChain() returned error: %v", err)
+		}
+		if len(chain) != 0 {
+			t.Errorf("GetReportingChain() returned %d entries, expected 0", len(chain))
+		}
+	})
+
+	t.Run("non-existent ID", func(t *testing.T) {
+		_, err := service.GetReportingChain(uuid.New())
+		if err == nil {
+			t.Error("GetReportingChain() should return error for non-existent ID")
+		}
+	})
+
+	t.Run("nil ID", func(t *testing.T) {
+		_, err := service.GetReportingChain(uuid.Nil)
+		if err == nil {
+			t.Error("GetReportingChain() should return error for nil ID")
+		}
+	})
+}
+
+func TestListSubordinates(t *testing.T) {
+	repo := NewMockRepository()
+	logger := logging.NewMockLogger()
+	service := NewService(repo, NewMockDepartmentRepository(), logger)
+
+	deptID := uuid.New()
+	manager := &Employee{ID: uuid.New(), Name: "Manager", CPF: "12345678909", DepartmentID: deptID}
+	repo.AddEmployee(manager)
+	repo.SetSubordinates(manager.ID, []Subordinate{
+		{Employee: Employee{ID: uuid.New(), Name: "Direct", DepartmentID: deptID}, Direct: true},
+		{Employee: Employee{ID: uuid.New(), Name: "Indirect 1", DepartmentID: uuid.New()}},
+		{Employee: Employee{ID: uuid.New(), Name: "Indirect 2", DepartmentID: uuid.New()}},
+	})
+
+	t.Run("all subordinates paginated", func(t *testing.T) {
+		subordinates, total, err := service.ListSubordinates(manager.ID, SubordinateFilters{Page: 1, PageSize: 2})
+		if err != nil {
+			t.Fatalf("ListSubordinates() returned error: %v", err)
+		}
+		if len(subordinates) != 2 {
+			t.Errorf("ListSubordinates() returned %d subordinates, expected 2", len(subordinates))
+		}
+		if total != 3 {
+			t.Errorf("ListSubordinates() returned total %d, expected 3", total)
+		}
+	})
+
+	t.Run("direct only", func(t *testing.T) {
+		subordinates, total, err := service.ListSubordinates(manager.ID, SubordinateFilters{DirectOnly: true, Page: 1, PageSize: 10})
+		if err != nil {
+			t.Fatalf("ListSubordinates() returned error: %v", err)
+		}
+		if total != 1 || !subordinates[0].Direct {
+			t.Error("ListSubordinates() should only return direct reports")
+		}
+	})
+
+	t.Run("non-existent manager", func(t *testing.T) {
+		_, _, err := service.ListSubordinates(uuid.New(), SubordinateFilters{Page: 1, PageSize: 10})
+		if err == nil {
+			t.Error("ListSubordinates() should return error for non-existent manager")
+		}
+	})
+
+	t.Run("nil ID", func(t *testing.T) {
+		_, _, err := service.ListSubordinates(uuid.Nil, SubordinateFilters{Page: 1, PageSize: 10})
+		if err == nil {
+			t.Error("ListSubordinates() should return error for nil ID")
+		}
+	})
+}
+
+func TestRestoreEmployee(t *testing.T) {
+	setup := func() (*Service, *MockRepository, *MockDepartmentRepository, *Employee) {
+		repo := NewMockRepository()
+		deptRepo := NewMockDepartmentRepository()
+		service := NewService(repo, deptRepo, logging.NewMockLogger())
+
+		dept := &Department{ID: uuid.New(), Name: "Engineering"}
+		deptRepo.AddDepartment(dept)
+
+		emp := &Employee{ID: uuid.New(), Name: "John Doe", CPF: "12345678909", DepartmentID: dept.ID}
+		repo.AddDeletedEmployee(emp)
+		return service, repo, deptRepo, emp
+	}
+
+	t.Run("valid restore", func(t *testing.T) {
+		service, repo, _, emp := setup()
+
+		restored, err := service.RestoreEmployee(emp.ID)
+		if err != nil {
+			t.Fatalf("RestoreEmployee() returned error: %v", err)
+		}
+		if restored.DeletedAt.Valid {
+			t.Error("RestoreEmployee() should clear DeletedAt")
+		}
+		if _, err := repo.FindByID(emp.ID); err != nil {
+			t.Error("RestoreEmployee() did not make the employee active again")
+		}
+	})
+
+	t.Run("CPF taken while deleted", func(t *testing.T) {
+		service, repo, _, emp := setup()
+		repo.AddEmployee(&Employee{ID: uuid.New(), Name: "Other", CPF: emp.CPF, DepartmentID: emp.DepartmentID})
+
+		_, err := service.RestoreEmployee(emp.ID)
+
+		var conflict *domainErrors.ConflictError
+		if !errors.As(err, &conflict) || conflict.Field != "cpf" {
+			t.Errorf("RestoreEmployee() should return a CPF conflict, got %v", err)
+		}
+	})
+
+	t.Run("department no longer exists", func(t *testing.T) {
+		service, _, deptRepo, emp := setup()
+		deptRepo.Reset()
+
+		_, err := service.RestoreEmployee(emp.ID)
+
+		var validation *domainErrors.ValidationError
+		if !errors.As(err, &validation) || validation.Field != "department_id" {
+			t.Errorf("RestoreEmployee() should return a department validation error, got %v", err)
+		}
+	})
+
+	t.Run("employee not deleted", func(t *testing.T) {
+		service, repo, _, emp := setup()
+		active := &Employee{ID: uuid.New(), Name: "Active", CPF: "11144477735", DepartmentID: emp.DepartmentID}
+		repo.AddEmployee(active)
+
+		_, err := service.RestoreEmployee(active.ID)
+
+		var notFound *domainErrors.NotFoundError
+		if !errors.As(err, &notFound) {
+			t.Errorf("RestoreEmployee() should return not found for an active employee, got %v", err)
+		}
+	})
+}
+
+func TestPurgeEmployee(t *testing.T) {
+	t.Run("valid purge", func(t *testing.T) {
+		repo := NewMockRepository()
+		service := NewService(repo, NewMockDepartmentRepository(), logging.NewMockLogger())
+
+		emp := &Employee{ID: uuid.New(), Name: "John Doe", CPF: "12345678909", DepartmentID: uuid.New()}
+		repo.AddDeletedEmployee(emp)
+
+		if err := service.PurgeEmployee(emp.ID); err != nil {
+			t.Fatalf("PurgeEmployee() returned error: %v", err)
+		}
+		if _, err := repo.FindDeletedByID(emp.ID); err == nil {
+			t.Error("PurgeEmployee() did not remove the employee")
+		}
+	})
+
+	t.Run("active employee", func(t *testing.T) {
+		repo := NewMockRepository()
+		service := NewService(repo, NewMockDepartmentRepository(), logging.NewMockLogger())
+
+		emp := &Employee{ID: uuid.New(), Name: "John Doe", CPF: "12345678909", DepartmentID: uuid.New()}
+		repo.AddEmployee(emp)
+
+		err := service.PurgeEmployee(emp.ID)
+
+		var notFound *domainErrors.NotFoundError
+		if !errors.As(err, &notFound) {
+			t.Errorf("PurgeEmployee() should refuse active employees, got %v", err)
+		}
+	})
+
+	t.Run("still manages a department", func(t *testing.T) {
+		repo := NewMockRepository()
+		deptRepo := NewMockDepartmentRepository()
+		service := NewService(repo, deptRepo, logging.NewMockLogger())
+
+		emp := &Employee{ID: uuid.New(), Name: "John Doe", CPF: "12345678909", DepartmentID: uuid.New()}
+		repo.AddDeletedEmployee(emp)
+		deptRepo.AddDepartment(&Department{ID: emp.DepartmentID, Name: "Engineering", ManagerID: emp.ID})
+
+		err := service.PurgeEmployee(emp.ID)
+
+		var conflict *domainErrors.ConflictError
+		if !errors.As(err, &conflict) {
+			t.Errorf("PurgeEmployee() should refuse managers, got %v", err)
+		}
+	})
+
+	t.Run("still manages a deleted department", func(t *testing.T) {
+		repo := NewMockRepository()
+		deptRepo := NewMockDepartmentRepository()
+		service := NewService(repo, deptRepo, logging.NewMockLogger())
+
+		emp := &Employee{ID: uuid.New(), Name: "John Doe", CPF: "12345678909", DepartmentID: uuid.New()}
+		repo.AddDeletedEmployee(emp)
+		deptRepo.AddDeletedDepartment(&Department{ID: emp.DepartmentID, Name: "Engineering", ManagerID: emp.ID})
+
+		err := service.PurgeEmployee(emp.ID)
+
+		var conflict *domainErrors.ConflictError
+		if !errors.As(err, &conflict) {
+			t.Errorf("PurgeEmployee() should refuse managers of deleted departments, got %v", err)
+		}
+	})
+}
+
+func TestCreateEmployeeWithPolicy(t *testing.T) {
+	setup := func() (*Service, *MockRepository, *Employee, uuid.UUID) {
+		repo := NewMockRepository()
+		deptRepo := NewMockDepartmentRepository()
+		service := NewService(repo, deptRepo, logging.NewMockLogger())
+
+		newDept := &Department{ID: uuid.New(), Name: "Sales"}
+		deptRepo.AddDepartment(newDept)
+
+		previous := &Employee{ID: uuid.New(), Name: "John Doe", CPF: "12345678909", DepartmentID: uuid.New()}
+		repo.AddDeletedEmployee(previous)
+		return service, repo, previous, newDept.ID
+	}
+
+	t.Run("offer reports the previous record", func(t *testing.T) {
+		service, _, previous, deptID := setup()
+		emp := &Employee{Name: "John Doe", CPF: previous.CPF, DepartmentID: deptID}
+
+		restored, err := service.CreateEmployeeWithPolicy(emp, RehireOffer)
+
+		var previousErr *PreviousRecordError
+		if !errors.As(err, &previousErr) {
+			t.Fatalf("CreateEmployeeWithPolicy() should return PreviousRecordError, got %v", err)
+		}
+		if previousErr.PreviousID != previous.ID {
+			t.Errorf("PreviousRecordError.PreviousID = %v, expected %v", previousErr.PreviousID, previous.ID)
+		}
+		if restored {
+			t.Error("CreateEmployeeWithPolicy() should not report a restore")
+		}
+	})
+
+	t.Run("CreateEmployee defaults to offer", func(t *testing.T) {
+		service, _, previous, deptID := setup()
+
+		err := service.CreateEmployee(&Employee{Name: "John Doe", CPF: previous.CPF, DepartmentID: deptID})
+
+		var previousErr *PreviousRecordError
+		if !errors.As(err, &previousErr) {
+			t.Errorf("CreateEmployee() should return PreviousRecordError, got %v", err)
+		}
+	})
+
+	t.Run("restore reuses the previous record", func(t *testing.T) {
+		service, repo, previous, deptID := setup()
+		emp := &Employee{Name: "John Rehired", CPF: previous.CPF, DepartmentID: deptID}
+
+		restored, err := service.CreateEmployeeWithPolicy(emp, RehireRestore)
+		if err != nil {
+			t.Fatalf("CreateEmployeeWithPolicy() returned error: %v", err)
+		}
+		if !restored || emp.ID != previous.ID {
+			t.Errorf("CreateEmployeeWithPolicy() should restore %v, got %v", previous.ID, emp.ID)
+		}
+		stored, err := repo.FindByID(previous.ID)
+		if err != nil {
+			t.Fatal("CreateEmployeeWithPolicy() did not make the previous record active")
+		}
+		if stored.Name != "John Rehired" || stored.DepartmentID != deptID {
+			t.Error("CreateEmployeeWithPolicy() did not apply the new data to the previous record")
+		}
+		if repo.TransactionCalls != 1 {
+			t.Errorf("CreateEmployeeWithPolicy() used %d transactions, expected 1", repo.TransactionCalls)
+		}
+	})
+
+	t.Run("ignore creates a new record", func(t *testing.T) {
+		service, repo, previous, deptID := setup()
+		emp := &Employee{Name: "John Doe", CPF: previous.CPF, DepartmentID: deptID}
+
+		restored, err := service.CreateEmployeeWithPolicy(emp, RehireIgnore)
+		if err != nil {
+			t.Fatalf("CreateEmployeeWithPolicy() returned error: %v", err)
+		}
+		if restored || emp.ID == previous.ID {
+			t.Error("CreateEmployeeWithPolicy() should create a new record")
+		}
+		if _, err := repo.FindDeletedByID(previous.ID); err != nil {
+			t.Error("CreateEmployeeWithPolicy() should leave the previous record deleted")
+		}
+	})
+
+	t.Run("no previous record", func(t *testing.T) {
+		service, _, _, deptID := setup()
+		emp := &Employee{Name: "Jane Doe", CPF: "11144477735", DepartmentID: deptID}
+
+		restored, err := service.CreateEmployeeWithPolicy(emp, RehireOffer)
+		if err != nil || restored {
+			t.Errorf("CreateEmployeeWithPolicy() = (%v, %v), expected a plain create", restored, err)
+		}
+	})
+}
+
+func TestImportEmployees(t *testing.T) {
+	setup := func() (*Service, *MockRepository, *MockDepartmentRepository) {
+		repo := NewMockRepository()
+		deptRepo := NewMockDepartmentRepository()
+		deptRepo.AddDepartment(&Department{ID: uuid.New(), Name: "Tecnologia"})
+		return NewService(repo, deptRepo, logging.NewMockLogger()), repo, deptRepo
+	}
+	codes := func(result ImportRowResult) []string {
+		var codes []string
+		for _, err := range result.Errors {
+			var validation *domainErrors.ValidationError
+			var conflict *domainErrors.ConflictError
+			switch {
+			case errors.As(err, &validation):
+				codes = append(codes, validation.Code)
+			case errors.As(err, &conflict):
+				codes = append(codes, conflict.Code)
+			}
+		}
+		return codes
+	}
+
+	t.Run("creates every row in one transaction", func(t *testing.T) {
+		service, repo, deptRepo := setup()
+		financas := &Department{ID: uuid.New(), Name: "Finanças"}
+		deptRepo.AddDepartment(financas)
+
+		report, err := service.ImportEmployees([]ImportRow{
+			{Line: 2, Name: "Ana", CPF: "11144477735", Department: "tecnologia"},
+			{Line: 3, Name: "Bruno", CPF: "52998224725", Department: financas.ID.String()},
+		}, ImportOptions{})
+
+		if err != nil {
+			t.Fatalf("ImportEmployees() returned error: %v", err)
+		}
+		if report.Created != 2 || report.Invalid != 0 {
+			t.Errorf("ImportEmployees() created %d and rejected %d rows, expected 2 and 0", report.Created, report.Invalid)
+		}
+		if repo.TransactionCalls != 1 {
+			t.Errorf("ImportEmployees() used %d transactions, expected 1", repo.TransactionCalls)
+		}
+		if report.Rows[1].Employee.DepartmentID != financas.ID {
+			t.Error("ImportEmployees() did not resolve the department ID")
+		}
+	})
+
+	t.Run("one invalid row rejects the whole file", func(t *testing.T) {
+		service, repo, _ := setup()
+
+		report, err := service.ImportEmployees([]ImportRow{
+			{Line: 2, Name: "Ana", CPF: "11144477735", Department: "Tecnologia"},
+			{Line: 3, Name: "", CPF: "12345678900", Department: "Marketing"},
+		}, ImportOptions{})
+
+		if err != nil {
+			t.Fatalf("ImportEmployees() returned error: %v", err)
+		}
+		if report.Created != 0 || repo.TransactionCalls != 0 {
+			t.Error("ImportEmployees() should not write when a row is invalid")
+		}
+		got := codes(report.Rows[1])
+		want := []string{"employee.name_required", "cpf.invalid", "import.department_not_found"}
+		if len(got) != len(want) {
+			t.Fatalf("row errors = %v, want %v", got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("row errors = %v, want %v", got, want)
+			}
+		}
+	})
+
+	t.Run("partial import creates the valid rows", func(t *testing.T) {
+		service, repo, _ := setup()
+
+		report, err := service.ImportEmployees([]ImportRow{
+			{Line: 2, Name: "Ana", CPF: "11144477735", Department: "Tecnologia"},
+			{Line: 3, Name: "Bruno", CPF: "12345678900", Department: "Tecnologia"},
+		}, ImportOptions{Partial: true})
+
+		if err != nil {
+			t.Fatalf("ImportEmployees() returned error: %v", err)
+		}
+		if report.Created != 1 || !report.Rows[0].Created || report.Rows[1].Created {
+			t.Errorf("ImportEmployees() created %d rows, expected only the first one", report.Created)
+		}
+		if employees, _ := repo.FindAll(); len(employees) != 1 {
+			t.Errorf("repository holds %d employees, expected 1", len(employees))
+		}
+	})
+
+	t.Run("dry run only validates", func(t *testing.T) {
+		service, repo, _ := setup()
+
+		report, err := service.ImportEmployees([]ImportRow{
+			{Line: 2, Name: "Ana", CPF: "11144477735", Department: "Tecnologia"},
+		}, ImportOptions{DryRun: true})
+
+		if err != nil {
+			t.Fatalf("ImportEmployees() returned error: %v", err)
+		}
+		if report.Valid != 1 || report.Created != 0 {
+			t.Errorf("ImportEmployees() valid=%d created=%d, expected 1 and 0", report.Valid, report.Created)
+		}
+		if employees, _ := repo.FindAll(); len(employees) != 0 {
+			t.Error("a dry run should not write")
+		}
+	})
+
+	t.Run("duplicates in the file and in the database", func(t *testing.T) {
+		service, repo, _ := setup()
+		repo.AddEmployee(&Employee{ID: uuid.New(), Name: "Existing", CPF: "98765432100", DepartmentID: uuid.New()})
+		rg := "123456789"
+
+		report, err := service.ImportEmployees([]ImportRow{
+			{Line: 2, Name: "Ana", CPF: "11144477735", RG: &rg, Department: "Tecnologia"},
+			{Line: 3, Name: "Ana", CPF: "11144477735", RG: &rg, Department: "Tecnologia"},
+			{Line: 4, Name: "Carla", CPF: "98765432100", Department: "Tecnologia"},
+		}, ImportOptions{DryRun: true})
+
+		if err != nil {
+			t.Fatalf("ImportEmployees() returned error: %v", err)
+		}
+		if got := codes(report.Rows[1]); len(got) != 2 || got[0] != "import.cpf_repeated" || got[1] != "import.rg_repeated" {
+			t.Errorf("second row errors = %v, expected the repeated CPF and RG", got)
+		}
+		if got := codes(report.Rows[2]); len(got) != 1 || got[0] != "cpf.duplicate" {
+			t.Errorf("third row errors = %v, expected cpf.duplicate", got)
+		}
+		if report.Valid != 1 {
+			t.Errorf("ImportEmployees() valid = %d, expected 1", report.Valid)
+		}
+	})
+
+	t.Run("same RG with and without punctuation", func(t *testing.T) {
+		service, _, _ := setup()
+		formatted, digits := "12.345.678-9", "123456789"
+
+		report, err := service.ImportEmployees([]ImportRow{
+			{Line: 2, Name: "Ana", CPF: "11144477735", RG: &formatted, Department: "Tecnologia"},
+			{Line: 3, Name: "Bruno", CPF: "111.444.777-35", RG: &digits, Department: "Tecnologia"},
+		}, ImportOptions{DryRun: true})
+
+		if err != nil {
+			t.Fatalf("ImportEmployees() returned error: %v", err)
+		}
+		if got := codes(report.Rows[1]); len(got) != 2 || got[0] != "import.cpf_repeated" || got[1] != "import.rg_repeated" {
+			t.Errorf("second row errors = %v, expected the repeated CPF and RG", got)
+		}
+	})
+
+	t.Run("ambiguous department name", func(t *testing.T) {
+		service, _, deptRepo := setup()
+		deptRepo.AddDepartment(&Department{ID: uuid.New(), Name: "TECNOLOGIA"})
+
+		report, err := service.ImportEmployees([]ImportRow{
+			{Line: 2, Name: "Ana", CPF: "11144477735", Department: "Tecnologia"},
+		}, ImportOptions{DryRun: true})
+
+		if err != nil {
+			t.Fatalf("ImportEmployees() returned error: %v", err)
+		}
+		if got := codes(report.Rows[0]); len(got) != 1 || got[0] != "import.department_ambiguous" {
+			t.Errorf("row errors = %v, expected import.department_ambiguous", got)
+		}
+	})
+}
+
+// headcountRecorder is a HeadcountObserver remembering the departments it was told about
+type headcountRecorder struct {
+	changed  []uuid.UUID
+	managers []uuid.UUID
+}
+
+func (r *headcountRecorder) HeadcountChanged(departmentIDs ...uuid.UUID) {
+	r.changed = append(r.changed, departmentIDs...)
+}
+
+func (r *headcountRecorder) ManagerChanged(employeeID uuid.UUID) {
+	r.managers = append(r.managers, employeeID)
+}
+
+func TestHeadcountObserver(t *testing.T) {
+	setup := func(departments ...uuid.UUID) (*Service, *MockRepository, *headcountRecorder) {
+		repo := NewMockRepository()
+		deptRepo := NewMockDepartmentRepository()
+		for _, id := range departments {
+			deptRepo.AddDepartment(&Department{ID: id})
+		}
+		service := NewService(repo, deptRepo, logging.NewMockLogger())
+		recorder := &headcountRecorder{}
+		service.SetHeadcountObserver(recorder)
+		return service, repo, recorder
+	}
+	want := func(t *testing.T, recorder *headcountRecorder, ids ...uuid.UUID) {
+		t.Helper()
+		if len(recorder.changed) != len(ids) {
+			t.Fatalf("HeadcountChanged() got %v, want %v", recorder.changed, ids)
+		}
+		for i, id := range ids {
+			if recorder.changed[i] != id {
+				t.Errorf("HeadcountChanged() got %v, want %v", recorder.changed, ids)
+			}
+		}
+	}
+
+	t.Run("create", func(t *testing.T) {
+		service, _, recorder := setup()
+		emp := &Employee{Name: "John Doe", CPF: "12345678909", DepartmentID: uuid.New()}
+		if err := service.CreateEmployee(emp); err != nil {
+			t.Fatalf("CreateEmployee() returned error: %v", err)
+		}
+		want(t, recorder, emp.DepartmentID)
+	})
+
+	t.Run("move between departments", func(t *testing.T) {
+		from, to := uuid.New(), uuid.New()
+		service, repo, recorder := setup(to)
+		emp := &Employee{ID: uuid.New(), Name: "John Doe", CPF: "12345678909", DepartmentID: from}
+		repo.AddEmployee(emp)
+
+		if _, err := service.PatchEmployee(emp.ID, Patch{DepartmentID: &to}); err != nil {
+			t.Fatalf("PatchEmployee() returned error: %v", err)
+		}
+		want(t, recorder, from, to)
+	})
+
+	t.Run("update keeping the department", func(t *testing.T) {
+		service, repo, recorder := setup()
+		emp := &Employee{ID: uuid.New(), Name: "John Doe", CPF: "12345678909", DepartmentID: uuid.New()}
+		repo.AddEmployee(emp)
+
+		update := &Employee{Name: "John Smith", CPF: "12345678909", DepartmentID: emp.DepartmentID}
+		if err := service.UpdateEmployee(emp.ID, update); err != nil {
+			t.Fatalf("UpdateEmployee() returned error: %v", err)
+		}
+		want(t, recorder)
+		if len(recorder.managers) != 1 || recorder.managers[0] != emp.ID {
+			t.Errorf("ManagerChanged() got %v, want the renamed employee", recorder.managers)
+		}
+	})
+
+	t.Run("patch without a rename", func(t *testing.T) {
+		service, repo, recorder := setup()
+		emp := &Employee{ID: uuid.New(), Name: "John Doe", CPF: "12345678909", DepartmentID: uuid.New()}
+		repo.AddEmployee(emp)
+
+		rg := "123456789"
+		if _, err := service.PatchEmployee(emp.ID, Patch{RG: &rg, RGSet: true}); err != nil {
+			t.Fatalf("PatchEmployee() returned error: %v", err)
+		}
+		if len(recorder.managers) != 0 {
+			t.Errorf("ManagerChanged() got %v, want nothing", recorder.managers)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		service, repo, recorder := setup()
+		emp := &Employee{ID: uuid.New(), Name: "John Doe", CPF: "12345678909", DepartmentID: uuid.New()}
+		repo.AddEmployee(emp)
+
+		if err := service.DeleteEmployee(emp.ID, 0); err != nil {
+			t.Fatalf("DeleteEmployee() returned error: %v", err)
+		}
+		want(t, recorder, emp.DepartmentID)
+		if len(recorder.managers) != 1 || recorder.managers[0] != emp.ID {
+			t.Errorf("ManagerChanged() got %v, want the deleted employee", recorder.managers)
+		}
+	})
+}
+
+func TestAuditHistory(t *testing.T) {
+	repo := NewMockRepository()
+	deptRepo := NewMockDepartmentRepository()
+	service := NewService(repo, deptRepo, logging.NewMockLogger())
+
+	emp := &Employee{Name: "John Doe", CPF: "12345678909", DepartmentID: uuid.New()}
+	if err := service.CreateEmployee(emp); err != nil {
+		t.Fatalf("CreateEmployee() returned error: %v", err)
+	}
+	to := uuid.New()
+	deptRepo.AddDepartment(&Department{ID: to})
+	if _, err := service.PatchEmployee(emp.ID, Patch{DepartmentID: &to}); err != nil {
+		t.Fatalf("PatchEmployee() returned error: %v", err)
+	}
+	update := &Employee{Name: "John Smith", CPF: "12345678909", DepartmentID: to}
+	if err := service.UpdateEmployee(emp.ID, update); err != nil {
+		t.Fatalf("UpdateEmployee() returned error: %v", err)
+	}
+	if err := service.DeleteEmployee(emp.ID, 0); err != nil {
+		t.Fatalf("DeleteEmployee() returned error: %v", err)
+	}
+
+	events, _ := repo.FindEvents(emp.ID)
+	want := []struct {
+		action AuditAction
+		fields []string
+	}{
+		{AuditCreated, nil},
+		{AuditUpdated, []string{"department_id"}},
+		{AuditUpdated, []string{"name"}},
+		{AuditDeleted, nil},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("history has %d events, want %d: %+v", len(events), len(want), events)
+	}
+	for i, w := range want {
+		if events[i].Action != w.action || !slices.Equal(events[i].Fields, w.fields) {
+			t.Errorf("event %d = %s %v, want %s %v", i, events[i].Action, events[i].Fields, w.action, w.fields)
+		}
+	}
+	if events[1].DepartmentID != to {
+		t.Error("history does not record the department the employee moved to")
+	}
+
+	t.Run("change fails with its history", func(t *testing.T) {
+		repo.SetRecordEventError(errors.New("database error"))
+		defer repo.SetRecordEventError(nil)
+
+		calls := repo.TransactionCalls
+		other := &Employee{Name: "Jane Doe", CPF: "52998224725", DepartmentID: to}
+		if err := service.CreateEmployee(other); err == nil {
+			t.Error("CreateEmployee() succeeded without recording its history")
+		}
+		if repo.TransactionCalls != calls+1 {
+			t.Error("the change and its history were not written in one transaction")
+		}
+	})
+}
+
+func TestMemberships(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	day := func(n int) time.Time { return created.AddDate(0, 0, n) }
+	sales, tech := uuid.New(), uuid.New()
+
+	t.Run("no history", func(t *testing.T) {
+		emp := &Employee{DepartmentID: sales, CreatedAt: created}
+
+		got := memberships(emp, nil)
+
+		if len(got) != 1 || got[0].DepartmentID != sales || !got[0].From.Equal(created) || got[0].To != nil {
+			t.Errorf("memberships() = %+v, want the current department since creation", got)
+		}
+	})
+
+	t.Run("moves, deletion and restore", func(t *testing.T) {
+		emp := &Employee{DepartmentID: tech, CreatedAt: created}
+		history := []AuditEvent{
+			{Action: AuditCreated, DepartmentID: sales, CreatedAt: created},
+			{Action: AuditUpdated, Fields: []string{"name"}, DepartmentID: sales, CreatedAt: day(5)},
+			{Action: AuditUpdated, Fields: []string{"department_id"}, DepartmentID: tech, CreatedAt: day(10)},
+			{Action: AuditDeleted, DepartmentID: tech, CreatedAt: day(20)},
+			{Action: AuditRestored, DepartmentID: tech, CreatedAt: day(30)},
+			{Action: AuditDataExported, DepartmentID: tech, CreatedAt: day(31)},
+		}
+
+		got := memberships(emp, history)
+
+		want := []Membership{
+			{DepartmentID: sales, From: created, To: ptrTime(day(10))},
+			{DepartmentID: tech, From: day(10), To: ptrTime(day(20))},
+			{DepartmentID: tech, From: day(30)},
+		}
+		assertMemberships(t, got, want)
+	})
+
+	t.Run("history started after creation", func(t *testing.T) {
+		emp := &Employee{DepartmentID: tech, CreatedAt: created}
+		history := []AuditEvent{
+			{Action: AuditUpdated, Fields: []string{"name"}, DepartmentID: sales, CreatedAt: day(5)},
+			{Action: AuditUpdated, Fields: []string{"department_id"}, DepartmentID: tech, CreatedAt: day(10)},
+		}
+
+		got := memberships(emp, history)
+
+		want := []Membership{
+			{DepartmentID: sales, From: created, To: ptrTime(day(10))},
+			{DepartmentID: tech, From: day(10)},
+		}
+		assertMemberships(t, got, want)
+	})
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
+
+func assertMemberships(t *testing.T, got, want []Membership) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("memberships() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		sameEnd := (g.To == nil) == (w.To == nil) && (g.To == nil || g.To.Equal(*w.To))
+		if g.DepartmentID != w.DepartmentID || !g.From.Equal(w.From) || !sameEnd {
+			t.Errorf("membership %d = %+v, want %+v", i, g, w)
+		}
+	}
+}
+
+func TestExportEmployeeData(t *testing.T) {
+	setup := func() (*Service, *MockRepository, *Employee, *Department) {
+		repo := NewMockRepository()
+		deptRepo := NewMockDepartmentRepository()
+		service := NewService(repo, deptRepo, logging.NewMockLogger())
+
+		dept := &Department{ID: uuid.New(), Name: "Engineering"}
+		deptRepo.AddDepartment(dept)
+		emp := &Employee{ID: uuid.New(), Name: "John Doe", CPF: "12345678909", DepartmentID: dept.ID}
+		dept.ManagerID = emp.ID
+		return service, repo, emp, dept
+	}
+
+	t.Run("active employee", func(t *testing.T) {
+		service, repo, emp, dept := setup()
+		repo.AddEmployee(emp)
+		repo.AddEvents(emp.ID, AuditEvent{Action: AuditCreated, DepartmentID: dept.ID, CreatedAt: time.Now()})
+
+		export, err := service.ExportEmployeeData(emp.ID)
+		if err != nil {
+			t.Fatalf("ExportEmployeeData() returned error: %v", err)
+		}
+		if export.Employee.CPF != emp.CPF {
+			t.Error("ExportEmployeeData() did not include the employee record")
+		}
+		if len(export.Memberships) != 1 || export.Memberships[0].DepartmentName != "Engineering" {
+			t.Errorf("ExportEmployeeData() memberships = %+v, want Engineering", export.Memberships)
+		}
+		if len(export.ManagedDepartments) != 1 || export.ManagedDepartments[0].ID != dept.ID {
+			t.Errorf("ExportEmployeeData() managed departments = %+v, want Engineering", export.ManagedDepartments)
+		}
+		if len(export.History) != 1 {
+			t.Errorf("ExportEmployeeData() history = %+v, want the created event", export.History)
+		}
+
+		events, _ := repo.FindEvents(emp.ID)
+		if last := events[len(events)-1]; last.Action != AuditDataExported {
+			t.Errorf("ExportEmployeeData() recorded %s, want %s", last.Action, AuditDataExported)
+		}
+	})
+
+	t.Run("deleted employee", func(t *testing.T) {
+		service, repo, emp, _ := setup()
+		repo.AddDeletedEmployee(emp)
+
+		export, err := service.ExportEmployeeData(emp.ID)
+		if err != nil {
+			t.Fatalf("ExportEmployeeData() returned error: %v", err)
+		}
+		if len(export.Memberships) != 1 || export.Memberships[0].To == nil {
+			t.Errorf("ExportEmployeeData() memberships = %+v, want one ended at the deletion", export.Memberships)
+		}
+	})
+
+	t.Run("unknown employee", func(t *testing.T) {
+		service, _, _, _ := setup()
+
+		_, err := service.ExportEmployeeData(uuid.New())
+
+		var notFound *domainErrors.NotFoundError
+		if !errors.As(err, &notFound) {
+			t.Errorf("ExportEmployeeData() should return not found, got %v", err)
+		}
+	})
+}
+
+func TestAnonymizeEmployee(t *testing.T) {
+	setup := func() (*Service, *MockRepository, *Employee) {
+		repo := NewMockRepository()
+		deptRepo := NewMockDepartmentRepository()
+		service := NewService(repo, deptRepo, logging.NewMockLogger())
+
+		dept := &Department{ID: uuid.New(), Name: "Engineering"}
+		deptRepo.AddDepartment(dept)
+		rg := "12.345.678-X"
+		emp := &Employee{ID: uuid.New(), Name: "John Doe", CPF: "12345678909", RG: &rg, DepartmentID: dept.ID, Version: 1}
+		repo.AddDeletedEmployee(emp)
+		return service, repo, emp
+	}
+
+	t.Run("deleted employee", func(t *testing.T) {
+		service, repo, emp := setup()
+
+		anonymized, err := service.AnonymizeEmployee(emp.ID)
+		if err != nil {
+			t.Fatalf("AnonymizeEmployee() returned error: %v", err)
+		}
+		if anonymized.Name != AnonymizedName || anonymized.CPF != "" || anonymized.RG != nil || anonymized.AnonymizedAt == nil {
+			t.Errorf("AnonymizeEmployee() = %+v, want the personal data erased", anonymized)
+		}
+		stored, err := repo.FindDeletedByID(emp.ID)
+		if err != nil || stored.Name != AnonymizedName || stored.DepartmentID != emp.DepartmentID {
+			t.Errorf("AnonymizeEmployee() did not keep the deleted record, got %+v, %v", stored, err)
+		}
+		events, _ := repo.FindEvents(emp.ID)
+		if len(events) != 1 || events[0].Action != AuditAnonymized {
+			t.Errorf("AnonymizeEmployee() history = %+v, want the anonymized event", events)
+		}
+
+		_, err = service.RestoreEmployee(emp.ID)
+		var conflict *domainErrors.ConflictError
+		if !errors.As(err, &conflict) || conflict.Code != "employee.anonymized" {
+			t.Errorf("RestoreEmployee() of an anonymized employee should conflict, got %v", err)
+		}
+		_, err = service.AnonymizeEmployee(emp.ID)
+		if !errors.As(err, &conflict) || conflict.Code != "employee.already_anonymized" {
+			t.Errorf("AnonymizeEmployee() twice should conflict, got %v", err)
+		}
+	})
+
+	t.Run("within the retention period", func(t *testing.T) {
+		service, repo, emp := setup()
+		service.SetRetentionPeriod(30 * 24 * time.Hour)
+
+		_, err := service.AnonymizeEmployee(emp.ID)
+
+		var conflict *domainErrors.ConflictError
+		if !errors.As(err, &conflict) || conflict.Code != "employee.retention_period" {
+			t.Errorf("AnonymizeEmployee() should conflict within the retention period, got %v", err)
+		}
+		if stored, _ := repo.FindDeletedByID(emp.ID); stored.Name != "John Doe" {
+			t.Error("AnonymizeEmployee() changed the employee within the retention period")
+		}
+
+		emp.DeletedAt.Time = time.Now().AddDate(0, 0, -31)
+		if _, err := service.AnonymizeEmployee(emp.ID); err != nil {
+			t.Errorf("AnonymizeEmployee() after the retention period returned error: %v", err)
+		}
+	})
+
+	t.Run("active employee", func(t *testing.T) {
+		service, repo, emp := setup()
+		active := &Employee{ID: uuid.New(), Name: "Active", CPF: "11144477735", DepartmentID: emp.DepartmentID}
+		repo.AddEmployee(active)
+
+		_, err := service.AnonymizeEmployee(active.ID)
+
+		var conflict *domainErrors.ConflictError
+		if !errors.As(err, &conflict) || conflict.Code != "employee.anonymize_requires_deleted" {
+			t.Errorf("AnonymizeEmployee() should refuse active employees, got %v", err)
+		}
+	})
+
+	t.Run("unknown employee", func(t *testing.T) {
+		service, _, _ := setup()
+
+		_, err := service.AnonymizeEmployee(uuid.New())
+
+		var notFound *domainErrors.NotFoundError
+		if !errors.As(err, &notFound) {
+			t.Errorf("AnonymizeEmployee() should return not found, got %v", err)
+		}
+	})
+}
@@ -13,33 +13,81 @@ var (
 	ErrInvalidCPF         = errors.New("invalid CPF")
 	ErrDuplicateCPF       = errors.New("CPF already exists")
 	ErrDuplicateRG        = errors.New("RG already exists")
+	ErrPreconditionFailed = errors.New("precondition failed")
 )
 
-// ValidationError represents a validation error
+// Params fills the placeholders of a localized message, e.g. {"count": 3}
+type Params map[string]any
+
+// ValidationError represents a validation error. Code is a stable identifier
+// used to localize Message, which is always in English.
 type ValidationError struct {
 	Message string
 	Field   string
+	Code    string
+	Params  Params
+	Err     error // optional sentinel, e.g. ErrInvalidCPF
 }
 
 func (e *ValidationError) Error() string {
 	return e.Message
 }
 
+// Unwrap exposes the sentinel so errors.Is works with both the specific error
+// and ErrValidation
+func (e *ValidationError) Unwrap() []error {
+	return unwrap(e.Err, ErrValidation)
+}
+
 // ConflictError represents a conflict error (uniqueness violation)
 type ConflictError struct {
 	Message string
 	Field   string
+	Code    string
+	Params  Params
+	Err     error // optional sentinel, e.g. ErrDuplicateCPF
 }
 
 func (e *ConflictError) Error() string {
 	return e.Message
 }
 
+func (e *ConflictError) Unwrap() []error {
+	return unwrap(e.Err, ErrConflict)
+}
+
 // NotFoundError represents a not found error
 type NotFoundError struct {
 	Message string
+	Code    string
 }
 
 func (e *NotFoundError) Error() string {
 	return e.Message
 }
+
+func (e *NotFoundError) Unwrap() error {
+	return ErrNotFound
+}
+
+// PreconditionFailedError represents a stale write: the record changed since the
+// version the request was based on
+type PreconditionFailedError struct {
+	Message string
+	Code    string
+}
+
+func (e *PreconditionFailedError) Error() string {
+	return e.Message
+}
+
+func (e *PreconditionFailedError) Unwrap() error {
+	return ErrPreconditionFailed
+}
+
+func unwrap(specific, generic error) []error {
+	if specific == nil {
+		return []error{generic}
+	}
+	return []error{specific, generic}
+}
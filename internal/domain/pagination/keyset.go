@@ -0,0 +1,81 @@
+// Package pagination holds the ordering and keyset (cursor) pagination shared by
+// the listings
+package pagination
+
+import (
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// SortField is one key of the order of a listing
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// TimeFields are the sort fields holding timestamps, in every listing; the
+// others hold text
+var TimeFields = []string{"created_at", "updated_at"}
+
+// Cursor points at a row of a listing: its ID and, in order, its values for
+// the sort fields the listing was read with
+type Cursor struct {
+	ID     uuid.UUID
+	Values []any
+}
+
+// Keyset selects a page by position instead of by offset: the Limit rows right
+// after Cursor, or right before it when Backward is set, in the order of the
+// listing. Ties, and listings without a sort, are ordered by ID; IDs are
+// UUIDv7, so that is creation order and rows inserted meanwhile never shift a
+// page. Without a cursor the first (or, backwards, the last) page is read.
+type Keyset struct {
+	Cursor   *Cursor
+	Backward bool
+	Limit    int
+	// WithTotal asks for the number of matching rows, which costs a COUNT(*)
+	WithTotal bool
+}
+
+// Info tells where a page sits among the matching rows: Next and Prev point at
+// the rows to continue from in each direction, and are nil at either end
+type Info struct {
+	Next  *Cursor
+	Prev  *Cursor
+	Total *int64 // only set when Keyset.WithTotal was
+}
+
+// Cut takes the rows read for a page, up to Limit+1 of them in the direction
+// of the traversal along with their cursors, and returns the page in listing
+// order with its position. An empty page past a cursor leads back to it.
+func Cut[T any](rows []T, cursors []Cursor, k Keyset) ([]T, Info) {
+	more := len(rows) > k.Limit
+	if more {
+		rows, cursors = rows[:k.Limit], cursors[:k.Limit]
+	}
+	if k.Backward {
+		slices.Reverse(rows)
+		slices.Reverse(cursors)
+	}
+
+	first, last := k.Cursor, k.Cursor
+	if len(cursors) > 0 {
+		first, last = &cursors[0], &cursors[len(cursors)-1]
+	}
+
+	var info Info
+	// Reading forwards, more rows mean a next page and a cursor means the rows
+	// before it; backwards it is the other way round
+	hasNext, hasPrev := more, k.Cursor != nil
+	if k.Backward {
+		hasNext, hasPrev = hasPrev, hasNext
+	}
+	if hasNext {
+		info.Next = last
+	}
+	if hasPrev {
+		info.Prev = first
+	}
+	return rows, info
+}
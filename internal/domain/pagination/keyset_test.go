@@ -0,0 +1,56 @@
+package pagination
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestCut(t *testing.T) {
+	cursors := make([]Cursor, 10)
+	for i := range cursors {
+		cursors[i] = Cursor{ID: uuid.New()}
+	}
+	// rows are numbered after the position of their cursor
+	read := func(rows ...int) ([]int, []Cursor) {
+		var result []Cursor
+		for _, row := range rows {
+			result = append(result, cursors[row])
+		}
+		return rows, result
+	}
+	id := func(c *Cursor) uuid.UUID {
+		if c == nil {
+			return uuid.Nil
+		}
+		return c.ID
+	}
+
+	tests := []struct {
+		name       string
+		rows       []int
+		k          Keyset
+		want       []int
+		next, prev uuid.UUID
+	}{
+		{"first page with more", []int{1, 2, 3}, Keyset{Limit: 2}, []int{1, 2}, cursors[2].ID, uuid.Nil},
+		{"only page", []int{1, 2}, Keyset{Limit: 2}, []int{1, 2}, uuid.Nil, uuid.Nil},
+		{"after a cursor", []int{3, 4}, Keyset{Cursor: &cursors[2], Limit: 2}, []int{3, 4}, uuid.Nil, cursors[3].ID},
+		{"before a cursor with more", []int{4, 3, 2}, Keyset{Cursor: &cursors[5], Backward: true, Limit: 2}, []int{3, 4}, cursors[4].ID, cursors[3].ID},
+		{"last page", []int{9, 8}, Keyset{Backward: true, Limit: 2}, []int{8, 9}, uuid.Nil, uuid.Nil},
+		{"empty page after a cursor", nil, Keyset{Cursor: &cursors[7], Limit: 2}, nil, uuid.Nil, cursors[7].ID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, rowCursors := read(tt.rows...)
+			got, info := Cut(rows, rowCursors, tt.k)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Cut() rows = %v, want %v", got, tt.want)
+			}
+			if id(info.Next) != tt.next || id(info.Prev) != tt.prev {
+				t.Errorf("Cut() next/prev = %v/%v, want %v/%v", id(info.Next), id(info.Prev), tt.next, tt.prev)
+			}
+		})
+	}
+}
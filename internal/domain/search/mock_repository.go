@@ -0,0 +1,47 @@
+package search
+
+import "sync"
+
+type MockRepository struct {
+	mu      sync.Mutex
+	results []Result
+	err     error
+	// Queries holds every query passed to Search
+	Queries []Query
+}
+
+func NewMockRepository() *MockRepository {
+	return &MockRepository{}
+}
+
+func (m *MockRepository) Search(query Query) ([]Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.Queries = append(m.Queries, query)
+	if m.err != nil {
+		return nil, m.err
+	}
+	var results []Result
+	for _, result := range m.results {
+		if query.Kind == "" || result.Kind == query.Kind {
+			results = append(results, result)
+		}
+	}
+	if len(results) > query.Limit {
+		results = results[:query.Limit]
+	}
+	return results, nil
+}
+
+func (m *MockRepository) SetResults(results ...Result) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.results = results
+}
+
+func (m *MockRepository) SetError(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.err = err
+}
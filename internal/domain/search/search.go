@@ -0,0 +1,146 @@
+// Package search finds employees and departments from a single free-text query
+package search
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	domainErrors "api-employees-and-departments/internal/domain/errors"
+	"api-employees-and-departments/internal/domain/validators"
+
+	"github.com/google/uuid"
+)
+
+// Kind is the type of record a result points at
+type Kind string
+
+const (
+	KindEmployee   Kind = "employee"
+	KindDepartment Kind = "department"
+)
+
+// Fields a result can have matched on
+const (
+	FieldName = "name"
+	FieldCPF  = "cpf"
+	FieldRG   = "rg"
+)
+
+const (
+	// MinQueryLength is the shortest query, in characters, worth searching for;
+	// trigram matching has nothing to go on below it
+	MinQueryLength = 2
+	DefaultLimit   = 20
+	MaxLimit       = 50
+)
+
+// CPFPrefixLengths are the lengths of the CPF prefixes that can be searched:
+// the digit groups of a formatted CPF, which are indexed apart from the whole
+// CPF since encrypted documents can't be matched in part
+var CPFPrefixLengths = []int{3, 6, 9}
+
+// Query is a search as the repository runs it. Names are compared with the
+// accents and case removed. Documents are stored encrypted and can only be
+// matched whole, or for CPFs by one of CPFPrefixLengths first digits, so CPF
+// and RG are only set when the text could be one of those.
+type Query struct {
+	Text  string
+	CPF   string // digits only, a whole CPF or a prefix of CPFPrefixLengths
+	RG    string // as normalized by validators.NormalizeRG
+	Kind  Kind   // empty for every kind
+	Limit int
+}
+
+// Result is a record matching a search, best matches first. Score goes from 0
+// to 1: the word similarity of the name for name matches, 1 for a CPF or RG
+// and the share of the CPF digits given for a CPF prefix.
+type Result struct {
+	Kind         Kind
+	ID           uuid.UUID
+	Name         string
+	MatchedField string
+	Score        float64
+
+	// Department of an employee
+	DepartmentID   *uuid.UUID
+	DepartmentName string
+}
+
+type Repository interface {
+	// Search returns up to query.Limit records ordered by score, each record
+	// once with its best matching field
+	Search(query Query) ([]Result, error)
+}
+
+type Service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// Search looks text up in employee names, CPFs and RGs and in department names.
+// Documents are only searched when searchDocuments is set, so that callers who
+// may not see them can't confirm whose they are. A zero limit means
+// DefaultLimit.
+func (s *Service) Search(text string, kind Kind, limit int, searchDocuments bool) ([]Result, error) {
+	text = strings.TrimSpace(text)
+	if utf8.RuneCountInString(text) < MinQueryLength {
+		return nil, &domainErrors.ValidationError{
+			Field:   "q",
+			Code:    "search.query_too_short",
+			Params:  domainErrors.Params{"min": MinQueryLength},
+			Message: fmt.Sprintf("the search query must have at least %d characters", MinQueryLength),
+		}
+	}
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+
+	query := Query{Text: text, Kind: kind, Limit: min(limit, MaxLimit)}
+	if kind != KindDepartment && searchDocuments {
+		query.CPF = cpfQuery(text)
+		query.RG = rgDocument(text)
+	}
+	return s.repo.Search(query)
+}
+
+// cpfQuery returns the digits of text when it is a whole CPF or one of the
+// searchable prefixes, typed with or without its punctuation
+func cpfQuery(text string) string {
+	var digits strings.Builder
+	for _, r := range text {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '.' || r == '-' || r == ' ':
+		default:
+			return ""
+		}
+	}
+	if digits.Len() != 11 && !slices.Contains(CPFPrefixLengths, digits.Len()) {
+		return ""
+	}
+	return digits.String()
+}
+
+// rgDocument returns text normalized when it could be an RG: letters, digits
+// and punctuation, beginning with a digit
+func rgDocument(text string) string {
+	for _, r := range text {
+		switch {
+		case r >= '0' && r <= '9', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case r == '.' || r == '-' || r == ' ':
+		default:
+			return ""
+		}
+	}
+	rg := validators.NormalizeRG(text)
+	if rg == "" || rg[0] < '0' || rg[0] > '9' {
+		return ""
+	}
+	return rg
+}
@@ -0,0 +1,74 @@
+package search
+
+import (
+	"errors"
+	"testing"
+
+	domainErrors "api-employees-and-departments/internal/domain/errors"
+
+	"github.com/google/uuid"
+)
+
+func TestSearchBuildsQuery(t *testing.T) {
+	tests := []struct {
+		name            string
+		text            string
+		kind            Kind
+		limit           int
+		searchDocuments bool
+		want            Query
+	}{
+		{"name", "  João ", "", 0, true, Query{Text: "João", Limit: DefaultLimit}},
+		{"formatted CPF", "111.444.777-35", "", 10, true, Query{Text: "111.444.777-35", CPF: "11144477735", RG: "11144477735", Limit: 10}},
+		{"documents not searched", "111.444.777-35", "", 10, false, Query{Text: "111.444.777-35", Limit: 10}},
+		{"RG not searched", "12.345.678-x", "", 0, false, Query{Text: "12.345.678-x", Limit: DefaultLimit}},
+		{"start of a CPF", "111.444", "", 0, true, Query{Text: "111.444", CPF: "111444", RG: "111444", Limit: DefaultLimit}},
+		{"CPF prefix not indexed", "111.444.7", "", 0, true, Query{Text: "111.444.7", RG: "1114447", Limit: DefaultLimit}},
+		{"RG with check letter", "12.345.678-x", "", 0, true, Query{Text: "12.345.678-x", RG: "12345678X", Limit: DefaultLimit}},
+		{"too long for a CPF", "123456789012", "", 0, true, Query{Text: "123456789012", RG: "123456789012", Limit: DefaultLimit}},
+		{"departments only", "12", KindDepartment, 0, true, Query{Text: "12", Kind: KindDepartment, Limit: DefaultLimit}},
+		{"limit capped", "ana", KindEmployee, 500, true, Query{Text: "ana", Kind: KindEmployee, Limit: MaxLimit}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMockRepository()
+			service := NewService(repo)
+
+			if _, err := service.Search(tt.text, tt.kind, tt.limit, tt.searchDocuments); err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			if len(repo.Queries) != 1 || repo.Queries[0] != tt.want {
+				t.Errorf("Search() ran %+v, want %+v", repo.Queries, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchRejectsShortQuery(t *testing.T) {
+	repo := NewMockRepository()
+	service := NewService(repo)
+
+	_, err := service.Search(" é ", "", 0, true)
+	if !errors.Is(err, domainErrors.ErrValidation) {
+		t.Fatalf("Search() error = %v, want a validation error", err)
+	}
+	if len(repo.Queries) != 0 {
+		t.Errorf("Search() reached the repository with %+v", repo.Queries)
+	}
+}
+
+func TestSearchReturnsResults(t *testing.T) {
+	repo := NewMockRepository()
+	service := NewService(repo)
+	employee := Result{Kind: KindEmployee, ID: uuid.New(), Name: "João Silva", MatchedField: FieldName, Score: 1}
+	department := Result{Kind: KindDepartment, ID: uuid.New(), Name: "Jurídico", MatchedField: FieldName, Score: 0.4}
+	repo.SetResults(employee, department)
+
+	results, err := service.Search("joao", KindEmployee, 0, true)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(results) != 1 || results[0].ID != employee.ID {
+		t.Errorf("Search() = %+v, want only the employee", results)
+	}
+}
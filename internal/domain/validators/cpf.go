@@ -3,6 +3,7 @@ package validators
 import (
 	"regexp"
 	"strconv"
+	"strings"
 )
 
 // ValidateCPF validates a Brazilian CPF number
@@ -65,3 +66,38 @@ func ValidateCPF(cpf string) bool {
 
 	return true
 }
+
+// cpfPattern matches the 11 digits of a CPF, with or without the punctuation
+// of XXX.XXX.XXX-XX
+var cpfPattern = regexp.MustCompile(`^(\d{3})\.?(\d{3})\.?(\d{3})-?(\d{2})$`)
+
+// NormalizeCPF returns the 11 digits of a CPF typed with or without its
+// punctuation. Anything else is only trimmed, so validation still rejects it.
+func NormalizeCPF(cpf string) string {
+	cpf = strings.TrimSpace(cpf)
+	parts := cpfPattern.FindStringSubmatch(cpf)
+	if parts == nil {
+		return cpf
+	}
+	return parts[1] + parts[2] + parts[3] + parts[4]
+}
+
+// FormatCPF writes a CPF as XXX.XXX.XXX-XX. A value that is not a CPF is
+// returned unchanged.
+func FormatCPF(cpf string) string {
+	parts := cpfPattern.FindStringSubmatch(cpf)
+	if parts == nil {
+		return cpf
+	}
+	return parts[1] + "." + parts[2] + "." + parts[3] + "-" + parts[4]
+}
+
+// MaskCPF hides the first three and the check digits of a CPF, as in
+// ***.444.777-**. A value that is not a CPF is hidden entirely.
+func MaskCPF(cpf string) string {
+	parts := cpfPattern.FindStringSubmatch(cpf)
+	if parts == nil {
+		return "***.***.***-**"
+	}
+	return "***." + parts[2] + "." + parts[3] + "-**"
+}
@@ -74,3 +74,32 @@ func TestValidateCPF(t *testing.T) {
 		})
 	}
 }
+
+func TestCPFRepresentations(t *testing.T) {
+	tests := []struct {
+		input      string
+		normalized string
+		formatted  string
+		masked     string
+	}{
+		{"11144477735", "11144477735", "111.444.777-35", "***.444.777-**"},
+		{" 111.444.777-35 ", "11144477735", "111.444.777-35", "***.444.777-**"},
+		{"111444777-35", "11144477735", "111.444.777-35", "***.444.777-**"},
+		{"111/444/777-35", "111/444/777-35", "111/444/777-35", "***.***.***-**"},
+		{"1114447773", "1114447773", "1114447773", "***.***.***-**"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			normalized := NormalizeCPF(tt.input)
+			if normalized != tt.normalized {
+				t.Errorf("NormalizeCPF(%q) = %q, want %q", tt.input, normalized, tt.normalized)
+			}
+			if got := FormatCPF(normalized); got != tt.formatted {
+				t.Errorf("FormatCPF(%q) = %q, want %q", normalized, got, tt.formatted)
+			}
+			if got := MaskCPF(normalized); got != tt.masked {
+				t.Errorf("MaskCPF(%q) = %q, want %q", normalized, got, tt.masked)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package validators
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NormalizeRG returns the letters and digits of an RG in upper case, so the
+// same RG typed with or without punctuation compares equal
+func NormalizeRG(rg string) string {
+	var normalized strings.Builder
+	for _, r := range rg {
+		switch {
+		case r >= '0' && r <= '9':
+			normalized.WriteRune(r)
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+			normalized.WriteRune(unicode.ToUpper(r))
+		}
+	}
+	return normalized.String()
+}
@@ -0,0 +1,21 @@
+package validators
+
+import "testing"
+
+func TestNormalizeRG(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"123456789", "123456789"},
+		{"12.345.678-9", "123456789"},
+		{" 12.345.678-x ", "12345678X"},
+		{"MG-12.345.678", "MG12345678"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeRG(tt.input); got != tt.want {
+			t.Errorf("NormalizeRG(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
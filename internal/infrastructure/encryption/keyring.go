@@ -0,0 +1,170 @@
+// Package encryption protects personal data at rest: values are sealed with
+// AES-GCM, and keyed HMAC blind indexes let them be looked up without being
+// decrypted
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// prefix starts every encrypted value, as in "enc:<key id>:<base64>", so that
+// values written before encryption was enabled can be told apart
+const prefix = "enc:"
+
+// KeySize is the size of AES-256 keys; blind index keys must be at least as long
+const KeySize = 32
+
+var (
+	ErrNoKeys         = errors.New("no encryption key configured")
+	ErrUnknownKey     = errors.New("value encrypted with an unknown key")
+	ErrMalformedValue = errors.New("malformed encrypted value")
+)
+
+// Key is an AES-256 key with the ID stored next to the values it encrypts
+type Key struct {
+	ID     string
+	Secret []byte
+}
+
+// Keyring encrypts with its active key and decrypts with any of its keys, so
+// values written with a retired key stay readable until they are re-encrypted
+type Keyring struct {
+	activeID string
+	aeads    map[string]cipher.AEAD
+	indexKey []byte
+}
+
+// NewKeyring creates a Keyring whose first key is the active one. indexKey
+// keys the blind indexes and is not rotated with the encryption keys.
+func NewKeyring(keys []Key, indexKey []byte) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, ErrNoKeys
+	}
+	if len(indexKey) < KeySize {
+		return nil, fmt.Errorf("blind index key must have at least %d bytes", KeySize)
+	}
+
+	k := &Keyring{activeID: keys[0].ID, aeads: make(map[string]cipher.AEAD, len(keys)), indexKey: indexKey}
+	for _, key := range keys {
+		if key.ID == "" || strings.ContainsAny(key.ID, ":,") {
+			return nil, fmt.Errorf("invalid encryption key id %q", key.ID)
+		}
+		if _, ok := k.aeads[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate encryption key id %q", key.ID)
+		}
+		if len(key.Secret) != KeySize {
+			return nil, fmt.Errorf("encryption key %q must have %d bytes", key.ID, KeySize)
+		}
+		block, err := aes.NewCipher(key.Secret)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		k.aeads[key.ID] = aead
+	}
+	return k, nil
+}
+
+// ParseKeyring creates a Keyring from its configuration: keys as a
+// comma-separated list of id:base64 pairs, the active one first, and the
+// base64 blind index key
+func ParseKeyring(keys, indexKey string) (*Keyring, error) {
+	var parsed []Key
+	for _, pair := range strings.Split(keys, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		id, secret, ok := strings.Cut(pair, ":")
+		if !ok {
+			return nil, fmt.Errorf("encryption key %q is not in the id:base64 form", id)
+		}
+		decoded, err := base64.StdEncoding.DecodeString(secret)
+		if err != nil {
+			return nil, fmt.Errorf("encryption key %q is not valid base64", id)
+		}
+		parsed = append(parsed, Key{ID: id, Secret: decoded})
+	}
+
+	decodedIndexKey, err := base64.StdEncoding.DecodeString(strings.TrimSpace(indexKey))
+	if err != nil {
+		return nil, errors.New("blind index key is not valid base64")
+	}
+	return NewKeyring(parsed, decodedIndexKey)
+}
+
+// ActiveKeyID returns the ID of the key new values are encrypted with
+func (k *Keyring) ActiveKeyID() string {
+	return k.activeID
+}
+
+// Encrypt seals plaintext with the active key under a random nonce
+func (k *Keyring) Encrypt(plaintext string) (string, error) {
+	aead := k.aeads[k.activeID]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return prefix + k.activeID + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value returned by Encrypt with whichever key sealed it.
+// Values without the encryption prefix were stored before encryption was
+// enabled and are returned as they are.
+func (k *Keyring) Decrypt(stored string) (string, error) {
+	id, encoded, ok := k.split(stored)
+	if !ok {
+		return stored, nil
+	}
+	aead, found := k.aeads[id]
+	if !found {
+		return "", fmt.Errorf("%w: %q", ErrUnknownKey, id)
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", ErrMalformedValue
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return "", ErrMalformedValue
+	}
+	return string(plaintext), nil
+}
+
+// NeedsReencryption reports whether a stored value is plaintext or was sealed
+// with a key other than the active one
+func (k *Keyring) NeedsReencryption(stored string) bool {
+	id, _, ok := k.split(stored)
+	return !ok || id != k.activeID
+}
+
+// split takes an encrypted value apart into its key ID and payload
+func (k *Keyring) split(stored string) (id, encoded string, ok bool) {
+	rest, found := strings.CutPrefix(stored, prefix)
+	if !found {
+		return "", "", false
+	}
+	return strings.Cut(rest, ":")
+}
+
+// BlindIndex returns the hex HMAC-SHA256 of value. Equal values always have
+// the same index, so it can be compared and constrained to be unique in place
+// of the value, which it does not reveal without the key.
+func (k *Keyring) BlindIndex(value string) string {
+	mac := hmac.New(sha256.New, k.indexKey)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,137 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testKey(id string, fill byte) Key {
+	return Key{ID: id, Secret: bytes.Repeat([]byte{fill}, KeySize)}
+}
+
+func testKeyring(t *testing.T, keys ...Key) *Keyring {
+	t.Helper()
+	keyring, err := NewKeyring(keys, bytes.Repeat([]byte{'i'}, KeySize))
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+	return keyring
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	keyring := testKeyring(t, testKey("k1", 1))
+
+	stored, err := keyring.Encrypt("11144477735")
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	if !strings.HasPrefix(stored, "enc:k1:") || strings.Contains(stored, "11144477735") {
+		t.Errorf("Encrypt() = %q, want a value sealed with k1", stored)
+	}
+	again, _ := keyring.Encrypt("11144477735")
+	if again == stored {
+		t.Error("Encrypt() returned the same value twice, want a fresh nonce every time")
+	}
+
+	plaintext, err := keyring.Decrypt(stored)
+	if err != nil || plaintext != "11144477735" {
+		t.Errorf("Decrypt() = %q, %v, want the original value", plaintext, err)
+	}
+}
+
+func TestDecryptLegacyAndInvalidValues(t *testing.T) {
+	keyring := testKeyring(t, testKey("k1", 1))
+
+	if plaintext, err := keyring.Decrypt("11144477735"); err != nil || plaintext != "11144477735" {
+		t.Errorf("Decrypt(plaintext) = %q, %v, want the value unchanged", plaintext, err)
+	}
+	if _, err := keyring.Decrypt("enc:k9:AAAA"); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Decrypt(unknown key) error = %v, want ErrUnknownKey", err)
+	}
+
+	stored, _ := keyring.Encrypt("11144477735")
+	tampered := stored[:len(stored)-4] + "AAAA"
+	if _, err := keyring.Decrypt(tampered); !errors.Is(err, ErrMalformedValue) {
+		t.Errorf("Decrypt(tampered) error = %v, want ErrMalformedValue", err)
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	old := testKeyring(t, testKey("k1", 1))
+	stored, _ := old.Encrypt("12.345.678-X")
+
+	rotated := testKeyring(t, testKey("k2", 2), testKey("k1", 1))
+	if !rotated.NeedsReencryption(stored) {
+		t.Error("NeedsReencryption() = false for a value sealed with a retired key")
+	}
+	plaintext, err := rotated.Decrypt(stored)
+	if err != nil || plaintext != "12.345.678-X" {
+		t.Errorf("Decrypt() with a retired key = %q, %v", plaintext, err)
+	}
+
+	reencrypted, _ := rotated.Encrypt(plaintext)
+	if rotated.NeedsReencryption(reencrypted) {
+		t.Error("NeedsReencryption() = true for a value sealed with the active key")
+	}
+	if !rotated.NeedsReencryption("11144477735") {
+		t.Error("NeedsReencryption() = false for a plaintext value")
+	}
+}
+
+func TestBlindIndex(t *testing.T) {
+	keyring := testKeyring(t, testKey("k1", 1))
+	rotated := testKeyring(t, testKey("k2", 2), testKey("k1", 1))
+
+	index := keyring.BlindIndex("11144477735")
+	if len(index) != 64 || strings.Contains(index, "11144477735") {
+		t.Errorf("BlindIndex() = %q, want a hex HMAC-SHA256", index)
+	}
+	if rotated.BlindIndex("11144477735") != index {
+		t.Error("BlindIndex() changed with the encryption keys")
+	}
+	if keyring.BlindIndex("11144477736") == index {
+		t.Error("BlindIndex() is the same for different values")
+	}
+
+	other, _ := NewKeyring([]Key{testKey("k1", 1)}, bytes.Repeat([]byte{'j'}, KeySize))
+	if other.BlindIndex("11144477735") == index {
+		t.Error("BlindIndex() does not depend on the index key")
+	}
+}
+
+func TestParseKeyring(t *testing.T) {
+	secret := func(fill byte) string {
+		return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{fill}, KeySize))
+	}
+
+	keyring, err := ParseKeyring("k2:"+secret(2)+", k1:"+secret(1), secret('i'))
+	if err != nil {
+		t.Fatalf("ParseKeyring() error = %v", err)
+	}
+	if keyring.ActiveKeyID() != "k2" {
+		t.Errorf("ActiveKeyID() = %q, want the first key", keyring.ActiveKeyID())
+	}
+
+	invalid := []struct {
+		name     string
+		keys     string
+		indexKey string
+	}{
+		{"no keys", "", secret('i')},
+		{"missing id", secret(1), secret('i')},
+		{"short key", "k1:" + base64.StdEncoding.EncodeToString([]byte("short")), secret('i')},
+		{"duplicate id", "k1:" + secret(1) + ",k1:" + secret(2), secret('i')},
+		{"short index key", "k1:" + secret(1), base64.StdEncoding.EncodeToString([]byte("short"))},
+		{"index key not base64", "k1:" + secret(1), "%%%"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseKeyring(tt.keys, tt.indexKey); err == nil {
+				t.Error("ParseKeyring() error = nil, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package ginapi
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	domainErrors "api-employees-and-departments/internal/domain/errors"
+	"api-employees-and-departments/internal/domain/validators"
+	"api-employees-and-departments/internal/interfaces/api/dto"
+	"api-employees-and-departments/internal/interfaces/api/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// cpfFormat picks how a response writes CPFs from the cpf_format query
+// parameter. Callers with access to personal data get the digits by default;
+// the others get masked CPFs and are refused any other format. It reports the
+// error and returns ok false when the format is unknown or not allowed.
+func cpfFormat(c *gin.Context) (dto.CPFFormat, bool) {
+	// The same URL answers differently depending on the key
+	c.Writer.Header().Add("Vary", apiKeyHeader)
+	allowed := c.GetBool(piiAccessKey)
+
+	value := c.Query("cpf_format")
+	if value == "" {
+		if allowed {
+			return dto.CPFDigits, true
+		}
+		return dto.CPFMasked, true
+	}
+
+	if !slices.Contains(dto.CPFFormats, value) {
+		formats := strings.Join(dto.CPFFormats, ", ")
+		badRequest(c, "validation_error", requestError("cpf_format", "request.invalid_cpf_format",
+			domainErrors.Params{"value": value, "allowed": formats},
+			fmt.Sprintf("unknown cpf_format %s; expected one of %s", value, formats)))
+		return "", false
+	}
+
+	format := dto.CPFFormat(value)
+	if format != dto.CPFMasked && !allowed {
+		lang := requestLanguage(c)
+		problem := newProblem(lang, http.StatusForbidden, "forbidden",
+			i18n.Message(lang, "cpf.access_denied", nil,
+				"full CPFs are only shown to API keys with access to personal data; use cpf_format=masked"))
+		problem.Instance = getRequestID(c)
+		writeProblem(c, http.StatusForbidden, problem)
+		return "", false
+	}
+	return format, true
+}
+
+// normalizeCPFFilter lets listings be filtered by a CPF typed with punctuation
+func normalizeCPFFilter(cpf *string) *string {
+	if cpf == nil {
+		return nil
+	}
+	normalized := validators.NormalizeCPF(*cpf)
+	return &normalized
+}
+
+// allowDocumentFilters refuses with a 403 a listing filtered by CPF or RG unless
+// the API key has access to personal data, since the answer would confirm
+// whose document it is. It reports whether the request may go on.
+func allowDocumentFilters(c *gin.Context, cpf, rg *string) bool {
+	c.Writer.Header().Add("Vary", apiKeyHeader)
+	if c.GetBool(piiAccessKey) || ((cpf == nil || *cpf == "") && (rg == nil || *rg == "")) {
+		return true
+	}
+
+	lang := requestLanguage(c)
+	problem := newProblem(lang, http.StatusForbidden, "forbidden",
+		i18n.Message(lang, "filter.pii_access_denied", nil,
+			"filtering by CPF or RG needs an API key with access to personal data"))
+	problem.Instance = getRequestID(c)
+	writeProblem(c, http.StatusForbidden, problem)
+	return false
+}
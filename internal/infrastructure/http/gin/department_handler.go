@@ -1,11 +1,19 @@
 package ginapi
 
 import (
+	"bytes"
+	"cmp"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"api-employees-and-departments/internal/domain/department"
+	domainErrors "api-employees-and-departments/internal/domain/errors"
 	"api-employees-and-departments/internal/infrastructure/logging"
 	"api-employees-and-departments/internal/interfaces/api/dto"
+	"api-employees-and-departments/internal/interfaces/api/i18n"
+	"api-employees-and-departments/internal/interfaces/api/orgchart"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -26,15 +34,12 @@ func NewDepartmentHandler(s *department.Service) *DepartmentHandler {
 // @Accept json
 // @Produce json
 // @Success 200 {array} dto.DepartmentResponse
-// @Failure 500 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ProblemDetails
 // @Router /departments [get]
 func (h *DepartmentHandler) GetAll(c *gin.Context) {
 	departments, err := h.service.GetAllDepartments()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error:   "internal_server_error",
-			Message: err.Error(),
-		})
+		_ = c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, dto.ToDepartmentResponseList(departments))
@@ -45,40 +50,151 @@ func (h *DepartmentHandler) GetAll(c *gin.Context) {
 // @Tags departments
 // @Accept json
 // @Produce json
+// @Description With include=headcount every node carries its own active employees (headcount) and those of its whole subtree (total_headcount).
+// @Description With include=employees every node lists up to employees_limit of its employees, ordered by name; more_employees tells that some were left out.
 // @Param id path string true "Department ID"
+// @Param include query string false "Comma-separated optional parts: headcount, employees"
+// @Param employees_limit query int false "Employees listed per department with include=employees" default(20) minimum(1) maximum(100)
+// @Param If-None-Match header string false "ETag of a cached copy"
 // @Success 200 {object} dto.DepartmentWithHierarchyResponse
-// @Failure 400 {object} dto.ErrorResponse
-// @Failure 404 {object} dto.ErrorResponse
+// @Success 304 "Not modified"
+// @Header 200 {string} ETag "Version of the department and hash of the response"
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
 // @Router /departments/{id} [get]
 func (h *DepartmentHandler) GetByID(c *gin.Context) {
-	id, err := uuid.Parse(c.Param("id"))
+	id, ok := parseUUIDParam(c, "department")
+	if !ok {
+		return
+	}
+
+	var req dto.GetDepartmentRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+	includes, unknown := dto.ParseIncludes(req.Include, dto.DepartmentIncludes)
+	if unknown != "" {
+		allowed := strings.Join(dto.DepartmentIncludes, ", ")
+		badRequest(c, "validation_error", requestError("include", "request.invalid_include",
+			domainErrors.Params{"value": unknown, "allowed": allowed},
+			fmt.Sprintf("unknown include %s; expected one of %s", unknown, allowed)))
+		return
+	}
+
+	var deptWithHierarchy *department.DepartmentWithHierarchy
+	var err error
+	if includes[dto.IncludeEmployees] {
+		deptWithHierarchy, err = h.service.GetDepartmentWithEmployees(id, req.EmployeesLimit)
+	} else {
+		deptWithHierarchy, err = h.service.GetDepartmentWithHierarchy(id)
+	}
 	if err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "invalid_id",
-			Message: "Invalid department ID format",
-		})
+		_ = c.Error(err)
 		return
 	}
 
-	deptWithHierarchy, err := h.service.GetDepartmentWithHierarchy(id)
+	// The version only covers the department's own fields, not the tree, the
+	// manager names or the included parts, so the ETag hashes the body too
+	jsonWithETag(c, deptWithHierarchy.Version, h.toHierarchyResponse(deptWithHierarchy, includes))
+}
+
+// GetOrgChart godoc
+// @Summary Render the org chart below a department
+// @Description Draws the department tree with the manager name and headcount of every node, as Graphviz DOT, Mermaid or SVG (default)
+// @Tags departments
+// @Produce image/svg+xml
+// @Produce text/vnd.graphviz
+// @Produce text/plain
+// @Param id path string true "Department ID"
+// @Param format query string false "Output format" Enums(svg, dot, mermaid)
+// @Success 200 {file} file
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /departments/{id}/orgchart [get]
+func (h *DepartmentHandler) GetOrgChart(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "department")
+	if !ok {
+		return
+	}
+
+	var req dto.OrgChartRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	tree, err := h.service.GetDepartmentWithHierarchy(id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, dto.ErrorResponse{
-			Error:   "not_found",
-			Message: "Department not found",
-		})
+		_ = c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, h.toHierarchyResponse(deptWithHierarchy))
+	var chart bytes.Buffer
+	format := orgchart.Format(req.Format)
+	if err := orgchart.Render(&chart, format, toOrgChartNode(requestLanguage(c), tree)); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Data(http.StatusOK, format.ContentType(), chart.Bytes())
+}
+
+// toOrgChartNode labels a department tree for the org chart
+func toOrgChartNode(lang i18n.Lang, dept *department.DepartmentWithHierarchy) *orgchart.Node {
+	node := &orgchart.Node{Title: dept.Name}
+	if dept.ManagerName != "" {
+		node.Details = append(node.Details, i18n.Message(lang, "orgchart.manager",
+			map[string]any{"name": dept.ManagerName}, "Manager: "+dept.ManagerName))
+	}
+	node.Details = append(node.Details, i18n.Message(lang, "orgchart.headcount",
+		map[string]any{"count": dept.Headcount}, fmt.Sprintf("%d employees", dept.Headcount)))
+	if len(dept.Subdepartments) > 0 {
+		node.Details = append(node.Details, i18n.Message(lang, "orgchart.total_headcount",
+			map[string]any{"count": dept.TotalHeadcount}, fmt.Sprintf("%d in total", dept.TotalHeadcount)))
+	}
+
+	for i := range dept.Subdepartments {
+		node.Children = append(node.Children, toOrgChartNode(lang, &dept.Subdepartments[i]))
+	}
+	return node
 }
 
-func (h *DepartmentHandler) toHierarchyResponse(dept *department.DepartmentWithHierarchy) dto.DepartmentWithHierarchyResponse {
+// GetAncestors godoc
+// @Summary Get the path from the root down to a department (breadcrumb)
+// @Tags departments
+// @Accept json
+// @Produce json
+// @Param id path string true "Department ID"
+// @Success 200 {array} dto.DepartmentAncestorResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /departments/{id}/ancestors [get]
+func (h *DepartmentHandler) GetAncestors(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "department")
+	if !ok {
+		return
+	}
+
+	ancestors, err := h.service.GetDepartmentAncestors(id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToDepartmentAncestorResponseList(ancestors))
+}
+
+func (h *DepartmentHandler) toHierarchyResponse(dept *department.DepartmentWithHierarchy, includes map[string]bool) dto.DepartmentWithHierarchyResponse {
 	subdepartments := make([]dto.DepartmentWithHierarchyResponse, 0, len(dept.Subdepartments))
 	for _, sub := range dept.Subdepartments {
-		subdepartments = append(subdepartments, h.toHierarchyResponse(&sub))
+		subdepartments = append(subdepartments, h.toHierarchyResponse(&sub, includes))
 	}
 
-	return dto.DepartmentWithHierarchyResponse{
+	response := dto.DepartmentWithHierarchyResponse{
 		ID:                 dept.Department.ID,
 		Name:               dept.Department.Name,
 		ManagerID:          dept.Department.ManagerID,
@@ -88,6 +204,22 @@ func (h *DepartmentHandler) toHierarchyResponse(dept *department.DepartmentWithH
 		CreatedAt:          dept.Department.CreatedAt,
 		UpdatedAt:          dept.Department.UpdatedAt,
 	}
+	if includes[dto.IncludeHeadcount] {
+		response.Headcount = &dept.Headcount
+		response.TotalHeadcount = &dept.TotalHeadcount
+	}
+	if includes[dto.IncludeEmployees] {
+		response.Employees = make([]dto.HierarchyEmployeeResponse, 0, len(dept.Employees))
+		for _, emp := range dept.Employees {
+			response.Employees = append(response.Employees, dto.HierarchyEmployeeResponse{
+				ID:        emp.ID,
+				Name:      emp.Name,
+				IsManager: emp.ID == dept.ManagerID,
+			})
+		}
+		response.MoreEmployees = dept.MoreEmployees
+	}
+	return response
 }
 
 // Create godoc
@@ -98,16 +230,14 @@ func (h *DepartmentHandler) toHierarchyResponse(dept *department.DepartmentWithH
 // @Produce json
 // @Param department body dto.CreateDepartmentRequest true "Department data"
 // @Success 201 {object} dto.DepartmentResponse
-// @Failure 400 {object} dto.ErrorResponse
-// @Failure 500 {object} dto.ErrorResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 422 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
 // @Router /departments [post]
 func (h *DepartmentHandler) Create(c *gin.Context) {
 	var req dto.CreateDepartmentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "validation_error",
-			Message: err.Error(),
-		})
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
 		return
 	}
 
@@ -118,10 +248,7 @@ func (h *DepartmentHandler) Create(c *gin.Context) {
 			zap.String("name", req.Name),
 			zap.String("request_id", getRequestID(c)),
 		)
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error:   "creation_failed",
-			Message: err.Error(),
-		})
+		_ = c.Error(err)
 		return
 	}
 
@@ -142,41 +269,41 @@ func (h *DepartmentHandler) Create(c *gin.Context) {
 // @Produce json
 // @Param id path string true "Department ID"
 // @Param department body dto.UpdateDepartmentRequest true "Department data"
+// @Param If-Match header string false "ETag the change is based on"
 // @Success 200 {object} dto.DepartmentResponse
-// @Failure 400 {object} dto.ErrorResponse
-// @Failure 404 {object} dto.ErrorResponse
-// @Failure 500 {object} dto.ErrorResponse
+// @Header 200 {string} ETag "New version of the department"
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 412 {object} dto.ProblemDetails
+// @Failure 422 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
 // @Router /departments/{id} [put]
 func (h *DepartmentHandler) Update(c *gin.Context) {
-	id, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "invalid_id",
-			Message: "Invalid department ID format",
-		})
+	id, ok := parseUUIDParam(c, "department")
+	if !ok {
 		return
 	}
 
 	var req dto.UpdateDepartmentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "validation_error",
-			Message: err.Error(),
-		})
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	version, ok := ifMatchVersion(c, "department")
+	if !ok {
 		return
 	}
 
 	dept := dto.ToDepartmentEntityFromUpdate(&req)
+	dept.Version = version
 	if err := h.service.UpdateDepartment(id, dept); err != nil {
 		logging.Error("Failed to update department",
 			zap.Error(err),
 			zap.String("department_id", id.String()),
 			zap.String("request_id", getRequestID(c)),
 		)
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error:   "update_failed",
-			Message: err.Error(),
-		})
+		_ = c.Error(err)
 		return
 	}
 
@@ -185,44 +312,285 @@ func (h *DepartmentHandler) Update(c *gin.Context) {
 		zap.String("request_id", getRequestID(c)),
 	)
 
+	setETag(c, dept.Version)
+	c.JSON(http.StatusOK, dto.ToDepartmentResponse(dept))
+}
+
+// Patch godoc
+// @Summary Partially update a department
+// @Description JSON Merge Patch (RFC 7396): only the fields sent are validated and written. null on parent_department_id turns the department into a root.
+// @Tags departments
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param id path string true "Department ID"
+// @Param department body dto.PatchDepartmentRequest true "Fields to change"
+// @Param If-Match header string false "ETag the change is based on"
+// @Success 200 {object} dto.DepartmentResponse
+// @Header 200 {string} ETag "New version of the department"
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 412 {object} dto.ProblemDetails
+// @Failure 415 {object} dto.ProblemDetails
+// @Failure 422 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /departments/{id} [patch]
+func (h *DepartmentHandler) Patch(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "department")
+	if !ok {
+		return
+	}
+	if !requireMergePatch(c) {
+		return
+	}
+
+	var req dto.PatchDepartmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	version, ok := ifMatchVersion(c, "department")
+	if !ok {
+		return
+	}
+
+	patch := dto.ToDepartmentPatch(&req)
+	patch.Version = version
+	dept, err := h.service.PatchDepartment(id, patch)
+	if err != nil {
+		logging.Error("Failed to patch department",
+			zap.Error(err),
+			zap.String("department_id", id.String()),
+			zap.String("request_id", getRequestID(c)),
+		)
+		_ = c.Error(err)
+		return
+	}
+
+	logging.Info("Department patched successfully",
+		zap.String("department_id", id.String()),
+		zap.String("request_id", getRequestID(c)),
+	)
+
+	setETag(c, dept.Version)
+	c.JSON(http.StatusOK, dto.ToDepartmentResponse(dept))
+}
+
+// Move godoc
+// @Summary Move a department (and its whole subtree) under another parent
+// @Description Reparent a department in a single transaction. Use null (without quotes) for parent_department_id to turn it into a root department.
+// @Tags departments
+// @Accept json
+// @Produce json
+// @Param id path string true "Department ID"
+// @Param move body dto.MoveDepartmentRequest true "New parent"
+// @Success 200 {object} dto.DepartmentResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 422 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /departments/{id}/move [post]
+func (h *DepartmentHandler) Move(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "department")
+	if !ok {
+		return
+	}
+
+	var req dto.MoveDepartmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+	if !req.ParentDepartmentID.Set {
+		badRequest(c, "validation_error", requestError("parent_department_id", "department.parent_required", nil,
+			"parent_department_id is required (use null to move to the root)"))
+		return
+	}
+
+	dept, err := h.service.MoveDepartment(id, req.ParentDepartmentID.ToUUIDPointer())
+	if err != nil {
+		logging.Error("Failed to move department",
+			zap.Error(err),
+			zap.String("department_id", id.String()),
+			zap.String("request_id", getRequestID(c)),
+		)
+		_ = c.Error(err)
+		return
+	}
+
+	logging.Info("Department moved successfully",
+		zap.String("department_id", id.String()),
+		zap.String("request_id", getRequestID(c)),
+	)
+
 	c.JSON(http.StatusOK, dto.ToDepartmentResponse(dept))
 }
 
 // Delete godoc
 // @Summary Delete a department
+// @Description Soft-delete a department. strategy=restrict (default) refuses while employees or sub-departments exist, reparent moves them to the parent department and cascade deletes the whole subtree with its employees.
 // @Tags departments
 // @Accept json
 // @Produce json
 // @Param id path string true "Department ID"
-// @Success 204
-// @Failure 400 {object} dto.ErrorResponse
-// @Failure 404 {object} dto.ErrorResponse
-// @Failure 500 {object} dto.ErrorResponse
+// @Param strategy query string false "Deletion strategy" Enums(restrict, reparent, cascade) default(restrict)
+// @Param If-Match header string false "ETag the deletion is based on"
+// @Success 200 {object} dto.DeleteDepartmentResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 409 {object} dto.ProblemDetails
+// @Failure 412 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
 // @Router /departments/{id} [delete]
 func (h *DepartmentHandler) Delete(c *gin.Context) {
-	id, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "invalid_id",
-			Message: "Invalid department ID format",
-		})
+	id, ok := parseUUIDParam(c, "department")
+	if !ok {
+		return
+	}
+
+	var req dto.DeleteDepartmentRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		badRequest(c, "validation_error", requestError("strategy", "department.invalid_delete_strategy",
+			domainErrors.Params{"strategy": c.Query("strategy")}, "strategy must be one of restrict, reparent, cascade"))
+		return
+	}
+
+	version, ok := ifMatchVersion(c, "department")
+	if !ok {
 		return
 	}
 
-	if err := h.service.DeleteDepartment(id); err != nil {
+	summary, err := h.service.DeleteDepartment(id, department.DeleteOptions{
+		Strategy:        department.DeleteStrategy(req.Strategy),
+		ExpectedVersion: version,
+	})
+	if err != nil {
 		logging.Error("Failed to delete department",
 			zap.Error(err),
 			zap.String("department_id", id.String()),
+			zap.String("strategy", req.Strategy),
 			zap.String("request_id", getRequestID(c)),
 		)
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error:   "deletion_failed",
-			Message: err.Error(),
-		})
+		_ = c.Error(err)
 		return
 	}
 
 	logging.Info("Department deleted successfully",
+		zap.String("department_id", id.String()),
+		zap.String("strategy", req.Strategy),
+		zap.String("request_id", getRequestID(c)),
+	)
+
+	c.JSON(http.StatusOK, dto.ToDeleteDepartmentResponse(summary))
+}
+
+// ListDeleted godoc
+// @Summary List soft-deleted departments
+// @Tags departments
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} dto.PaginatedResponse{data=[]dto.DepartmentResponse}
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /departments/deleted [get]
+func (h *DepartmentHandler) ListDeleted(c *gin.Context) {
+	var req dto.ListDeletedRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	departments, total, err := h.service.ListDeletedDepartments(req.Page, req.PageSize)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	// Calculate total pages
+	totalPages := int(total) / req.PageSize
+	if int(total)%req.PageSize != 0 {
+		totalPages++
+	}
+
+	c.JSON(http.StatusOK, dto.PaginatedResponse{
+		Data:       dto.ToDepartmentResponseList(departments),
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+		Total:      total,
+		TotalPages: totalPages,
+	})
+}
+
+// Restore godoc
+// @Summary Restore a soft-deleted department
+// @Description Re-runs the domain validations before undeleting the record
+// @Tags departments
+// @Accept json
+// @Produce json
+// @Param id path string true "Department ID"
+// @Success 200 {object} dto.DepartmentResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 409 {object} dto.ProblemDetails
+// @Failure 422 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /departments/{id}/restore [post]
+func (h *DepartmentHandler) Restore(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "department")
+	if !ok {
+		return
+	}
+
+	dept, err := h.service.RestoreDepartment(id)
+	if err != nil {
+		logging.Error("Failed to restore department",
+			zap.Error(err),
+			zap.String("department_id", id.String()),
+			zap.String("request_id", getRequestID(c)),
+		)
+		_ = c.Error(err)
+		return
+	}
+
+	logging.Info("Department restored successfully",
+		zap.String("department_id", id.String()),
+		zap.String("request_id", getRequestID(c)),
+	)
+
+	c.JSON(http.StatusOK, dto.ToDepartmentResponse(dept))
+}
+
+// Purge godoc
+// @Summary Permanently remove a soft-deleted department
+// @Tags departments
+// @Accept json
+// @Produce json
+// @Param id path string true "Department ID"
+// @Success 204
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 409 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /departments/{id}/purge [delete]
+func (h *DepartmentHandler) Purge(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "department")
+	if !ok {
+		return
+	}
+
+	if err := h.service.PurgeDepartment(id); err != nil {
+		logging.Error("Failed to purge department",
+			zap.Error(err),
+			zap.String("department_id", id.String()),
+			zap.String("request_id", getRequestID(c)),
+		)
+		_ = c.Error(err)
+		return
+	}
+
+	logging.Info("Department purged successfully",
 		zap.String("department_id", id.String()),
 		zap.String("request_id", getRequestID(c)),
 	)
@@ -230,53 +598,102 @@ func (h *DepartmentHandler) Delete(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// Export godoc
+// @Summary Export departments as CSV or XLSX
+// @Description Exports every department matching the filters of /departments/list, with the manager and parent department names. The format comes from the format parameter or else from the Accept header.
+// @Tags departments
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param name query string false "Name contains"
+// @Param manager_name query string false "Manager name"
+// @Param parent_department_id query string false "Parent department ID"
+// @Param has_parent query bool false "Only departments with (true) or without (false) a parent"
+// @Param root_only query bool false "Only root departments"
+// @Param min_depth query int false "Minimum distance from the root"
+// @Param max_depth query int false "Maximum distance from the root"
+// @Param min_headcount query int false "Minimum number of active employees"
+// @Param max_headcount query int false "Maximum number of active employees"
+// @Param created_from query string false "Created at or after (RFC 3339)"
+// @Param created_to query string false "Created at or before (RFC 3339)"
+// @Param updated_from query string false "Updated at or after (RFC 3339)"
+// @Param updated_to query string false "Updated at or before (RFC 3339)"
+// @Param format query string false "File format" Enums(csv, xlsx)
+// @Success 200 {file} file
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 406 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /departments/export [get]
+func (h *DepartmentHandler) Export(c *gin.Context) {
+	var req dto.ExportDepartmentsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	filters, ok := departmentFilters(c, req.DepartmentFilters)
+	if !ok {
+		return
+	}
+
+	format, ok := exportFormat(c, req.Format)
+	if !ok {
+		return
+	}
+
+	writeExport(c, format, "departments", dto.DepartmentExportHeader, func(write func(values ...any) error) error {
+		return h.service.ExportDepartments(filters, func(row department.ExportRow) error {
+			return write(dto.ToDepartmentExportRecord(row)...)
+		})
+	})
+}
+
 // List godoc
 // @Summary List departments with filters and pagination
+// @Description Pages with page/page_size, or with keyset pagination when after, before or limit is sent. Keyset pages follow creation order, answer with dto.CursorResponse and only count the total with include_total.
 // @Tags departments
 // @Accept json
 // @Produce json
 // @Param filters body dto.ListDepartmentsRequest true "Filter and pagination params"
 // @Success 200 {object} dto.PaginatedResponse
-// @Failure 400 {object} dto.ErrorResponse
-// @Failure 500 {object} dto.ErrorResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
 // @Router /departments/list [post]
 func (h *DepartmentHandler) List(c *gin.Context) {
 	var req dto.ListDepartmentsRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "validation_error",
-			Message: err.Error(),
-		})
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+	sort, ok := listSort(c, req.Sort, department.SortFields)
+	if !ok {
+		return
+	}
+	keyset, useCursor, ok := listKeyset(c, req.Page, req.PageSize, req.CursorRequest, sort)
+	if !ok {
 		return
 	}
 
-	// Build filters
-	filters := department.ListFilters{
-		Name:        req.Name,
-		ManagerName: req.ManagerName,
-		Page:        req.Page,
-		PageSize:    req.PageSize,
+	filters, ok := departmentFilters(c, req.DepartmentFilters)
+	if !ok {
+		return
 	}
+	filters.Sort = sort
+	filters.Page = req.Page
+	filters.PageSize = req.PageSize
 
-	// Parse parent department ID if provided
-	if req.ParentDepartmentID != nil && *req.ParentDepartmentID != "" {
-		parentID, err := uuid.Parse(*req.ParentDepartmentID)
+	if useCursor {
+		departments, info, err := h.service.ListDepartmentsPage(filters, keyset)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-				Error:   "invalid_filter",
-				Message: "Invalid parent department ID format",
-			})
+			_ = c.Error(err)
 			return
 		}
-		filters.ParentDepartmentID = &parentID
+		c.JSON(http.StatusOK, dto.NewCursorResponse(dto.ToDepartmentResponseList(departments), sort, keyset, info))
+		return
 	}
 
 	departments, total, err := h.service.ListDepartments(filters)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error:   "list_failed",
-			Message: err.Error(),
-		})
+		_ = c.Error(err)
 		return
 	}
 
@@ -294,3 +711,62 @@ func (h *DepartmentHandler) List(c *gin.Context) {
 		TotalPages: totalPages,
 	})
 }
+
+// departmentFilters converts the filters of a listing or export, reporting a
+// bad request and returning ok false when they are malformed or contradict
+// each other
+func departmentFilters(c *gin.Context, req dto.DepartmentFilters) (department.ListFilters, bool) {
+	filters := department.ListFilters{
+		Name:         req.Name,
+		ManagerName:  req.ManagerName,
+		HasParent:    req.HasParent,
+		MinDepth:     req.MinDepth,
+		MaxDepth:     req.MaxDepth,
+		MinHeadcount: req.MinHeadcount,
+		MaxHeadcount: req.MaxHeadcount,
+		CreatedFrom:  req.CreatedFrom,
+		CreatedTo:    req.CreatedTo,
+		UpdatedFrom:  req.UpdatedFrom,
+		UpdatedTo:    req.UpdatedTo,
+	}
+
+	if req.ParentDepartmentID != nil && *req.ParentDepartmentID != "" {
+		parentID, err := uuid.Parse(*req.ParentDepartmentID)
+		if err != nil {
+			badRequest(c, "invalid_filter", requestError("parent_department_id", "filter.invalid_parent_department_id", nil,
+				"invalid parent department ID format"))
+			return filters, false
+		}
+		filters.ParentDepartmentID = &parentID
+	}
+
+	if req.RootOnly {
+		if (req.HasParent != nil && *req.HasParent) || filters.ParentDepartmentID != nil {
+			badRequest(c, "invalid_filter", requestError("root_only", "filter.root_only_conflict", nil,
+				"root_only cannot be combined with has_parent=true or parent_department_id"))
+			return filters, false
+		}
+		hasParent := false
+		filters.HasParent = &hasParent
+	}
+
+	if !filterRange(c, "min_depth", "max_depth", req.MinDepth, req.MaxDepth, cmp.Compare[int]) ||
+		!filterRange(c, "min_headcount", "max_headcount", req.MinHeadcount, req.MaxHeadcount, cmp.Compare[int64]) ||
+		!filterRange(c, "created_from", "created_to", req.CreatedFrom, req.CreatedTo, time.Time.Compare) ||
+		!filterRange(c, "updated_from", "updated_to", req.UpdatedFrom, req.UpdatedTo, time.Time.Compare) {
+		return filters, false
+	}
+	return filters, true
+}
+
+// filterRange reports a bad request and returns false when both bounds of a
+// range filter are set and the lower one is past the upper one
+func filterRange[T any](c *gin.Context, minField, maxField string, lower, upper *T, compare func(a, b T) int) bool {
+	if lower == nil || upper == nil || compare(*lower, *upper) <= 0 {
+		return true
+	}
+	badRequest(c, "invalid_filter", requestError(minField, "filter.invalid_range",
+		domainErrors.Params{"min": minField, "max": maxField},
+		fmt.Sprintf("%s cannot be greater than %s", minField, maxField)))
+	return false
+}
@@ -0,0 +1,278 @@
+package ginapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	domainErrors "api-employees-and-departments/internal/domain/errors"
+	"api-employees-and-departments/internal/infrastructure/logging"
+	"api-employees-and-departments/internal/interfaces/api/dto"
+	"api-employees-and-departments/internal/interfaces/api/i18n"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ErrorHandler renders the last error a handler attached with c.Error as an
+// RFC 9457 problem, so every endpoint answers the same status for the same kind
+// of failure: 400 malformed request, 404 not found, 409 conflict, 412 stale
+// version, 422 validation, 500 otherwise. Messages are localized from their error code according to the
+// Accept-Language header.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		last := c.Errors.Last()
+		problem := problemFromError(last, requestLanguage(c))
+		if problem.Status == http.StatusInternalServerError {
+			logging.Error("Unhandled error",
+				zap.Error(last.Err),
+				zap.String("path", c.Request.URL.Path),
+				zap.String("request_id", getRequestID(c)),
+			)
+		}
+		problem.Instance = getRequestID(c)
+		writeProblem(c, problem.Status, problem)
+	}
+}
+
+// writeProblem sends a problem document, which may extend dto.ProblemDetails
+func writeProblem(c *gin.Context, status int, problem any) {
+	c.Header("Content-Type", dto.ProblemContentType)
+	c.JSON(status, problem)
+}
+
+// requestLanguage negotiates the language of the error messages from the
+// Accept-Language header and announces it in the response
+func requestLanguage(c *gin.Context) i18n.Lang {
+	lang := i18n.FromAcceptLanguage(c.GetHeader("Accept-Language"))
+	c.Header("Content-Language", string(lang))
+	c.Writer.Header().Add("Vary", "Accept-Language")
+	return lang
+}
+
+// newProblem builds a problem with a localized title
+func newProblem(lang i18n.Lang, status int, code, detail string) dto.ProblemDetails {
+	problem := dto.NewProblem(status, code, detail)
+	problem.Title = i18n.Title(lang, status)
+	return problem
+}
+
+// problemFromError maps an error to its problem document
+func problemFromError(err *gin.Error, lang i18n.Lang) dto.ProblemDetails {
+	if err.IsType(gin.ErrorTypeBind) {
+		return problemFromBindError(err, lang)
+	}
+
+	var notFound *domainErrors.NotFoundError
+	var conflict *domainErrors.ConflictError
+	var validation *domainErrors.ValidationError
+	var precondition *domainErrors.PreconditionFailedError
+
+	switch {
+	case errors.As(err.Err, &notFound):
+		detail := i18n.Message(lang, notFound.Code, nil, notFound.Error())
+		return newProblem(lang, http.StatusNotFound, "not_found", detail)
+
+	case errors.As(err.Err, &conflict):
+		detail := i18n.Message(lang, conflict.Code, conflict.Params, conflict.Error())
+		problem := newProblem(lang, http.StatusConflict, "conflict", detail)
+		if conflict.Field != "" {
+			problem.Errors = []dto.FieldError{{Field: conflict.Field, Code: codeOr(conflict.Code, "conflict"), Message: detail}}
+		}
+		return problem
+
+	case errors.As(err.Err, &precondition):
+		detail := i18n.Message(lang, precondition.Code, nil, precondition.Error())
+		return newProblem(lang, http.StatusPreconditionFailed, "precondition_failed", detail)
+
+	case errors.As(err.Err, &validation):
+		detail := i18n.Message(lang, validation.Code, validation.Params, validation.Error())
+		problem := newProblem(lang, http.StatusUnprocessableEntity, "validation_error", detail)
+		if validation.Field != "" {
+			problem.Errors = []dto.FieldError{{Field: validation.Field, Code: codeOr(validation.Code, "invalid"), Message: detail}}
+		}
+		return problem
+	}
+
+	// Unexpected errors may carry driver details, so they are only logged
+	return newProblem(lang, http.StatusInternalServerError, "internal_server_error",
+		i18n.Message(lang, "internal_error", nil, "An unexpected error occurred"))
+}
+
+// fieldError describes a domain error attached to a single field, as reported
+// per row by bulk operations
+func fieldError(lang i18n.Lang, err error) dto.FieldError {
+	var conflict *domainErrors.ConflictError
+	var validation *domainErrors.ValidationError
+
+	switch {
+	case errors.As(err, &conflict):
+		return dto.FieldError{Field: conflict.Field, Code: codeOr(conflict.Code, "conflict"),
+			Message: i18n.Message(lang, conflict.Code, conflict.Params, conflict.Error())}
+	case errors.As(err, &validation):
+		return dto.FieldError{Field: validation.Field, Code: codeOr(validation.Code, "invalid"),
+			Message: i18n.Message(lang, validation.Code, validation.Params, validation.Error())}
+	}
+	return dto.FieldError{Code: "invalid", Message: err.Error()}
+}
+
+// codeOr returns code, or fallback for errors raised without one
+func codeOr(code, fallback string) string {
+	if code == "" {
+		return fallback
+	}
+	return code
+}
+
+// problemFromBindError turns request binding failures into field errors instead
+// of exposing the raw validator message. Failures of any other kind, such as a
+// query parameter that is not a number, get a generic detail, since their
+// messages are Go internals.
+func problemFromBindError(err *gin.Error, lang i18n.Lang) dto.ProblemDetails {
+	code, ok := err.Meta.(string)
+	if !ok {
+		code = "invalid_request"
+	}
+	problem := newProblem(lang, http.StatusBadRequest, code,
+		i18n.Message(lang, "request.invalid", nil, "The request is invalid"))
+
+	var validationErrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	var requestErr *domainErrors.ValidationError
+
+	switch {
+	case errors.As(err.Err, &validationErrs):
+		problem.Detail = i18n.Message(lang, "request.invalid_fields", nil, "The request has invalid fields")
+		for _, fe := range validationErrs {
+			problem.Errors = append(problem.Errors, dto.FieldError{
+				Field:   fe.Field(),
+				Code:    fe.Tag(),
+				Message: validationMessage(lang, fe),
+			})
+		}
+	case errors.As(err.Err, &typeErr):
+		problem.Detail = i18n.Message(lang, "request.invalid_fields", nil, "The request has invalid fields")
+		problem.Errors = []dto.FieldError{{
+			Field: typeErr.Field,
+			Code:  "type",
+			Message: i18n.Message(lang, "validation.type", map[string]any{"type": typeErr.Type},
+				fmt.Sprintf("must be of type %s", typeErr.Type)),
+		}}
+	case errors.As(err.Err, &syntaxErr), errors.Is(err.Err, io.ErrUnexpectedEOF):
+		problem.Detail = i18n.Message(lang, "request.invalid_json", nil, "The request body is not valid JSON")
+	case errors.Is(err.Err, io.EOF):
+		problem.Detail = i18n.Message(lang, "request.empty_body", nil, "The request body is empty")
+	case errors.As(err.Err, &requestErr):
+		problem.Detail = i18n.Message(lang, requestErr.Code, requestErr.Params, requestErr.Error())
+	}
+
+	return problem
+}
+
+// validationMessage describes a failed validator tag in plain words
+func validationMessage(lang i18n.Lang, fe validator.FieldError) string {
+	params := map[string]any{"param": fe.Param(), "tag": fe.Tag()}
+
+	switch fe.Tag() {
+	case "required", "uuid":
+		return i18n.Message(lang, "validation."+fe.Tag(), nil, fe.Error())
+	case "len", "min", "max":
+		code := "validation." + fe.Tag()
+		if fe.Kind() == reflect.String {
+			code += "_chars"
+		}
+		return i18n.Message(lang, code, params, fe.Error())
+	case "oneof":
+		params["param"] = strings.ReplaceAll(fe.Param(), " ", ", ")
+		return i18n.Message(lang, "validation.oneof", params, fe.Error())
+	}
+	return i18n.Message(lang, "validation.failed", params, fe.Error())
+}
+
+var registerFieldNamesOnce sync.Once
+
+// registerValidationFieldNames makes validator errors report the JSON (or query)
+// name of a field instead of the Go one
+func registerValidationFieldNames() {
+	registerFieldNamesOnce.Do(func() {
+		v, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			return
+		}
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			for _, tag := range []string{"json", "form"} {
+				name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+				if name == "-" {
+					return ""
+				}
+				if name != "" {
+					return name
+				}
+			}
+			return ""
+		})
+	})
+}
+
+// badRequest reports a malformed request; code becomes the problem type
+func badRequest(c *gin.Context, code string, err error) {
+	_ = c.Error(err).SetType(gin.ErrorTypeBind).SetMeta(code)
+}
+
+// mergePatchContentType is the media type of JSON Merge Patch documents (RFC 7396)
+const mergePatchContentType = "application/merge-patch+json"
+
+// requireMergePatch answers 415 unless the body is a merge patch. Plain JSON is
+// accepted as well, since a merge patch is just a JSON object.
+func requireMergePatch(c *gin.Context) bool {
+	switch c.ContentType() {
+	case mergePatchContentType, binding.MIMEJSON:
+		return true
+	}
+
+	unsupportedMediaType(c, mergePatchContentType)
+	return false
+}
+
+// unsupportedMediaType answers 415, naming the media type the endpoint expects
+func unsupportedMediaType(c *gin.Context, expected string) {
+	lang := requestLanguage(c)
+	problem := newProblem(lang, http.StatusUnsupportedMediaType, "unsupported_media_type",
+		i18n.Message(lang, "request.unsupported_media_type", map[string]any{"type": expected},
+			"Content-Type must be "+expected))
+	problem.Instance = getRequestID(c)
+	writeProblem(c, http.StatusUnsupportedMediaType, problem)
+}
+
+// requestError describes a malformed request with a catalog code, so it is
+// localized the same way as domain errors
+func requestError(field, code string, params domainErrors.Params, message string) error {
+	return &domainErrors.ValidationError{Field: field, Code: code, Params: params, Message: message}
+}
+
+// parseUUIDParam parses the :id path parameter, reporting a bad request when it
+// is not a UUID
+func parseUUIDParam(c *gin.Context, resource string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		badRequest(c, "invalid_id", requestError("id", "request.invalid_id",
+			domainErrors.Params{"resource": resource}, fmt.Sprintf("invalid %s ID format", resource)))
+		return uuid.Nil, false
+	}
+	return id, true
+}
@@ -0,0 +1,95 @@
+package ginapi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	domainErrors "api-employees-and-departments/internal/domain/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// etag formats a record version as a strong entity tag
+func etag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// setETag announces the current version of the record in the response
+func setETag(c *gin.Context, version int64) {
+	c.Header("ETag", etag(version))
+}
+
+// representationETag tags a response that shows more than its record, such as
+// a manager name or a department tree: the record version, which If-Match
+// checks, followed by a hash of the body, which changes whenever anything shown
+// does, in whatever representation was asked for
+func representationETag(version int64, body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + strconv.FormatInt(version, 10) + "-" + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// jsonWithETag writes body as JSON tagged with representationETag, or answers
+// 304 when If-None-Match already holds that tag
+func jsonWithETag(c *gin.Context, version int64, body any) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	current := representationETag(version, data)
+	c.Header("ETag", current)
+	if noneMatch(c, current) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", data)
+}
+
+// noneMatch reports whether If-None-Match holds the current tag
+func noneMatch(c *gin.Context, current string) bool {
+	header := c.GetHeader("If-None-Match")
+	if header == "" {
+		return false
+	}
+
+	// If-None-Match uses the weak comparison, so W/"3" matches "3"
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == current {
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatchVersion reads the version a write is conditioned on from If-Match,
+// either a version tag or the version part of a representationETag. It returns
+// 0 when the header is absent or "*", meaning any current version. A tag that is
+// not one of ours can never match, so it is reported as a failed precondition
+// and ok is false.
+func ifMatchVersion(c *gin.Context, resource string) (version int64, ok bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return 0, true
+	}
+
+	// If-Match uses the strong comparison, so weak tags never match
+	if len(header) > 2 && strings.HasPrefix(header, `"`) && strings.HasSuffix(header, `"`) {
+		value, _, _ := strings.Cut(header[1:len(header)-1], "-")
+		version, err := strconv.ParseInt(value, 10, 64)
+		if err == nil && version > 0 {
+			return version, true
+		}
+	}
+
+	_ = c.Error(&domainErrors.PreconditionFailedError{
+		Code:    resource + ".version_mismatch",
+		Message: resource + " was modified by another request",
+	})
+	return 0, false
+}
@@ -0,0 +1,74 @@
+package ginapi
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"api-employees-and-departments/internal/infrastructure/logging"
+	"api-employees-and-departments/internal/interfaces/api/export"
+	"api-employees-and-departments/internal/interfaces/api/i18n"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// exportFormat picks the file format of an export from ?format= or, when it is
+// absent, from the Accept header. It answers 406 when the client accepts
+// neither CSV nor XLSX.
+func exportFormat(c *gin.Context, requested string) (export.Format, bool) {
+	if requested != "" {
+		return export.Format(requested), true
+	}
+
+	negotiated := c.NegotiateFormat(export.CSVContentType, export.XLSXContentType)
+	if format, ok := export.FormatFromContentType(negotiated); ok {
+		return format, true
+	}
+
+	lang := requestLanguage(c)
+	types := export.CSVContentType + ", " + export.XLSXContentType
+	problem := newProblem(lang, http.StatusNotAcceptable, "not_acceptable",
+		i18n.Message(lang, "request.not_acceptable", map[string]any{"types": types},
+			"the response can only be sent as "+types))
+	problem.Instance = getRequestID(c)
+	writeProblem(c, http.StatusNotAcceptable, problem)
+	return "", false
+}
+
+// writeExport sends a spreadsheet named after name, writing header and then
+// every row passed by produce. CSV rows are streamed as they are read; once
+// they have started, a failure can only be logged and the download is cut short.
+func writeExport(c *gin.Context, format export.Format, name string, header []any, produce func(write func(values ...any) error) error) {
+	filename := fmt.Sprintf("%s-%s.%s", name, time.Now().UTC().Format("20060102"), format)
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+	writer, err := export.NewWriter(format, c.Writer, name)
+	if err == nil {
+		err = writer.Write(header...)
+	}
+	if err == nil {
+		err = produce(writer.Write)
+	}
+	if err == nil {
+		err = writer.Close()
+	}
+	if err == nil {
+		return
+	}
+
+	if c.Writer.Written() {
+		logging.Error("Export interrupted",
+			zap.Error(err),
+			zap.String("export", name),
+			zap.String("request_id", getRequestID(c)),
+		)
+		_ = c.Error(err)
+		c.Abort()
+		return
+	}
+
+	c.Writer.Header().Del("Content-Disposition")
+	_ = c.Error(err)
+}
@@ -1,13 +1,19 @@
 package ginapi
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 
 	"api-employees-and-departments/internal/domain/employee"
+	domainErrors "api-employees-and-departments/internal/domain/errors"
 	"api-employees-and-departments/internal/infrastructure/logging"
 	"api-employees-and-departments/internal/interfaces/api/dto"
+	"api-employees-and-departments/internal/interfaces/api/i18n"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
@@ -25,19 +31,23 @@ func NewEmployeeHandler(s *employee.Service) *EmployeeHandler {
 // @Tags employees
 // @Accept json
 // @Produce json
+// @Param cpf_format query string false "How CPFs are written; full CPFs need an X-API-Key with access to personal data" Enums(digits, formatted, masked)
 // @Success 200 {array} dto.EmployeeResponse
-// @Failure 500 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
 // @Router /employees [get]
 func (h *EmployeeHandler) GetAll(c *gin.Context) {
+	cpf, ok := cpfFormat(c)
+	if !ok {
+		return
+	}
+
 	employees, err := h.service.GetAllEmployees()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error:   "internal_server_error",
-			Message: err.Error(),
-		})
+		_ = c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, dto.ToEmployeeResponseList(employees))
+	c.JSON(http.StatusOK, dto.ToEmployeeResponseList(employees, cpf))
 }
 
 // GetByID godoc
@@ -46,33 +56,37 @@ func (h *EmployeeHandler) GetAll(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "Employee ID"
+// @Param If-None-Match header string false "ETag of a cached copy"
+// @Param cpf_format query string false "How CPFs are written; full CPFs need an X-API-Key with access to personal data" Enums(digits, formatted, masked)
 // @Success 200 {object} dto.EmployeeWithManagerResponse
-// @Failure 400 {object} dto.ErrorResponse
-// @Failure 404 {object} dto.ErrorResponse
+// @Success 304 "Not modified"
+// @Header 200 {string} ETag "Version of the employee and hash of the response"
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 403 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
 // @Router /employees/{id} [get]
 func (h *EmployeeHandler) GetByID(c *gin.Context) {
-	id, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "invalid_id",
-			Message: "Invalid employee ID format",
-		})
+	id, ok := parseUUIDParam(c, "employee")
+	if !ok {
+		return
+	}
+	cpf, ok := cpfFormat(c)
+	if !ok {
 		return
 	}
 
 	empWithManager, err := h.service.GetEmployeeWithManager(id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, dto.ErrorResponse{
-			Error:   "not_found",
-			Message: "Employee not found",
-		})
+		_ = c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.EmployeeWithManagerResponse{
+	// The manager name is not covered by the version, so the ETag hashes the body
+	jsonWithETag(c, empWithManager.Employee.Version, dto.EmployeeWithManagerResponse{
 		ID:           empWithManager.Employee.ID,
 		Name:         empWithManager.Employee.Name,
-		CPF:          empWithManager.Employee.CPF,
+		CPF:          cpf.Apply(empWithManager.Employee.CPF),
 		RG:           empWithManager.Employee.RG,
 		DepartmentID: empWithManager.Employee.DepartmentID,
 		ManagerName:  empWithManager.ManagerName,
@@ -81,15 +95,49 @@ func (h *EmployeeHandler) GetByID(c *gin.Context) {
 	})
 }
 
+// GetReportingChain godoc
+// @Summary Get the chain of command of an employee
+// @Description Returns the direct manager, that manager's manager and so on up to the top of the department tree
+// @Tags employees
+// @Accept json
+// @Produce json
+// @Param id path string true "Employee ID"
+// @Success 200 {object} dto.ReportingChainResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /employees/{id}/reporting-chain [get]
+func (h *EmployeeHandler) GetReportingChain(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "employee")
+	if !ok {
+		return
+	}
+
+	chain, err := h.service.GetReportingChain(id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToReportingChainResponse(id, chain))
+}
+
 // Create godoc
 // @Summary Create a new employee
 // @Tags employees
 // @Accept json
 // @Produce json
+// @Description If the CPF belongs to a soft-deleted employee, rehire=true restores that record with the new data and rehire=false creates a new one. Without rehire the request is refused with the previous record ID.
 // @Param employee body dto.CreateEmployeeRequest true "Employee data"
+// @Param rehire query bool false "Restore (true) or ignore (false) a deleted employee with the same CPF"
+// @Param cpf_format query string false "How CPFs are written; full CPFs need an X-API-Key with access to personal data" Enums(digits, formatted, masked)
 // @Success 201 {object} dto.EmployeeResponse
-// @Failure 400 {object} dto.ErrorResponse
-// @Failure 500 {object} dto.ErrorResponse
+// @Success 200 {object} dto.EmployeeResponse "Previous record restored"
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 403 {object} dto.ProblemDetails
+// @Failure 409 {object} dto.RehireConflictProblem
+// @Failure 422 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
 // @Router /employees [post]
 func (h *EmployeeHandler) Create(c *gin.Context) {
 	var req dto.CreateEmployeeRequest
@@ -98,35 +146,179 @@ func (h *EmployeeHandler) Create(c *gin.Context) {
 			zap.Error(err),
 			zap.String("request_id", getRequestID(c)),
 		)
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "validation_error",
-			Message: err.Error(),
-		})
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
 		return
 	}
 
+	var query dto.CreateEmployeeQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+	cpf, ok := cpfFormat(c)
+	if !ok {
+		return
+	}
+
+	policy := employee.RehireOffer
+	if query.Rehire != nil {
+		policy = employee.RehireIgnore
+		if *query.Rehire {
+			policy = employee.RehireRestore
+		}
+	}
+
 	emp := dto.ToEmployeeEntity(&req)
-	if err := h.service.CreateEmployee(emp); err != nil {
+	restored, err := h.service.CreateEmployeeWithPolicy(emp, policy)
+	if err != nil {
+		var previous *employee.PreviousRecordError
+		if errors.As(err, &previous) {
+			lang := requestLanguage(c)
+			problem := newProblem(lang, http.StatusConflict, "previous_record_exists",
+				i18n.Message(lang, "employee.previous_record_exists",
+					map[string]any{"previous_id": previous.PreviousID}, previous.Error()))
+			problem.Instance = getRequestID(c)
+			writeProblem(c, http.StatusConflict, dto.RehireConflictProblem{
+				ProblemDetails:     problem,
+				PreviousEmployeeID: previous.PreviousID,
+				DeletedAt:          previous.DeletedAt,
+			})
+			return
+		}
+
 		logging.Error("Failed to create employee",
 			zap.Error(err),
 			zap.String("name", req.Name),
-			zap.String("cpf", req.CPF),
 			zap.String("request_id", getRequestID(c)),
 		)
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error:   "creation_failed",
-			Message: err.Error(),
-		})
+		_ = c.Error(err)
 		return
 	}
 
 	logging.Info("Employee created successfully",
 		zap.String("employee_id", emp.ID.String()),
 		zap.String("name", emp.Name),
+		zap.Bool("rehired", restored),
+		zap.String("request_id", getRequestID(c)),
+	)
+
+	status := http.StatusCreated
+	if restored {
+		status = http.StatusOK
+	}
+	c.JSON(status, dto.ToEmployeeResponse(emp, cpf))
+}
+
+// maxImportSize bounds the body of an import request
+const maxImportSize = 10 << 20
+
+// Import godoc
+// @Summary Import employees from a CSV file
+// @Description The header names the columns name, cpf, rg (optional) and department (ID or name), separated by commas or semicolons. Every row is validated like a single creation. By default nothing is written if any row is invalid; atomic=false creates the valid rows anyway and dry_run=true only validates. The file is sent as text/csv or as the "file" field of a multipart form.
+// @Tags employees
+// @Accept text/csv
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file false "CSV file"
+// @Param dry_run query bool false "Only validate the rows"
+// @Param atomic query bool false "All-or-nothing import (default true)"
+// @Param cpf_format query string false "How CPFs are written; full CPFs need an X-API-Key with access to personal data" Enums(digits, formatted, masked)
+// @Success 201 {object} dto.ImportEmployeesResponse
+// @Success 200 {object} dto.ImportEmployeesResponse "Dry run"
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 403 {object} dto.ProblemDetails
+// @Failure 415 {object} dto.ProblemDetails
+// @Failure 422 {object} dto.ImportRejectedProblem
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /employees/import [post]
+func (h *EmployeeHandler) Import(c *gin.Context) {
+	var query dto.ImportEmployeesQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+	cpf, ok := cpfFormat(c)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+
+	var body io.Reader
+	switch c.ContentType() {
+	case dto.ImportContentType, "application/csv":
+		body = c.Request.Body
+	case binding.MIMEMultipartPOSTForm:
+		file, err := c.FormFile("file")
+		if err != nil {
+			if !errors.Is(err, http.ErrMissingFile) {
+				badRequest(c, "invalid_file", importBodyError(err))
+				return
+			}
+			badRequest(c, "invalid_file", requestError("file", "import.file_required", nil, "the CSV must be sent in the file field"))
+			return
+		}
+		opened, err := file.Open()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		defer opened.Close()
+		body = opened
+	default:
+		unsupportedMediaType(c, dto.ImportContentType)
+		return
+	}
+
+	rows, err := dto.ParseEmployeeImportCSV(body)
+	if err != nil {
+		badRequest(c, "invalid_csv", importBodyError(err))
+		return
+	}
+
+	opts := employee.ImportOptions{DryRun: query.DryRun, Partial: query.Atomic != nil && !*query.Atomic}
+	report, err := h.service.ImportEmployees(rows, opts)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	logging.Info("Employee import processed",
+		zap.Int("rows", len(rows)),
+		zap.Int("created", report.Created),
+		zap.Int("invalid", report.Invalid),
+		zap.Bool("dry_run", opts.DryRun),
 		zap.String("request_id", getRequestID(c)),
 	)
 
-	c.JSON(http.StatusCreated, dto.ToEmployeeResponse(emp))
+	lang := requestLanguage(c)
+	response := dto.ToImportEmployeesResponse(report, opts.DryRun, cpf, func(err error) dto.FieldError {
+		return fieldError(lang, err)
+	})
+
+	switch {
+	case opts.DryRun:
+		c.JSON(http.StatusOK, response)
+	case report.Created == 0:
+		problem := newProblem(lang, http.StatusUnprocessableEntity, "import_rejected",
+			i18n.Message(lang, "import.rejected", map[string]any{"invalid": report.Invalid},
+				fmt.Sprintf("%d rows are invalid; no employee was imported", report.Invalid)))
+		problem.Instance = getRequestID(c)
+		writeProblem(c, http.StatusUnprocessableEntity, dto.ImportRejectedProblem{ProblemDetails: problem, Report: response})
+	default:
+		c.JSON(http.StatusCreated, response)
+	}
+}
+
+// importBodyError describes a failure to read an import file, which is usually
+// the body going over maxImportSize
+func importBodyError(err error) error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return requestError("file", "import.too_large", domainErrors.Params{"max": maxImportSize},
+			fmt.Sprintf("the file is larger than %d bytes", maxImportSize))
+	}
+	return err
 }
 
 // Update godoc
@@ -136,41 +328,48 @@ func (h *EmployeeHandler) Create(c *gin.Context) {
 // @Produce json
 // @Param id path string true "Employee ID"
 // @Param employee body dto.UpdateEmployeeRequest true "Employee data"
+// @Param If-Match header string false "ETag the change is based on"
+// @Param cpf_format query string false "How CPFs are written; full CPFs need an X-API-Key with access to personal data" Enums(digits, formatted, masked)
 // @Success 200 {object} dto.EmployeeResponse
-// @Failure 400 {object} dto.ErrorResponse
-// @Failure 404 {object} dto.ErrorResponse
-// @Failure 500 {object} dto.ErrorResponse
+// @Header 200 {string} ETag "New version of the employee"
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 403 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 409 {object} dto.ProblemDetails
+// @Failure 412 {object} dto.ProblemDetails
+// @Failure 422 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
 // @Router /employees/{id} [put]
 func (h *EmployeeHandler) Update(c *gin.Context) {
-	id, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "invalid_id",
-			Message: "Invalid employee ID format",
-		})
+	id, ok := parseUUIDParam(c, "employee")
+	if !ok {
 		return
 	}
 
 	var req dto.UpdateEmployeeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "validation_error",
-			Message: err.Error(),
-		})
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	version, ok := ifMatchVersion(c, "employee")
+	if !ok {
+		return
+	}
+	cpf, ok := cpfFormat(c)
+	if !ok {
 		return
 	}
 
 	emp := dto.ToEmployeeEntityFromUpdate(&req)
+	emp.Version = version
 	if err := h.service.UpdateEmployee(id, emp); err != nil {
 		logging.Error("Failed to update employee",
 			zap.Error(err),
 			zap.String("employee_id", id.String()),
 			zap.String("request_id", getRequestID(c)),
 		)
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error:   "update_failed",
-			Message: err.Error(),
-		})
+		_ = c.Error(err)
 		return
 	}
 
@@ -179,7 +378,75 @@ func (h *EmployeeHandler) Update(c *gin.Context) {
 		zap.String("request_id", getRequestID(c)),
 	)
 
-	c.JSON(http.StatusOK, dto.ToEmployeeResponse(emp))
+	setETag(c, emp.Version)
+	c.JSON(http.StatusOK, dto.ToEmployeeResponse(emp, cpf))
+}
+
+// Patch godoc
+// @Summary Partially update an employee
+// @Description JSON Merge Patch (RFC 7396): only the fields sent are validated and written, and null clears rg
+// @Tags employees
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param id path string true "Employee ID"
+// @Param employee body dto.PatchEmployeeRequest true "Fields to change"
+// @Param If-Match header string false "ETag the change is based on"
+// @Param cpf_format query string false "How CPFs are written; full CPFs need an X-API-Key with access to personal data" Enums(digits, formatted, masked)
+// @Success 200 {object} dto.EmployeeResponse
+// @Header 200 {string} ETag "New version of the employee"
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 403 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 409 {object} dto.ProblemDetails
+// @Failure 412 {object} dto.ProblemDetails
+// @Failure 415 {object} dto.ProblemDetails
+// @Failure 422 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /employees/{id} [patch]
+func (h *EmployeeHandler) Patch(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "employee")
+	if !ok {
+		return
+	}
+	if !requireMergePatch(c) {
+		return
+	}
+
+	var req dto.PatchEmployeeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	version, ok := ifMatchVersion(c, "employee")
+	if !ok {
+		return
+	}
+	cpf, ok := cpfFormat(c)
+	if !ok {
+		return
+	}
+
+	patch := dto.ToEmployeePatch(&req)
+	patch.Version = version
+	emp, err := h.service.PatchEmployee(id, patch)
+	if err != nil {
+		logging.Error("Failed to patch employee",
+			zap.Error(err),
+			zap.String("employee_id", id.String()),
+			zap.String("request_id", getRequestID(c)),
+		)
+		_ = c.Error(err)
+		return
+	}
+
+	logging.Info("Employee patched successfully",
+		zap.String("employee_id", id.String()),
+		zap.String("request_id", getRequestID(c)),
+	)
+
+	setETag(c, emp.Version)
+	c.JSON(http.StatusOK, dto.ToEmployeeResponse(emp, cpf))
 }
 
 // Delete godoc
@@ -188,31 +455,31 @@ func (h *EmployeeHandler) Update(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "Employee ID"
+// @Param If-Match header string false "ETag the deletion is based on"
 // @Success 204
-// @Failure 400 {object} dto.ErrorResponse
-// @Failure 404 {object} dto.ErrorResponse
-// @Failure 500 {object} dto.ErrorResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 412 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
 // @Router /employees/{id} [delete]
 func (h *EmployeeHandler) Delete(c *gin.Context) {
-	id, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "invalid_id",
-			Message: "Invalid employee ID format",
-		})
+	id, ok := parseUUIDParam(c, "employee")
+	if !ok {
 		return
 	}
 
-	if err := h.service.DeleteEmployee(id); err != nil {
+	version, ok := ifMatchVersion(c, "employee")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteEmployee(id, version); err != nil {
 		logging.Error("Failed to delete employee",
 			zap.Error(err),
 			zap.String("employee_id", id.String()),
 			zap.String("request_id", getRequestID(c)),
 		)
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error:   "deletion_failed",
-			Message: err.Error(),
-		})
+		_ = c.Error(err)
 		return
 	}
 
@@ -224,31 +491,229 @@ func (h *EmployeeHandler) Delete(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// ListDeleted godoc
+// @Summary List soft-deleted employees
+// @Tags employees
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Param cpf_format query string false "How CPFs are written; full CPFs need an X-API-Key with access to personal data" Enums(digits, formatted, masked)
+// @Success 200 {object} dto.PaginatedResponse{data=[]dto.EmployeeResponse}
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 403 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /employees/deleted [get]
+func (h *EmployeeHandler) ListDeleted(c *gin.Context) {
+	var req dto.ListDeletedRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+	cpf, ok := cpfFormat(c)
+	if !ok {
+		return
+	}
+
+	employees, total, err := h.service.ListDeletedEmployees(req.Page, req.PageSize)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	// Calculate total pages
+	totalPages := int(total) / req.PageSize
+	if int(total)%req.PageSize != 0 {
+		totalPages++
+	}
+
+	c.JSON(http.StatusOK, dto.PaginatedResponse{
+		Data:       dto.ToEmployeeResponseList(employees, cpf),
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+		Total:      total,
+		TotalPages: totalPages,
+	})
+}
+
+// Restore godoc
+// @Summary Restore a soft-deleted employee
+// @Description Re-runs the domain validations before undeleting the record
+// @Tags employees
+// @Accept json
+// @Produce json
+// @Param id path string true "Employee ID"
+// @Param cpf_format query string false "How CPFs are written; full CPFs need an X-API-Key with access to personal data" Enums(digits, formatted, masked)
+// @Success 200 {object} dto.EmployeeResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 403 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 409 {object} dto.ProblemDetails
+// @Failure 422 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /employees/{id}/restore [post]
+func (h *EmployeeHandler) Restore(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "employee")
+	if !ok {
+		return
+	}
+
+	cpf, ok := cpfFormat(c)
+	if !ok {
+		return
+	}
+
+	emp, err := h.service.RestoreEmployee(id)
+	if err != nil {
+		logging.Error("Failed to restore employee",
+			zap.Error(err),
+			zap.String("employee_id", id.String()),
+			zap.String("request_id", getRequestID(c)),
+		)
+		_ = c.Error(err)
+		return
+	}
+
+	logging.Info("Employee restored successfully",
+		zap.String("employee_id", id.String()),
+		zap.String("request_id", getRequestID(c)),
+	)
+
+	c.JSON(http.StatusOK, dto.ToEmployeeResponse(emp, cpf))
+}
+
+// Purge godoc
+// @Summary Permanently remove a soft-deleted employee
+// @Tags employees
+// @Accept json
+// @Produce json
+// @Param id path string true "Employee ID"
+// @Success 204
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 409 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /employees/{id}/purge [delete]
+func (h *EmployeeHandler) Purge(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "employee")
+	if !ok {
+		return
+	}
+
+	if err := h.service.PurgeEmployee(id); err != nil {
+		logging.Error("Failed to purge employee",
+			zap.Error(err),
+			zap.String("employee_id", id.String()),
+			zap.String("request_id", getRequestID(c)),
+		)
+		_ = c.Error(err)
+		return
+	}
+
+	logging.Info("Employee purged successfully",
+		zap.String("employee_id", id.String()),
+		zap.String("request_id", getRequestID(c)),
+	)
+
+	c.Status(http.StatusNoContent)
+}
+
+// Export godoc
+// @Summary Export employees as CSV or XLSX
+// @Description Exports every employee matching the filters of /employees/list, with the department and manager names. The format comes from the format parameter or else from the Accept header.
+// @Tags employees
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param name query string false "Name contains"
+// @Param cpf query string false "CPF; needs an X-API-Key with access to personal data"
+// @Param rg query string false "RG; needs an X-API-Key with access to personal data"
+// @Param department_id query string false "Department ID"
+// @Param format query string false "File format" Enums(csv, xlsx)
+// @Param cpf_format query string false "How CPFs are written; full CPFs need an X-API-Key with access to personal data" Enums(digits, formatted, masked)
+// @Success 200 {file} file
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 403 {object} dto.ProblemDetails
+// @Failure 406 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /employees/export [get]
+func (h *EmployeeHandler) Export(c *gin.Context) {
+	var req dto.ExportEmployeesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+	if !allowDocumentFilters(c, req.CPF, req.RG) {
+		return
+	}
+
+	filters := employee.ListFilters{Name: req.Name, CPF: normalizeCPFFilter(req.CPF), RG: req.RG}
+	if req.DepartmentID != nil && *req.DepartmentID != "" {
+		deptID, err := uuid.Parse(*req.DepartmentID)
+		if err != nil {
+			badRequest(c, "invalid_filter", requestError("department_id", "filter.invalid_department_id", nil,
+				"invalid department ID format"))
+			return
+		}
+		filters.DepartmentID = &deptID
+	}
+
+	cpf, ok := cpfFormat(c)
+	if !ok {
+		return
+	}
+	format, ok := exportFormat(c, req.Format)
+	if !ok {
+		return
+	}
+
+	writeExport(c, format, "employees", dto.EmployeeExportHeader, func(write func(values ...any) error) error {
+		return h.service.ExportEmployees(filters, func(row employee.ExportRow) error {
+			return write(dto.ToEmployeeExportRecord(row, cpf)...)
+		})
+	})
+}
+
 // List godoc
 // @Summary List employees with filters and pagination
+// @Description Pages with page/page_size, or with keyset pagination when after, before or limit is sent. Keyset pages follow creation order, answer with dto.CursorResponse and only count the total with include_total. The cpf and rg filters need an X-API-Key with access to personal data.
 // @Tags employees
 // @Accept json
 // @Produce json
 // @Param filters body dto.ListEmployeesRequest true "Filter and pagination params"
+// @Param cpf_format query string false "How CPFs are written; full CPFs need an X-API-Key with access to personal data" Enums(digits, formatted, masked)
 // @Success 200 {object} dto.PaginatedResponse
-// @Failure 400 {object} dto.ErrorResponse
-// @Failure 500 {object} dto.ErrorResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 403 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
 // @Router /employees/list [post]
 func (h *EmployeeHandler) List(c *gin.Context) {
 	var req dto.ListEmployeesRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "validation_error",
-			Message: err.Error(),
-		})
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+	if !allowDocumentFilters(c, req.CPF, req.RG) {
+		return
+	}
+	sort, ok := listSort(c, req.Sort, employee.SortFields)
+	if !ok {
+		return
+	}
+	keyset, useCursor, ok := listKeyset(c, req.Page, req.PageSize, req.CursorRequest, sort)
+	if !ok {
+		return
+	}
+	cpf, ok := cpfFormat(c)
+	if !ok {
 		return
 	}
 
 	// Build filters
 	filters := employee.ListFilters{
 		Name:     req.Name,
-		CPF:      req.CPF,
+		CPF:      normalizeCPFFilter(req.CPF),
 		RG:       req.RG,
+		Sort:     sort,
 		Page:     req.Page,
 		PageSize: req.PageSize,
 	}
@@ -257,21 +722,26 @@ func (h *EmployeeHandler) List(c *gin.Context) {
 	if req.DepartmentID != nil && *req.DepartmentID != "" {
 		deptID, err := uuid.Parse(*req.DepartmentID)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-				Error:   "invalid_filter",
-				Message: "Invalid department ID format",
-			})
+			badRequest(c, "invalid_filter", requestError("department_id", "filter.invalid_department_id", nil,
+				"invalid department ID format"))
 			return
 		}
 		filters.DepartmentID = &deptID
 	}
 
+	if useCursor {
+		employees, info, err := h.service.ListEmployeesPage(filters, keyset)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, dto.NewCursorResponse(dto.ToEmployeeResponseList(employees, cpf), sort, keyset, info))
+		return
+	}
+
 	employees, total, err := h.service.ListEmployees(filters)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error:   "list_failed",
-			Message: err.Error(),
-		})
+		_ = c.Error(err)
 		return
 	}
 
@@ -282,7 +752,7 @@ func (h *EmployeeHandler) List(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, dto.PaginatedResponse{
-		Data:       dto.ToEmployeeResponseList(employees),
+		Data:       dto.ToEmployeeResponseList(employees, cpf),
 		Page:       req.Page,
 		PageSize:   req.PageSize,
 		Total:      total,
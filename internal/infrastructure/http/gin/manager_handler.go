@@ -3,7 +3,6 @@ package ginapi
 import (
 	"net/http"
 
-	"api-employees-and-departments/internal/domain/department"
 	"api-employees-and-departments/internal/domain/employee"
 	"api-employees-and-departments/internal/interfaces/api/dto"
 
@@ -12,110 +11,87 @@ import (
 )
 
 type ManagerHandler struct {
-	departmentService *department.Service
-	employeeService   *employee.Service
+	employeeService *employee.Service
 }
 
-func NewManagerHandler(deptService *department.Service, empService *employee.Service) *ManagerHandler {
+func NewManagerHandler(empService *employee.Service) *ManagerHandler {
 	return &ManagerHandler{
-		departmentService: deptService,
-		employeeService:   empService,
+		employeeService: empService,
 	}
 }
 
 // GetSubordinateEmployees godoc
 // @Summary Get all employees subordinate to a manager (recursive)
+// @Description Employees of every department managed by the manager and of their sub-departments. Direct reports are flagged with direct=true.
 // @Tags managers
 // @Accept json
 // @Produce json
 // @Param id path string true "Manager ID (Employee ID)"
-// @Success 200 {array} dto.EmployeeResponse
-// @Failure 400 {object} dto.ErrorResponse
-// @Failure 404 {object} dto.ErrorResponse
-// @Failure 500 {object} dto.ErrorResponse
+// @Param name query string false "Filter by employee name (partial match)"
+// @Param department_id query string false "Filter by department ID"
+// @Param direct_only query bool false "Only direct reports"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Param cpf_format query string false "How CPFs are written; full CPFs need an X-API-Key with access to personal data" Enums(digits, formatted, masked)
+// @Success 200 {object} dto.PaginatedResponse{data=[]dto.SubordinateResponse}
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 403 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
 // @Router /managers/{id}/employees [get]
 func (h *ManagerHandler) GetSubordinateEmployees(c *gin.Context) {
-	managerID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "invalid_id",
-			Message: "Invalid manager ID format",
-		})
+	managerID, ok := parseUUIDParam(c, "manager")
+	if !ok {
 		return
 	}
 
-	// Verify that the manager exists
-	_, err = h.employeeService.GetEmployeeByID(managerID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, dto.ErrorResponse{
-			Error:   "not_found",
-			Message: "Manager not found",
-		})
+	var req dto.ListSubordinatesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
 		return
 	}
-
-	// Get all department IDs managed by this manager (recursively)
-	departmentIDs, err := h.getAllManagedDepartmentIDs(managerID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error:   "internal_error",
-			Message: err.Error(),
-		})
+	cpf, ok := cpfFormat(c)
+	if !ok {
 		return
 	}
 
-	// Get all employees from these departments
-	employees, err := h.employeeService.GetEmployeesByDepartmentIDs(departmentIDs)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error:   "internal_error",
-			Message: err.Error(),
-		})
-		return
-	}
-
-	c.JSON(http.StatusOK, dto.ToEmployeeResponseList(employees))
-}
-
-func (h *ManagerHandler) getAllManagedDepartmentIDs(managerID uuid.UUID) ([]uuid.UUID, error) {
-	// Find all departments where this employee is the manager
-	departments, err := h.departmentService.GetDepartmentsByManagerID(managerID)
-	if err != nil {
-		return nil, err
+	// Build filters
+	filters := employee.SubordinateFilters{
+		Name:       req.Name,
+		DirectOnly: req.DirectOnly,
+		Page:       req.Page,
+		PageSize:   req.PageSize,
 	}
 
-	var result []uuid.UUID
-	for _, dept := range departments {
-		result = append(result, dept.ID)
-
-		// Recursively get subdepartments
-		subdeptIDs, err := h.getAllSubdepartmentIDs(dept.ID)
+	// Parse department ID if provided
+	if req.DepartmentID != nil && *req.DepartmentID != "" {
+		deptID, err := uuid.Parse(*req.DepartmentID)
 		if err != nil {
-			return nil, err
+			badRequest(c, "invalid_filter", requestError("department_id", "filter.invalid_department_id", nil,
+				"invalid department ID format"))
+			return
 		}
-		result = append(result, subdeptIDs...)
+		filters.DepartmentID = &deptID
 	}
 
-	return result, nil
-}
-
-func (h *ManagerHandler) getAllSubdepartmentIDs(parentID uuid.UUID) ([]uuid.UUID, error) {
-	children, err := h.departmentService.GetDepartmentsByParentID(parentID)
+	// The service reports a missing manager as not found
+	subordinates, total, err := h.employeeService.ListSubordinates(managerID, filters)
 	if err != nil {
-		return nil, err
+		_ = c.Error(err)
+		return
 	}
 
-	var result []uuid.UUID
-	for _, child := range children {
-		result = append(result, child.ID)
-
-		// Recursive call
-		subdeptIDs, err := h.getAllSubdepartmentIDs(child.ID)
-		if err != nil {
-			return nil, err
-		}
-		result = append(result, subdeptIDs...)
+	// Calculate total pages
+	totalPages := int(total) / req.PageSize
+	if int(total)%req.PageSize != 0 {
+		totalPages++
 	}
 
-	return result, nil
+	c.JSON(http.StatusOK, dto.PaginatedResponse{
+		Data:       dto.ToSubordinateResponseList(subordinates, cpf),
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+		Total:      total,
+		TotalPages: totalPages,
+	})
 }
@@ -1,12 +1,13 @@
 package ginapi
 
 import (
+	"crypto/subtle"
 	"fmt"
 	"net/http"
 	"time"
 
 	"api-employees-and-departments/internal/infrastructure/logging"
-	"api-employees-and-departments/internal/interfaces/api/dto"
+	"api-employees-and-departments/internal/interfaces/api/i18n"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -17,7 +18,8 @@ func CORSMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, If-Match, If-None-Match, Accept-Language, X-API-Key")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "ETag, Content-Language, Content-Disposition, X-Request-ID")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE, PATCH")
 
 		if c.Request.Method == "OPTIONS" {
@@ -110,10 +112,12 @@ func Recovery() gin.HandlerFunc {
 				logging.Error("Panic recovered", fields...)
 
 				// Return error response
-				c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-					Error:   "internal_server_error",
-					Message: fmt.Sprintf("An unexpected error occurred: %v", err),
-				})
+				// The panic value is only logged, like other unexpected errors
+				lang := requestLanguage(c)
+				problem := newProblem(lang, http.StatusInternalServerError, "internal_server_error",
+					i18n.Message(lang, "internal_error", nil, "An unexpected error occurred"))
+				problem.Instance = getRequestID(c)
+				writeProblem(c, http.StatusInternalServerError, problem)
 
 				c.Abort()
 			}
@@ -135,3 +139,25 @@ func RequestID() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// apiKeyHeader carries the key that identifies a caller
+const apiKeyHeader = "X-API-Key"
+
+// piiAccessKey is set in the context of requests allowed to see personal data
+const piiAccessKey = "PIIAccess"
+
+// PIIAccess middleware allows the requests whose X-API-Key is one of keys to
+// see personal data, such as CPFs, in full
+func PIIAccess(keys []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if key := c.GetHeader(apiKeyHeader); key != "" {
+			for _, allowed := range keys {
+				if subtle.ConstantTimeCompare([]byte(key), []byte(allowed)) == 1 {
+					c.Set(piiAccessKey, true)
+					break
+				}
+			}
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,74 @@
+package ginapi
+
+import (
+	"fmt"
+	"strings"
+
+	domainErrors "api-employees-and-departments/internal/domain/errors"
+	"api-employees-and-departments/internal/domain/pagination"
+	"api-employees-and-departments/internal/interfaces/api/dto"
+
+	"github.com/gin-gonic/gin"
+)
+
+// listSort parses the sort of a listing, reporting a bad request and returning
+// ok false when it names a field that is not in allowed
+func listSort(c *gin.Context, value string, allowed []string) ([]pagination.SortField, bool) {
+	sort, invalid := dto.ParseSort(value, allowed)
+	if invalid != "" {
+		fields := strings.Join(allowed, ", ")
+		badRequest(c, "validation_error", requestError("sort", "request.invalid_sort",
+			domainErrors.Params{"value": invalid, "allowed": fields},
+			fmt.Sprintf("cannot sort by %s; use each of %s at most once, prefixed with - for descending order", invalid, fields)))
+		return nil, false
+	}
+	return sort, true
+}
+
+// listKeyset picks the pagination mode of a listing. Keyset pagination is used
+// when any cursor field is sent, and then page/page_size must be absent;
+// otherwise both of them are required. Cursors must come from a listing with the
+// same sort. It reports a bad request and returns ok false when the parameters
+// do not fit either mode.
+func listKeyset(c *gin.Context, page, pageSize int, req dto.CursorRequest, sort []pagination.SortField) (keyset pagination.Keyset, useCursor, ok bool) {
+	if !req.UsesCursor() {
+		if page == 0 || pageSize == 0 {
+			badRequest(c, "validation_error", requestError("page", "pagination.page_required", nil,
+				"page and page_size are required unless after, before or limit is sent"))
+			return keyset, false, false
+		}
+		return keyset, false, true
+	}
+
+	if page != 0 || pageSize != 0 {
+		badRequest(c, "validation_error", requestError("page", "pagination.mixed_modes", nil,
+			"page/page_size cannot be combined with after, before or limit"))
+		return keyset, true, false
+	}
+	if req.After != nil && req.Before != nil {
+		badRequest(c, "validation_error", requestError("before", "pagination.after_and_before", nil,
+			"after and before cannot be sent together"))
+		return keyset, true, false
+	}
+
+	keyset = pagination.Keyset{Limit: req.Limit, WithTotal: req.IncludeTotal}
+	if keyset.Limit == 0 {
+		keyset.Limit = dto.DefaultCursorLimit
+	}
+
+	field, cursor := "after", req.After
+	if req.Before != nil {
+		field, cursor = "before", req.Before
+		keyset.Backward = true
+	}
+	if cursor != nil {
+		decoded, err := dto.DecodeCursor(*cursor, sort)
+		if err != nil {
+			badRequest(c, "validation_error", requestError(field, "pagination.invalid_cursor", nil,
+				"invalid cursor, or cursor of a listing with another sort"))
+			return keyset, true, false
+		}
+		keyset.Cursor = &decoded
+	}
+	return keyset, true, true
+}
@@ -0,0 +1,117 @@
+package ginapi
+
+import (
+	"net/http"
+
+	"api-employees-and-departments/internal/infrastructure/logging"
+	"api-employees-and-departments/internal/interfaces/api/dto"
+	"api-employees-and-departments/internal/interfaces/api/i18n"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// requirePIIAccess refuses the request with a 403 unless its API key has access
+// to personal data, and reports whether it may go on
+func requirePIIAccess(c *gin.Context) bool {
+	c.Writer.Header().Add("Vary", apiKeyHeader)
+	if c.GetBool(piiAccessKey) {
+		return true
+	}
+
+	lang := requestLanguage(c)
+	problem := newProblem(lang, http.StatusForbidden, "forbidden",
+		i18n.Message(lang, "pii.access_required", nil,
+			"this operation needs an API key with access to personal data"))
+	problem.Instance = getRequestID(c)
+	writeProblem(c, http.StatusForbidden, problem)
+	return false
+}
+
+// DataExport godoc
+// @Summary Export everything held about an employee
+// @Description Data subject access request (LGPD): the employee record, deleted or not, the departments it belonged to and manages, and its change history. Needs an X-API-Key with access to personal data; the export is recorded in the history.
+// @Tags employees
+// @Produce json
+// @Param id path string true "Employee ID"
+// @Param cpf_format query string false "How CPFs are written" Enums(digits, formatted, masked)
+// @Success 200 {object} dto.EmployeeDataExportResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 403 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /employees/{id}/data-export [get]
+func (h *EmployeeHandler) DataExport(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "employee")
+	if !ok {
+		return
+	}
+	if !requirePIIAccess(c) {
+		return
+	}
+	cpf, ok := cpfFormat(c)
+	if !ok {
+		return
+	}
+
+	export, err := h.service.ExportEmployeeData(id)
+	if err != nil {
+		logging.Error("Failed to export employee data",
+			zap.Error(err),
+			zap.String("employee_id", id.String()),
+			zap.String("request_id", getRequestID(c)),
+		)
+		_ = c.Error(err)
+		return
+	}
+
+	logging.Info("Employee data exported",
+		zap.String("employee_id", id.String()),
+		zap.String("request_id", getRequestID(c)),
+	)
+
+	// Personal data must not be kept by shared caches
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, dto.ToEmployeeDataExportResponse(export, cpf))
+}
+
+// Anonymize godoc
+// @Summary Anonymize a deleted employee
+// @Description Irreversibly replaces the name and erases the CPF and RG of a soft-deleted employee whose retention period is over. The record is kept, still deleted, so the departments and reports referring to it stay consistent. Needs an X-API-Key with access to personal data.
+// @Tags employees
+// @Produce json
+// @Param id path string true "Employee ID"
+// @Success 200 {object} dto.EmployeeResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 403 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 409 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /employees/{id}/anonymize [post]
+func (h *EmployeeHandler) Anonymize(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "employee")
+	if !ok {
+		return
+	}
+	if !requirePIIAccess(c) {
+		return
+	}
+
+	emp, err := h.service.AnonymizeEmployee(id)
+	if err != nil {
+		logging.Error("Failed to anonymize employee",
+			zap.Error(err),
+			zap.String("employee_id", id.String()),
+			zap.String("request_id", getRequestID(c)),
+		)
+		_ = c.Error(err)
+		return
+	}
+
+	logging.Info("Employee anonymized",
+		zap.String("employee_id", id.String()),
+		zap.String("request_id", getRequestID(c)),
+	)
+
+	c.JSON(http.StatusOK, dto.ToEmployeeResponse(emp, dto.CPFDigits))
+}
@@ -8,26 +8,33 @@ import (
 )
 
 type RouterConfig struct {
-	EmployeeHandler    *EmployeeHandler
-	DepartmentHandler  *DepartmentHandler
-	ManagerHandler     *ManagerHandler
+	EmployeeHandler   *EmployeeHandler
+	DepartmentHandler *DepartmentHandler
+	ManagerHandler    *ManagerHandler
+	SearchHandler     *SearchHandler
+	// PIIAPIKeys are the X-API-Key values allowed to see personal data in full
+	PIIAPIKeys []string
 }
 
 // SetupRoutes configures all API routes
 func SetupRoutes(router *gin.Engine, config *RouterConfig) {
+	registerValidationFieldNames()
+
 	p := ginprometheus.NewPrometheus("gin")
 	p.Use(router)
 
 	// Global middlewares
 	router.Use(CORSMiddleware())
 	router.Use(RequestID())
+	router.Use(PIIAccess(config.PIIAPIKeys))
 	router.Use(Logger())
 	router.Use(Recovery())
+	router.Use(ErrorHandler())
 
 	// Health check endpoint
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{
-			"status": "ok",
+			"status":  "ok",
 			"service": "api-employees-and-departments",
 		})
 	})
@@ -43,10 +50,19 @@ func SetupRoutes(router *gin.Engine, config *RouterConfig) {
 		{
 			employees.GET("", config.EmployeeHandler.GetAll)
 			employees.POST("/list", config.EmployeeHandler.List)
+			employees.POST("/import", config.EmployeeHandler.Import)
+			employees.GET("/export", config.EmployeeHandler.Export)
+			employees.GET("/deleted", config.EmployeeHandler.ListDeleted)
 			employees.GET("/:id", config.EmployeeHandler.GetByID)
+			employees.GET("/:id/reporting-chain", config.EmployeeHandler.GetReportingChain)
 			employees.POST("", config.EmployeeHandler.Create)
 			employees.PUT("/:id", config.EmployeeHandler.Update)
+			employees.PATCH("/:id", config.EmployeeHandler.Patch)
 			employees.DELETE("/:id", config.EmployeeHandler.Delete)
+			employees.POST("/:id/restore", config.EmployeeHandler.Restore)
+			employees.DELETE("/:id/purge", config.EmployeeHandler.Purge)
+			employees.GET("/:id/data-export", config.EmployeeHandler.DataExport)
+			employees.POST("/:id/anonymize", config.EmployeeHandler.Anonymize)
 		}
 
 		// Department routes
@@ -54,10 +70,18 @@ func SetupRoutes(router *gin.Engine, config *RouterConfig) {
 		{
 			departments.GET("", config.DepartmentHandler.GetAll)
 			departments.POST("/list", config.DepartmentHandler.List)
+			departments.GET("/export", config.DepartmentHandler.Export)
+			departments.GET("/deleted", config.DepartmentHandler.ListDeleted)
 			departments.GET("/:id", config.DepartmentHandler.GetByID)
+			departments.GET("/:id/ancestors", config.DepartmentHandler.GetAncestors)
+			departments.GET("/:id/orgchart", config.DepartmentHandler.GetOrgChart)
 			departments.POST("", config.DepartmentHandler.Create)
 			departments.PUT("/:id", config.DepartmentHandler.Update)
+			departments.PATCH("/:id", config.DepartmentHandler.Patch)
+			departments.POST("/:id/move", config.DepartmentHandler.Move)
 			departments.DELETE("/:id", config.DepartmentHandler.Delete)
+			departments.POST("/:id/restore", config.DepartmentHandler.Restore)
+			departments.DELETE("/:id/purge", config.DepartmentHandler.Purge)
 		}
 
 		// Manager routes
@@ -65,5 +89,8 @@ func SetupRoutes(router *gin.Engine, config *RouterConfig) {
 		{
 			managers.GET("/:id/employees", config.ManagerHandler.GetSubordinateEmployees)
 		}
+
+		// Search across employees and departments
+		v1.GET("/search", config.SearchHandler.Search)
 	}
 }
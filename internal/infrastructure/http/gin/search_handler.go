@@ -0,0 +1,50 @@
+package ginapi
+
+import (
+	"net/http"
+	"strings"
+
+	"api-employees-and-departments/internal/domain/search"
+	"api-employees-and-departments/internal/interfaces/api/dto"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SearchHandler struct {
+	service *search.Service
+}
+
+func NewSearchHandler(s *search.Service) *SearchHandler {
+	return &SearchHandler{service: s}
+}
+
+// Search godoc
+// @Summary Search employees and departments
+// @Description Searches employee names, whole RGs, whole CPFs or their first 3, 6 or 9 digits and department names at once (CPFs and RGs only for an X-API-Key with access to personal data), ignoring accents and case, with typos tolerated in names. Results are ranked by score (0 to 1) and each record is listed once, with the field it matched best.
+// @Tags search
+// @Produce json
+// @Param q query string true "Search text, at least 2 characters"
+// @Param type query string false "Only one kind of record" Enums(employee, department)
+// @Param limit query int false "Maximum number of results" default(20)
+// @Success 200 {object} dto.SearchResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /search [get]
+func (h *SearchHandler) Search(c *gin.Context) {
+	var req dto.SearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		return
+	}
+
+	// Finding employees by CPF or RG would confirm whose document it is to
+	// callers who may not see it
+	c.Writer.Header().Add("Vary", apiKeyHeader)
+	results, err := h.service.Search(req.Q, search.Kind(req.Type), req.Limit, c.GetBool(piiAccessKey))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToSearchResponse(strings.TrimSpace(req.Q), results))
+}
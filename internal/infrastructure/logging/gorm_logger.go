@@ -11,9 +11,11 @@ import (
 	gormlogger "gorm.io/gorm/logger"
 )
 
-// GormLogger is a custom GORM logger that uses Zap for structured logging
+// GormLogger is a custom GORM logger that uses Zap for structured logging.
+// The values bound to logged SQL are masked when they look like a CPF or RG.
 type GormLogger struct {
 	logger                    *zap.Logger
+	redactor                  *Redactor
 	slowThreshold             time.Duration
 	ignoreRecordNotFoundError bool
 }
@@ -22,6 +24,7 @@ type GormLogger struct {
 func NewGormLogger(slowThreshold time.Duration) *GormLogger {
 	return &GormLogger{
 		logger:                    GetLogger(),
+		redactor:                  redactor,
 		slowThreshold:             slowThreshold,
 		ignoreRecordNotFoundError: true,
 	}
@@ -32,6 +35,12 @@ func (l *GormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
 	return l
 }
 
+// ParamsFilter implements gorm.ParamsFilter, masking documents bound to the
+// SQL before Trace receives it
+func (l *GormLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	return sql, l.redactor.Params(params)
+}
+
 // Info implements gorm logger.Interface
 func (l *GormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
 	l.logger.Info(fmt.Sprintf(msg, data...))
@@ -3,6 +3,7 @@ package logging
 import (
 	"os"
 	"strings"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -11,10 +12,14 @@ import (
 var (
 	// Logger is the global logger instance
 	Logger *zap.Logger
+
+	// redactor masks personal data in everything logged, SQL included
+	redactor = NewRedactor()
 )
 
-// InitLogger initializes the Zap logger based on environment configuration
-func InitLogger(environment string, logLevel string) error {
+// InitLogger initializes the Zap logger based on environment configuration.
+// Values logged under redactFields are masked, on top of DefaultRedactedFields.
+func InitLogger(environment string, logLevel string, redactFields ...string) error {
 	var config zap.Config
 
 	// Configure based on environment
@@ -44,11 +49,24 @@ func InitLogger(environment string, logLevel string) error {
 		config.Sampling = nil
 	}
 
+	// Sampling is applied by hand so that it wraps the redaction, which has to
+	// see every entry the sampler lets through
+	sampling := config.Sampling
+	config.Sampling = nil
+	redactor = NewRedactor(redactFields...)
+
 	// Build logger
 	logger, err := config.Build(
 		zap.AddCaller(),
 		zap.AddCallerSkip(1), // Skip one level to show actual caller
 		zap.AddStacktrace(zapcore.ErrorLevel),
+		zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			core = NewRedactingCore(core, redactor)
+			if sampling != nil {
+				core = zapcore.NewSamplerWithOptions(core, time.Second, sampling.Initial, sampling.Thereafter)
+			}
+			return core
+		}),
 	)
 	if err != nil {
 		return err
@@ -87,7 +105,9 @@ func Sync() {
 func GetLogger() *zap.Logger {
 	if Logger == nil {
 		// Fallback to development logger if not initialized
-		Logger, _ = zap.NewDevelopment()
+		Logger, _ = zap.NewDevelopment(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return NewRedactingCore(core, redactor)
+		}))
 	}
 	return Logger
 }
@@ -0,0 +1,159 @@
+package logging
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// DefaultRedactedFields are the field keys whose values are never logged,
+// whatever else is configured
+var DefaultRedactedFields = []string{"cpf", "rg"}
+
+// redacted replaces personal data in the log output
+const redacted = "[REDACTED]"
+
+var (
+	// CPFs with or without punctuation; any other 11-digit number is masked too
+	cpfPattern = regexp.MustCompile(`\b\d{3}\.?\d{3}\.?\d{3}-?\d{2}\b`)
+	// RGs written with their punctuation, whose check digit may be an X. Bare
+	// RGs can't be told apart from other numbers in free text.
+	rgPattern = regexp.MustCompile(`\b\d{1,2}\.\d{3}\.\d{3}-?[0-9Xx]\b`)
+	// documentValuePattern matches a whole value made of nothing but the digits
+	// and punctuation of a document, such as a bound CPF or RG
+	documentValuePattern = regexp.MustCompile(`^[0-9][0-9./ -]*[0-9Xx]$`)
+)
+
+// minDocumentDigits is the fewest digits a value needs to be taken for a document
+const minDocumentDigits = 5
+
+// Redactor masks personal data before it reaches the log output: the values of
+// the configured field keys, and CPFs and RGs found in messages and strings
+type Redactor struct {
+	fields map[string]struct{}
+}
+
+// NewRedactor creates a Redactor for DefaultRedactedFields and the given keys,
+// which are compared case-insensitively
+func NewRedactor(fields ...string) *Redactor {
+	r := &Redactor{fields: make(map[string]struct{})}
+	for _, keys := range [][]string{DefaultRedactedFields, fields} {
+		for _, key := range keys {
+			r.fields[strings.ToLower(strings.TrimSpace(key))] = struct{}{}
+		}
+	}
+	return r
+}
+
+// String masks the CPFs and RGs found in s
+func (r *Redactor) String(s string) string {
+	s = cpfPattern.ReplaceAllString(s, redacted)
+	return rgPattern.ReplaceAllString(s, redacted)
+}
+
+// Fields returns fields with the configured keys masked and the CPFs and RGs
+// in string and error values replaced. Other values are logged as they are.
+func (r *Redactor) Fields(fields []zapcore.Field) []zapcore.Field {
+	masked := make([]zapcore.Field, len(fields))
+	for i, field := range fields {
+		masked[i] = r.field(field)
+	}
+	return masked
+}
+
+func (r *Redactor) field(field zapcore.Field) zapcore.Field {
+	if _, ok := r.fields[strings.ToLower(field.Key)]; ok {
+		return zap.String(field.Key, redacted)
+	}
+
+	switch field.Type {
+	case zapcore.StringType:
+		field.String = r.String(field.String)
+	case zapcore.ErrorType:
+		// Database errors quote the offending values, duplicate CPFs included
+		if err, ok := field.Interface.(error); ok && err != nil {
+			if msg := err.Error(); r.String(msg) != msg {
+				return zap.NamedError(field.Key, errors.New(r.String(msg)))
+			}
+		}
+	}
+	return field
+}
+
+// Params returns a copy of the values bound to a SQL statement with the ones
+// that look like a document masked
+func (r *Redactor) Params(params []interface{}) []interface{} {
+	masked := make([]interface{}, len(params))
+	for i, param := range params {
+		masked[i] = r.param(param)
+	}
+	return masked
+}
+
+func (r *Redactor) param(param interface{}) interface{} {
+	switch v := param.(type) {
+	case string:
+		if isDocument(v) {
+			return redacted
+		}
+	case *string:
+		if v != nil && isDocument(*v) {
+			return redacted
+		}
+	case []string:
+		masked := make([]string, len(v))
+		for i, s := range v {
+			masked[i] = s
+			if isDocument(s) {
+				masked[i] = redacted
+			}
+		}
+		return masked
+	}
+	return param
+}
+
+// isDocument reports whether value could be a CPF or RG, or the start of one
+func isDocument(value string) bool {
+	if !documentValuePattern.MatchString(value) {
+		return false
+	}
+	digits := 0
+	for _, c := range value {
+		if c >= '0' && c <= '9' {
+			digits++
+		}
+	}
+	return digits >= minDocumentDigits
+}
+
+// redactingCore runs every entry through a Redactor before writing it
+type redactingCore struct {
+	zapcore.Core
+	redactor *Redactor
+}
+
+// NewRedactingCore wraps core so that personal data is masked from everything
+// written to it, fields added with With included
+func NewRedactingCore(core zapcore.Core, redactor *Redactor) zapcore.Core {
+	return &redactingCore{Core: core, redactor: redactor}
+}
+
+func (c *redactingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactingCore{Core: c.Core.With(c.redactor.Fields(fields)), redactor: c.redactor}
+}
+
+func (c *redactingCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *redactingCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	entry.Message = c.redactor.String(entry.Message)
+	return c.Core.Write(entry, c.redactor.Fields(fields))
+}
@@ -0,0 +1,142 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	domainLogging "api-employees-and-departments/internal/domain/logging"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// Documents that must never show up in the log output
+var personalData = []string{"11144477735", "111.444.777-35", "12.345.678-X", "123456789"}
+
+// newTestLogger writes JSON logs to buf, redacted like the ones built by InitLogger
+func newTestLogger(buf *bytes.Buffer, redactFields ...string) *zap.Logger {
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(buf), zapcore.DebugLevel)
+	return zap.New(NewRedactingCore(core, NewRedactor(redactFields...)))
+}
+
+func assertRedacted(t *testing.T, output string) {
+	t.Helper()
+	for _, document := range personalData {
+		if strings.Contains(output, document) {
+			t.Errorf("log output contains %q:\n%s", document, output)
+		}
+	}
+	if !strings.Contains(output, redacted) {
+		t.Errorf("log output has nothing redacted:\n%s", output)
+	}
+}
+
+func TestRedactorString(t *testing.T) {
+	redactor := NewRedactor()
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"CPF digits", "cpf 11144477735 taken", "cpf [REDACTED] taken"},
+		{"formatted CPF", "cpf 111.444.777-35 taken", "cpf [REDACTED] taken"},
+		{"quoted in SQL", `WHERE cpf = '11144477735'`, `WHERE cpf = '[REDACTED]'`},
+		{"formatted RG", "rg 12.345.678-X", "rg [REDACTED]"},
+		{"longer number", "id 123456789012", "id 123456789012"},
+		{"UUID", "id 8f14e45f-ceea-467f-a0e6-1d2b3c4d5e6f", "id 8f14e45f-ceea-467f-a0e6-1d2b3c4d5e6f"},
+		{"timestamp", "at 2026-10-17 12:30:45.123", "at 2026-10-17 12:30:45.123"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redactor.String(tt.in); got != tt.want {
+				t.Errorf("String(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactorParams(t *testing.T) {
+	redactor := NewRedactor()
+	rg := "12.345.678-X"
+	params := []interface{}{"11144477735", &rg, []string{"João", "111.444.777-35"}, "João Silva", "1234", 42, "1114447"}
+
+	got := redactor.Params(params)
+
+	want := []interface{}{redacted, redacted, []string{"João", redacted}, "João Silva", "1234", 42, redacted}
+	for i := range want {
+		if s, ok := want[i].([]string); ok {
+			if g := got[i].([]string); g[0] != s[0] || g[1] != s[1] {
+				t.Errorf("param %d = %v, want %v", i, g, s)
+			}
+			continue
+		}
+		if got[i] != want[i] {
+			t.Errorf("param %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+	if params[0] != "11144477735" {
+		t.Error("Params changed the values bound to the statement")
+	}
+}
+
+func TestZapLoggerRedactsPersonalData(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewZapLogger(newTestLogger(&buf, "document")).
+		With(domainLogging.String("cpf", "11144477735"))
+
+	logger.Warn("Employee validation failed for CPF 111.444.777-35",
+		domainLogging.String("name", "João Silva"),
+		domainLogging.String("rg", "123456789"),
+		domainLogging.Any("document", []string{"12.345.678-X"}),
+		domainLogging.String("detail", "RG 12.345.678-X already in use"),
+		domainLogging.Error(errors.New(`duplicate key value: Key (cpf)=(11144477735) already exists`)),
+	)
+
+	output := buf.String()
+	assertRedacted(t, output)
+	if !strings.Contains(output, "João Silva") {
+		t.Errorf("log output lost a field that is not personal data:\n%s", output)
+	}
+}
+
+func TestGormLoggerRedactsSQL(t *testing.T) {
+	var buf bytes.Buffer
+	gormLogger := &GormLogger{logger: newTestLogger(&buf), redactor: NewRedactor()}
+
+	// A dry run builds and logs the SQL without a database
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		Logger:                 gormLogger,
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+
+	type employee struct {
+		ID        int
+		Name      string
+		CPF       string
+		RG        *string
+		CreatedAt time.Time
+	}
+	rg := "123456789"
+	if err := db.Create(&employee{Name: "João Silva", CPF: "11144477735", RG: &rg}).Error; err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	db.Where("cpf = ? OR rg = ?", "111.444.777-35", "12.345.678-X").Find(&[]employee{})
+
+	output := buf.String()
+	assertRedacted(t, output)
+	if !strings.Contains(output, "João Silva") {
+		t.Errorf("SQL lost a value that is not personal data:\n%s", output)
+	}
+}
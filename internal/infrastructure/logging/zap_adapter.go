@@ -11,7 +11,8 @@ type ZapLogger struct {
 	logger *zap.Logger
 }
 
-// NewZapLogger creates a new ZapLogger that implements the domain Logger interface
+// NewZapLogger creates a new ZapLogger that implements the domain Logger interface.
+// Loggers derived from GetLogger mask personal data, as set up by InitLogger.
 func NewZapLogger(logger *zap.Logger) domainLogging.Logger {
 	return &ZapLogger{logger: logger}
 }
@@ -19,6 +19,7 @@ func Run(db *gorm.DB) error {
 	if err := db.AutoMigrate(
 		&department.Department{},
 		&employee.Employee{},
+		&employee.AuditEvent{},
 	); err != nil {
 		return fmt.Errorf("failed to run migrations: %w", err)
 	}
@@ -0,0 +1,70 @@
+package persistence
+
+import (
+	"api-employees-and-departments/internal/domain/department"
+	"api-employees-and-departments/internal/domain/employee"
+
+	"github.com/google/uuid"
+)
+
+// DepartmentAdapter adapts DepartmentRepository to employee.DepartmentRepository interface
+type DepartmentAdapter struct {
+	repo *DepartmentRepository
+}
+
+func NewDepartmentAdapter(repo *DepartmentRepository) employee.DepartmentRepository {
+	return &DepartmentAdapter{repo: repo}
+}
+
+func (a *DepartmentAdapter) FindByID(id uuid.UUID) (employee.Department, error) {
+	dept, err := a.repo.FindByID(id)
+	if err != nil {
+		return employee.Department{}, err
+	}
+
+	return employee.Department{
+		ID:                 dept.ID,
+		Name:               dept.Name,
+		ManagerID:          dept.ManagerID,
+		ParentDepartmentID: dept.ParentDepartmentID,
+	}, nil
+}
+
+func (a *DepartmentAdapter) FindByManagerID(managerID uuid.UUID) ([]employee.Department, error) {
+	departments, err := a.repo.FindByManagerID(managerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toEmployeeDepartments(departments), nil
+}
+
+func (a *DepartmentAdapter) CountAllByManagerID(managerID uuid.UUID) (int64, error) {
+	var count int64
+	err := a.repo.db.Unscoped().Model(&department.Department{}).
+		Where("manager_id = ?", managerID).
+		Count(&count).Error
+	return count, translateError(err, "department")
+}
+
+func (a *DepartmentAdapter) FindByName(name string) ([]employee.Department, error) {
+	var departments []department.Department
+	err := a.repo.db.Where("LOWER(name) = LOWER(?)", name).Find(&departments).Error
+	if err != nil {
+		return nil, translateError(err, "department")
+	}
+	return toEmployeeDepartments(departments), nil
+}
+
+func toEmployeeDepartments(departments []department.Department) []employee.Department {
+	result := make([]employee.Department, len(departments))
+	for i, dept := range departments {
+		result[i] = employee.Department{
+			ID:                 dept.ID,
+			Name:               dept.Name,
+			ManagerID:          dept.ManagerID,
+			ParentDepartmentID: dept.ParentDepartmentID,
+		}
+	}
+	return result
+}
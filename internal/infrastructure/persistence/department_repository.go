@@ -2,11 +2,14 @@ package persistence
 
 import (
 	"api-employees-and-departments/internal/domain/department"
+	"api-employees-and-departments/internal/domain/employee"
+	"api-employees-and-departments/internal/domain/pagination"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/lib/pq"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type DepartmentRepository struct {
@@ -27,7 +30,7 @@ func (r *DepartmentRepository) FindByID(id uuid.UUID) (*department.Department, e
 	var dept department.Department
 	err := r.db.First(&dept, "id = ?", id).Error
 	if err != nil {
-		return nil, err
+		return nil, translateError(err, "department")
 	}
 	return &dept, nil
 }
@@ -53,6 +56,7 @@ type hierarchyRow struct {
 	ManagerName          string         `gorm:"column:manager_name"`
 	Level                int            `gorm:"column:level"`
 	Path                 pq.StringArray `gorm:"column:path;type:text[]"`
+	Version              int64          `gorm:"column:version"`
 	CreatedAt            time.Time      `gorm:"column:created_at"`
 	UpdatedAt            time.Time      `gorm:"column:updated_at"`
 }
@@ -71,6 +75,7 @@ func (r *DepartmentRepository) FindHierarchyByID(id uuid.UUID) (*department.Depa
 			d.manager_id,
 			d.parent_department_id,
 			e.name as manager_name,
+			d.version,
 			d.created_at,
 			d.updated_at,
 			0 as level,
@@ -89,6 +94,7 @@ func (r *DepartmentRepository) FindHierarchyByID(id uuid.UUID) (*department.Depa
 			d.manager_id,
 			d.parent_department_id,
 			e.name as manager_name,
+			d.version,
 			d.created_at,
 			d.updated_at,
 			dt.level + 1,
@@ -109,7 +115,7 @@ func (r *DepartmentRepository) FindHierarchyByID(id uuid.UUID) (*department.Depa
 	}
 
 	if len(rows) == 0 {
-		return nil, gorm.ErrRecordNotFound
+		return nil, notFound("department")
 	}
 
 	// Construir árvore a partir do resultado flat
@@ -133,6 +139,7 @@ func buildTreeFromFlatList(rows []hierarchyRow) *department.DepartmentWithHierar
 				Name:                 row.Name,
 				ManagerID:            row.ManagerID,
 				ParentDepartmentID:   row.ParentDepartmentID,
+				Version:              row.Version,
 				CreatedAt:            row.CreatedAt,
 				UpdatedAt:            row.UpdatedAt,
 			},
@@ -142,59 +149,566 @@ func buildTreeFromFlatList(rows []hierarchyRow) *department.DepartmentWithHierar
 		deptMap[row.ID] = dept
 	}
 
-	// Segunda passagem: construir as relações parent-child
-	var root *department.DepartmentWithHierarchy
-	for _, row := range rows {
-		dept := deptMap[row.ID]
+	// Segunda passagem: agrupar os filhos de cada nó, mantendo a ordem por nome
+	children := make(map[uuid.UUID][]uuid.UUID)
+	for _, row := range rows[1:] {
 		if row.ParentDepartmentID != nil {
-			if parent, exists := deptMap[*row.ParentDepartmentID]; exists {
-				parent.Subdepartments = append(parent.Subdepartments, *dept)
-			}
-		} else {
-			root = dept  // Departamento raiz (nível 0)
+			children[*row.ParentDepartmentID] = append(children[*row.ParentDepartmentID], row.ID)
+		}
+	}
+
+	// Os filhos são copiados para o slice do pai, então a árvore é montada de
+	// baixo para cima para que cada cópia já leve seus próprios descendentes
+	var attach func(id uuid.UUID) department.DepartmentWithHierarchy
+	attach = func(id uuid.UUID) department.DepartmentWithHierarchy {
+		dept := deptMap[id]
+		for _, childID := range children[id] {
+			dept.Subdepartments = append(dept.Subdepartments, attach(childID))
+		}
+		return *dept
+	}
+
+	// O nível 0 é o departamento consultado, que não precisa ser uma raiz
+	root := attach(rows[0].ID)
+	return &root
+}
+
+// ancestorRow represents a row from the upward CTE recursive query
+type ancestorRow struct {
+	ID                 uuid.UUID  `gorm:"column:id"`
+	Name               string     `gorm:"column:name"`
+	ManagerID          uuid.UUID  `gorm:"column:manager_id"`
+	ParentDepartmentID *uuid.UUID `gorm:"column:parent_department_id"`
+	ManagerName        string     `gorm:"column:manager_name"`
+	CreatedAt          time.Time  `gorm:"column:created_at"`
+	UpdatedAt          time.Time  `gorm:"column:updated_at"`
+}
+
+// FindAncestors retrieves the path from the root down to a department using an upward CTE recursive query
+func (r *DepartmentRepository) FindAncestors(id uuid.UUID) ([]department.DepartmentAncestor, error) {
+	var rows []ancestorRow
+
+	// CTE recursivo subindo do departamento até a raiz
+	query := `
+	WITH RECURSIVE ancestors AS (
+		-- Base case: o próprio departamento
+		SELECT
+			d.id,
+			d.name,
+			d.manager_id,
+			d.parent_department_id,
+			d.created_at,
+			d.updated_at,
+			0 as level,
+			ARRAY[d.id::text] as path
+		FROM departments d
+		WHERE d.id = $1
+		AND d.deleted_at IS NULL
+
+		UNION ALL
+
+		-- Recursive case: pai do último nível encontrado
+		SELECT
+			p.id,
+			p.name,
+			p.manager_id,
+			p.parent_department_id,
+			p.created_at,
+			p.updated_at,
+			a.level + 1,
+			a.path || p.id::text
+		FROM departments p
+		INNER JOIN ancestors a ON p.id = a.parent_department_id
+		WHERE p.deleted_at IS NULL
+		AND NOT p.id::text = ANY(a.path)  -- Previne ciclos
+	)
+	SELECT a.id, a.name, a.manager_id, a.parent_department_id,
+		COALESCE(e.name, '') as manager_name, a.created_at, a.updated_at
+	FROM ancestors a
+	LEFT JOIN employees e ON a.manager_id = e.id AND e.deleted_at IS NULL
+	ORDER BY a.level DESC
+	`
+
+	if err := r.db.Raw(query, id).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, notFound("department")
+	}
+
+	result := make([]department.DepartmentAncestor, len(rows))
+	for i, row := range rows {
+		result[i] = department.DepartmentAncestor{
+			Department: department.Department{
+				ID:                 row.ID,
+				Name:               row.Name,
+				ManagerID:          row.ManagerID,
+				ParentDepartmentID: row.ParentDepartmentID,
+				CreatedAt:          row.CreatedAt,
+				UpdatedAt:          row.UpdatedAt,
+			},
+			ManagerName: row.ManagerName,
+			Depth:       i,
 		}
 	}
 
-	return root
+	return result, nil
+}
+
+// FindSubtreeIDs retrieves the IDs of a department and all of its descendants
+func (r *DepartmentRepository) FindSubtreeIDs(id uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+
+	query := `
+	WITH RECURSIVE subtree AS (
+		SELECT d.id, ARRAY[d.id::text] as path
+		FROM departments d
+		WHERE d.id = $1
+		AND d.deleted_at IS NULL
+
+		UNION ALL
+
+		SELECT d.id, s.path || d.id::text
+		FROM departments d
+		INNER JOIN subtree s ON d.parent_department_id = s.id
+		WHERE d.deleted_at IS NULL
+		AND NOT d.id::text = ANY(s.path)  -- Previne ciclos
+	)
+	SELECT id FROM subtree
+	`
+
+	if err := r.db.Raw(query, id).Scan(&ids).Error; err != nil {
+		return nil, err
+	}
+
+	return ids, nil
 }
 
 func (r *DepartmentRepository) Create(dept *department.Department) error {
-	return r.db.Create(dept).Error
+	return translateError(r.db.Create(dept).Error, "department")
 }
 
 func (r *DepartmentRepository) Update(dept *department.Department) error {
-	return r.db.Save(dept).Error
+	return updateVersioned(r.db, dept, &dept.Version, "department")
+}
+
+func (r *DepartmentRepository) UpdateFields(dept *department.Department, fields ...string) error {
+	return updateVersioned(r.db, dept, &dept.Version, "department", fields...)
+}
+
+func (r *DepartmentRepository) LockVersion(id uuid.UUID, version int64) error {
+	var ids []uuid.UUID
+	err := r.db.Model(&department.Department{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND version = ?", id, version).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return translateError(err, "department")
+	}
+	if len(ids) == 0 {
+		return versionMismatch("department")
+	}
+	return nil
 }
 
 func (r *DepartmentRepository) Delete(id uuid.UUID) error {
-	return r.db.Delete(&department.Department{}, "id = ?", id).Error
+	return translateError(r.db.Delete(&department.Department{}, "id = ?", id).Error, "department")
 }
 
-func (r *DepartmentRepository) FindWithFilters(filters department.ListFilters) ([]department.Department, int64, error) {
+func (r *DepartmentRepository) DeleteMany(ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return translateError(r.db.Delete(&department.Department{}, "id IN ?", ids).Error, "department")
+}
+
+func (r *DepartmentRepository) CountEmployees(departmentID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Table("employees").
+		Where("department_id = ? AND deleted_at IS NULL", departmentID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *DepartmentRepository) CountEmployeesByDepartments(departmentIDs []uuid.UUID) (map[uuid.UUID]int64, error) {
+	var rows []struct {
+		DepartmentID uuid.UUID
+		Count        int64
+	}
+	err := r.db.Table("employees").
+		Select("department_id, COUNT(*) AS count").
+		Where("department_id IN ? AND deleted_at IS NULL", departmentIDs).
+		Group("department_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uuid.UUID]int64, len(rows))
+	for _, row := range rows {
+		counts[row.DepartmentID] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *DepartmentRepository) ReassignEmployees(fromDepartmentID, toDepartmentID uuid.UUID) (int64, error) {
+	members, err := r.lockEmployees([]uuid.UUID{fromDepartmentID})
+	if err != nil || len(members) == 0 {
+		return 0, err
+	}
+	result := r.db.Table("employees").
+		Where("id IN ?", memberIDs(members)).
+		Updates(map[string]interface{}{
+			"department_id": toDepartmentID,
+			"updated_at":    time.Now(),
+			"version":       gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	events := make([]employee.AuditEvent, len(members))
+	for i, m := range members {
+		events[i] = employee.AuditEvent{EmployeeID: m.ID, Action: employee.AuditUpdated, Fields: []string{"department_id"}, DepartmentID: toDepartmentID}
+	}
+	return result.RowsAffected, r.db.Create(&events).Error
+}
+
+func (r *DepartmentRepository) DeleteEmployees(departmentIDs []uuid.UUID) (int64, error) {
+	if len(departmentIDs) == 0 {
+		return 0, nil
+	}
+	members, err := r.lockEmployees(departmentIDs)
+	if err != nil || len(members) == 0 {
+		return 0, err
+	}
+	result := r.db.Table("employees").
+		Where("id IN ?", memberIDs(members)).
+		Update("deleted_at", time.Now())
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	events := make([]employee.AuditEvent, len(members))
+	for i, m := range members {
+		events[i] = employee.AuditEvent{EmployeeID: m.ID, Action: employee.AuditDeleted, DepartmentID: m.DepartmentID}
+	}
+	return result.RowsAffected, r.db.Create(&events).Error
+}
+
+func (r *DepartmentRepository) RestoreManager(managerID, departmentID uuid.UUID) (bool, error) {
+	result := r.db.Table("employees").
+		Where("id = ? AND department_id = ? AND deleted_at IS NOT NULL AND anonymized_at IS NULL", managerID, departmentID).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return false, translateError(result.Error, "employee")
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	event := employee.AuditEvent{EmployeeID: managerID, Action: employee.AuditRestored, DepartmentID: departmentID}
+	return true, r.db.Create(&event).Error
+}
+
+// member is an active employee moved or deleted along with its department
+type member struct {
+	ID           uuid.UUID
+	DepartmentID uuid.UUID
+}
+
+// lockEmployees locks the active employees of the given departments, so that
+// the history written for them names exactly the rows that change
+func (r *DepartmentRepository) lockEmployees(departmentIDs []uuid.UUID) ([]member, error) {
+	var members []member
+	err := r.db.Table("employees").
+		Select("id, department_id").
+		Where("department_id IN ? AND deleted_at IS NULL", departmentIDs).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Scan(&members).Error
+	return members, err
+}
+
+func memberIDs(members []member) []uuid.UUID {
+	ids := make([]uuid.UUID, len(members))
+	for i, m := range members {
+		ids[i] = m.ID
+	}
+	return ids
+}
+
+func (r *DepartmentRepository) ReparentChildren(parentID uuid.UUID, newParentID *uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if err := r.db.Model(&department.Department{}).
+		Where("parent_department_id = ?", parentID).
+		Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return ids, nil
+	}
+
+	err := r.db.Model(&department.Department{}).
+		Where("id IN ?", ids).
+		Updates(map[string]interface{}{
+			"parent_department_id": newParentID,
+			"version":              gorm.Expr("version + 1"),
+		}).Error
+	return ids, err
+}
+
+func (r *DepartmentRepository) UpdateParent(id uuid.UUID, parentID *uuid.UUID) error {
+	return translateError(r.db.Model(&department.Department{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"parent_department_id": parentID,
+			"version":              gorm.Expr("version + 1"),
+		}).Error, "department")
+}
+
+// FindDeleted reads a page of the soft-deleted departments, latest deleted first
+func (r *DepartmentRepository) FindDeleted(page, pageSize int) ([]department.Department, int64, error) {
 	var departments []department.Department
 	var total int64
 
-	query := r.db.Model(&department.Department{})
+	query := r.db.Unscoped().Model(&department.Department{}).Where("deleted_at IS NOT NULL")
 
-	// Apply filters
-	if filters.Name != nil && *filters.Name != "" {
-		query = query.Where("name ILIKE ?", "%"+*filters.Name+"%")
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
 	}
-	if filters.ParentDepartmentID != nil {
-		query = query.Where("parent_department_id = ?", *filters.ParentDepartmentID)
+
+	offset := (page - 1) * pageSize
+	if err := query.Order("deleted_at DESC").Offset(offset).Limit(pageSize).Find(&departments).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return departments, total, nil
+}
+
+func (r *DepartmentRepository) FindDeletedByID(id uuid.UUID) (*department.Department, error) {
+	var dept department.Department
+	err := r.db.Unscoped().First(&dept, "id = ? AND deleted_at IS NOT NULL", id).Error
+	if err != nil {
+		return nil, translateError(err, "deleted department")
+	}
+	return &dept, nil
+}
+
+func (r *DepartmentRepository) Restore(id uuid.UUID) error {
+	return translateError(r.db.Unscoped().Model(&department.Department{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil).Error, "department")
+}
+
+func (r *DepartmentRepository) Purge(id uuid.UUID) error {
+	return translateError(r.db.Unscoped().Delete(&department.Department{}, "id = ? AND deleted_at IS NOT NULL", id).Error, "department")
+}
+
+func (r *DepartmentRepository) CountReferences(id uuid.UUID) (int64, int64, error) {
+	var employees, children int64
+	if err := r.db.Table("employees").Where("department_id = ?", id).Count(&employees).Error; err != nil {
+		return 0, 0, err
+	}
+	if err := r.db.Table("departments").Where("parent_department_id = ?", id).Count(&children).Error; err != nil {
+		return 0, 0, err
 	}
-	// TODO: Manager name filter requires join with employees table
+	return employees, children, nil
+}
+
+// LockHierarchy takes a transaction-scoped advisory lock so that two concurrent
+// moves cannot each pass the cycle check and together create a cycle
+func (r *DepartmentRepository) LockHierarchy() error {
+	return r.db.Exec("SELECT pg_advisory_xact_lock(hashtext('departments_hierarchy'))").Error
+}
+
+func (r *DepartmentRepository) Transaction(fn func(repo department.Repository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&DepartmentRepository{db: tx})
+	})
+}
+
+// departmentListRow is a department read by a listing, with the joined columns
+// it may be sorted by
+type departmentListRow struct {
+	department.Department
+	ManagerName string
+}
+
+// departmentSortKeys maps department.SortFields to their columns
+var departmentSortKeys = map[string]sortKey[departmentListRow]{
+	"name":         {column: "departments.name", value: func(r *departmentListRow) any { return r.Name }},
+	"manager_name": {column: "COALESCE(m.name, '')", joined: true, value: func(r *departmentListRow) any { return r.ManagerName }},
+	"created_at":   {column: "departments.created_at", value: func(r *departmentListRow) any { return r.CreatedAt }},
+	"updated_at":   {column: "departments.updated_at", value: func(r *departmentListRow) any { return r.UpdatedAt }},
+}
+
+// listDepartments selects the departments of a listing, joining their manager
+// when the order needs it
+func (r *DepartmentRepository) listDepartments(filters department.ListFilters) *gorm.DB {
+	query := applyDepartmentFilters(r.db.Model(&department.Department{}), filters)
+	if !needsJoin(departmentSortKeys, filters.Sort) {
+		return query.Select("departments.*")
+	}
+	return query.
+		Select("departments.*, COALESCE(m.name, '') AS manager_name").
+		Joins("LEFT JOIN employees AS m ON m.id = departments.manager_id AND m.deleted_at IS NULL")
+}
+
+func (r *DepartmentRepository) FindWithFilters(filters department.ListFilters) ([]department.Department, int64, error) {
+	var rows []departmentListRow
+	var total int64
 
 	// Count total
-	if err := query.Count(&total).Error; err != nil {
+	if err := applyDepartmentFilters(r.db.Model(&department.Department{}), filters).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
-	// Apply pagination
+	// Apply order and pagination
 	offset := (filters.Page - 1) * filters.PageSize
-	if err := query.Offset(offset).Limit(filters.PageSize).Find(&departments).Error; err != nil {
+	query := applySort(r.listDepartments(filters), "departments", departmentSortKeys, filters.Sort, false)
+	if err := query.Offset(offset).Limit(filters.PageSize).Find(&rows).Error; err != nil {
 		return nil, 0, err
 	}
 
+	departments := make([]department.Department, len(rows))
+	for i, row := range rows {
+		departments[i] = row.Department
+	}
 	return departments, total, nil
 }
+
+func (r *DepartmentRepository) FindPageWithFilters(filters department.ListFilters, keyset pagination.Keyset) ([]department.Department, []pagination.Cursor, error) {
+	query, err := applyKeyset(r.listDepartments(filters), "departments", departmentSortKeys, filters.Sort, keyset)
+	if err != nil {
+		return nil, nil, err
+	}
+	var rows []departmentListRow
+	if err := query.Find(&rows).Error; err != nil {
+		return nil, nil, err
+	}
+
+	departments := make([]department.Department, len(rows))
+	for i, row := range rows {
+		departments[i] = row.Department
+	}
+	cursors := rowCursors(rows, func(r *departmentListRow) uuid.UUID { return r.ID }, departmentSortKeys, filters.Sort)
+	return departments, cursors, nil
+}
+
+func (r *DepartmentRepository) CountWithFilters(filters department.ListFilters) (int64, error) {
+	var total int64
+	err := applyDepartmentFilters(r.db.Model(&department.Department{}), filters).Count(&total).Error
+	return total, err
+}
+
+func (r *DepartmentRepository) StreamWithFilters(filters department.ListFilters, fn func(department.ExportRow) error) error {
+	query := applyDepartmentFilters(r.db.Model(&department.Department{}), filters).
+		Select("departments.*, COALESCE(m.name, '') AS manager_name, COALESCE(p.name, '') AS parent_department_name").
+		Joins("LEFT JOIN employees AS m ON m.id = departments.manager_id AND m.deleted_at IS NULL").
+		Joins("LEFT JOIN departments AS p ON p.id = departments.parent_department_id AND p.deleted_at IS NULL").
+		Order("departments.name, departments.id")
+
+	rows, err := query.Rows()
+	if err != nil {
+		return translateError(err, "department")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row department.ExportRow
+		if err := r.db.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// applyDepartmentFilters narrows a query on the departments table down to the
+// list filters; columns are qualified so the query may join other tables
+func applyDepartmentFilters(query *gorm.DB, filters department.ListFilters) *gorm.DB {
+	if filters.Name != nil && *filters.Name != "" {
+		query = query.Where("f_unaccent(departments.name) ILIKE f_unaccent(?)", "%"+*filters.Name+"%")
+	}
+	if filters.ParentDepartmentID != nil {
+		query = query.Where("departments.parent_department_id = ?", *filters.ParentDepartmentID)
+	}
+	if filters.ManagerName != nil && *filters.ManagerName != "" {
+		// The alias differs from the one of the manager joined for sorting and
+		// exports; a department has a single manager, so no row is repeated
+		query = query.Joins("JOIN employees AS fm ON fm.id = departments.manager_id AND fm.deleted_at IS NULL AND f_unaccent(fm.name) ILIKE f_unaccent(?)",
+			"%"+*filters.ManagerName+"%")
+	}
+	if filters.HasParent != nil {
+		if *filters.HasParent {
+			query = query.Where("departments.parent_department_id IS NOT NULL")
+		} else {
+			query = query.Where("departments.parent_department_id IS NULL")
+		}
+	}
+	if filters.MinDepth != nil || filters.MaxDepth != nil {
+		condition, args := departmentDepthCondition(filters.MinDepth, filters.MaxDepth)
+		query = query.Where(condition, args...)
+	}
+	if filters.MinHeadcount != nil {
+		query = query.Where(departmentHeadcount+" >= ?", *filters.MinHeadcount)
+	}
+	if filters.MaxHeadcount != nil {
+		query = query.Where(departmentHeadcount+" <= ?", *filters.MaxHeadcount)
+	}
+	if filters.CreatedFrom != nil {
+		query = query.Where("departments.created_at >= ?", *filters.CreatedFrom)
+	}
+	if filters.CreatedTo != nil {
+		query = query.Where("departments.created_at <= ?", *filters.CreatedTo)
+	}
+	if filters.UpdatedFrom != nil {
+		query = query.Where("departments.updated_at >= ?", *filters.UpdatedFrom)
+	}
+	if filters.UpdatedTo != nil {
+		query = query.Where("departments.updated_at <= ?", *filters.UpdatedTo)
+	}
+	return query
+}
+
+// departmentHeadcount counts the active employees of the department of the row
+const departmentHeadcount = "(SELECT COUNT(*) FROM employees AS he WHERE he.department_id = departments.id AND he.deleted_at IS NULL)"
+
+// departmentDepthCondition keeps the departments whose distance from their root
+// is within the bounds. The recursion walks down from the roots and stops at
+// the maximum depth; a department in a parent cycle is never reached from a
+// root, so it matches no depth.
+func departmentDepthCondition(minDepth, maxDepth *int) (string, []any) {
+	var args []any
+	stop := ""
+	if maxDepth != nil {
+		stop = "AND t.depth < ?"
+		args = append(args, *maxDepth)
+	}
+	minimum := 0
+	if minDepth != nil {
+		minimum = *minDepth
+	}
+	args = append(args, minimum)
+
+	return `departments.id IN (
+		WITH RECURSIVE depths AS (
+			SELECT d.id, 0 AS depth
+			FROM departments d
+			WHERE d.parent_department_id IS NULL
+			AND d.deleted_at IS NULL
+
+			UNION ALL
+
+			SELECT d.id, t.depth + 1
+			FROM departments d
+			INNER JOIN depths t ON d.parent_department_id = t.id
+			WHERE d.deleted_at IS NULL
+			` + stop + `
+		)
+		SELECT id FROM depths WHERE depth >= ?
+	)`, args
+}
@@ -27,3 +27,20 @@ func (a *EmployeeAdapter) FindByID(id uuid.UUID) (department.Employee, error) {
 		DepartmentID: emp.DepartmentID,
 	}, nil
 }
+
+func (a *EmployeeAdapter) FindByDepartmentIDs(departmentIDs []uuid.UUID, limitPerDepartment int) ([]department.Employee, error) {
+	employees, err := a.repo.FindByDepartmentIDs(departmentIDs, limitPerDepartment)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]department.Employee, len(employees))
+	for i, emp := range employees {
+		result[i] = department.Employee{
+			ID:           emp.ID,
+			Name:         emp.Name,
+			DepartmentID: emp.DepartmentID,
+		}
+	}
+	return result, nil
+}
@@ -1,18 +1,28 @@
 package persistence
 
 import (
+	"database/sql"
+	"strings"
+
 	"api-employees-and-departments/internal/domain/employee"
+	"api-employees-and-departments/internal/domain/pagination"
+	"api-employees-and-departments/internal/infrastructure/encryption"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type EmployeeRepository struct {
-	db *gorm.DB
+	db      *gorm.DB
+	indexes documentIndexes
 }
 
-func NewEmployeeRepository(db *gorm.DB) employee.Repository {
-	return &EmployeeRepository{db: db}
+// NewEmployeeRepository creates an employee repository looking CPFs and RGs up
+// by their blind indexes under keyring, which RegisterEncryption must also have
+// been given
+func NewEmployeeRepository(db *gorm.DB, keyring *encryption.Keyring) employee.Repository {
+	return &EmployeeRepository{db: db, indexes: documentIndexes{keyring: keyring}}
 }
 
 func (r *EmployeeRepository) FindAll() ([]employee.Employee, error) {
@@ -25,7 +35,7 @@ func (r *EmployeeRepository) FindByID(id uuid.UUID) (*employee.Employee, error)
 	var emp employee.Employee
 	err := r.db.First(&emp, "id = ?", id).Error
 	if err != nil {
-		return nil, err
+		return nil, translateError(err, "employee")
 	}
 	return &emp, nil
 }
@@ -36,15 +46,18 @@ func (r *EmployeeRepository) FindByIDWithManager(id uuid.UUID) (*employee.Employ
 		ManagerName string
 	}
 
-	err := r.db.Table("employees AS e").
-		Select("e.*, m.name AS manager_name").
+	query := r.db.Table("employees AS e").
+		Select("e.*, COALESCE(m.name, '') AS manager_name").
 		Joins("INNER JOIN departments AS d ON e.department_id = d.id").
-		Joins("INNER JOIN employees AS m ON d.manager_id = m.id").
-		Where("e.id = ?", id).
-		Scan(&result).Error
+		Joins("LEFT JOIN employees AS m ON d.manager_id = m.id AND m.deleted_at IS NULL").
+		Where("e.id = ? AND e.deleted_at IS NULL", id).
+		Scan(&result)
 
-	if err != nil {
-		return nil, err
+	if query.Error != nil {
+		return nil, translateError(query.Error, "employee")
+	}
+	if query.RowsAffected == 0 {
+		return nil, notFound("employee")
 	}
 
 	return &employee.EmployeeWithManager{
@@ -53,57 +66,455 @@ func (r *EmployeeRepository) FindByIDWithManager(id uuid.UUID) (*employee.Employ
 	}, nil
 }
 
-func (r *EmployeeRepository) FindByDepartmentIDs(departmentIDs []uuid.UUID) ([]employee.Employee, error) {
+// FindDepartmentManagers walks up the department tree from the employee's department
+// using PostgreSQL CTE recursive query and returns the manager of each level
+func (r *EmployeeRepository) FindDepartmentManagers(id uuid.UUID) ([]employee.ReportingChainEntry, error) {
+	var rows []struct {
+		employee.Employee
+		DepartmentName string
+		Level          int
+	}
+
+	query := `
+	WITH RECURSIVE chain AS (
+		-- Base case: departamento do colaborador
+		SELECT
+			d.id,
+			d.parent_department_id,
+			d.manager_id,
+			0 as level,
+			ARRAY[d.id::text] as path
+		FROM employees e
+		INNER JOIN departments d ON d.id = e.department_id AND d.deleted_at IS NULL
+		WHERE e.id = $1
+		AND e.deleted_at IS NULL
+
+		UNION ALL
+
+		-- Recursive case: departamento pai
+		SELECT
+			p.id,
+			p.parent_department_id,
+			p.manager_id,
+			c.level + 1,
+			c.path || p.id::text
+		FROM departments p
+		INNER JOIN chain c ON p.id = c.parent_department_id
+		WHERE p.deleted_at IS NULL
+		AND NOT p.id::text = ANY(c.path)  -- Previne ciclos
+	)
+	SELECT m.*, md.name AS department_name, c.level
+	FROM chain c
+	INNER JOIN employees m ON m.id = c.manager_id AND m.deleted_at IS NULL
+	LEFT JOIN departments md ON md.id = m.department_id
+	ORDER BY c.level
+	`
+
+	if err := r.db.Raw(query, id).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]employee.ReportingChainEntry, len(rows))
+	for i, row := range rows {
+		result[i] = employee.ReportingChainEntry{
+			Employee:       row.Employee,
+			DepartmentName: row.DepartmentName,
+			Level:          row.Level,
+		}
+	}
+	return result, nil
+}
+
+// subordinatesQuery selects every employee under a manager with a single CTE recursive query.
+// An employee reports directly to the manager of their department, except the department
+// manager itself, who reports to the manager of the parent department.
+const subordinatesQuery = `
+	WITH RECURSIVE tree AS (
+		-- Base case: departamentos gerenciados diretamente
+		SELECT
+			d.id,
+			d.name,
+			d.manager_id,
+			p.manager_id as parent_manager_id,
+			ARRAY[d.id::text] as path
+		FROM departments d
+		LEFT JOIN departments p ON p.id = d.parent_department_id AND p.deleted_at IS NULL
+		WHERE d.manager_id = @manager
+		AND d.deleted_at IS NULL
+
+		UNION ALL
+
+		-- Recursive case: subdepartamentos
+		SELECT
+			c.id,
+			c.name,
+			c.manager_id,
+			t.manager_id,
+			t.path || c.id::text
+		FROM departments c
+		INNER JOIN tree t ON c.parent_department_id = t.id
+		WHERE c.deleted_at IS NULL
+		AND NOT c.id::text = ANY(t.path)  -- Previne ciclos
+	),
+	scope AS (
+		-- A department can be reached twice when the manager also manages an ancestor
+		SELECT DISTINCT ON (id) id, name, manager_id, parent_manager_id
+		FROM tree
+		ORDER BY id
+	),
+	subordinates AS (
+		SELECT
+			e.*,
+			s.name as department_name,
+			COALESCE(CASE
+				WHEN e.id = s.manager_id THEN s.parent_manager_id = @manager
+				ELSE s.manager_id = @manager
+			END, false) as direct
+		FROM employees e
+		INNER JOIN scope s ON e.department_id = s.id
+		WHERE e.deleted_at IS NULL
+		AND e.id <> @manager
+	)
+`
+
+// FindSubordinates retrieves a page of subordinates and the total count in one round trip
+func (r *EmployeeRepository) FindSubordinates(managerID uuid.UUID, filters employee.SubordinateFilters) ([]employee.Subordinate, int64, error) {
+	var rows []struct {
+		employee.Employee
+		DepartmentName string
+		Direct         bool
+		TotalCount     int64
+	}
+
+	args := []interface{}{sql.Named("manager", managerID)}
+	conditions := []string{"TRUE"}
+	if filters.Name != nil && *filters.Name != "" {
+		conditions = append(conditions, "f_unaccent(name) ILIKE f_unaccent(@name)")
+		args = append(args, sql.Named("name", "%"+*filters.Name+"%"))
+	}
+	if filters.DepartmentID != nil {
+		conditions = append(conditions, "department_id = @department")
+		args = append(args, sql.Named("department", *filters.DepartmentID))
+	}
+	if filters.DirectOnly {
+		conditions = append(conditions, "direct")
+	}
+	where := strings.Join(conditions, " AND ")
+
+	offset := (filters.Page - 1) * filters.PageSize
+	args = append(args, sql.Named("limit", filters.PageSize), sql.Named("offset", offset))
+
+	query := subordinatesQuery + `
+	SELECT *, COUNT(*) OVER() as total_count
+	FROM subordinates
+	WHERE ` + where + `
+	ORDER BY name, id
+	LIMIT @limit OFFSET @offset
+	`
+
+	if err := r.db.Raw(query, args...).Scan(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	if len(rows) > 0 {
+		total = rows[0].TotalCount
+	} else if offset > 0 {
+		// Past the last page the window function has no row to report the total on
+		countQuery := subordinatesQuery + `SELECT COUNT(*) FROM subordinates WHERE ` + where
+		if err := r.db.Raw(countQuery, args...).Scan(&total).Error; err != nil {
+			return nil, 0, err
+		}
+	}
+
+	result := make([]employee.Subordinate, len(rows))
+	for i, row := range rows {
+		result[i] = employee.Subordinate{
+			Employee:       row.Employee,
+			DepartmentName: row.DepartmentName,
+			Direct:         row.Direct,
+		}
+	}
+	return result, total, nil
+}
+
+func (r *EmployeeRepository) FindByDepartmentIDs(departmentIDs []uuid.UUID, limitPerDepartment int) ([]employee.Employee, error) {
 	var employees []employee.Employee
 	if len(departmentIDs) == 0 {
 		return employees, nil
 	}
-	err := r.db.Where("department_id IN ?", departmentIDs).Find(&employees).Error
+	if limitPerDepartment <= 0 {
+		err := r.db.Where("department_id IN ?", departmentIDs).Order("name, id").Find(&employees).Error
+		return employees, err
+	}
+
+	// Number the employees of each department by name and keep the first ones, so
+	// every department is capped in the same query
+	ranked := r.db.Model(&employee.Employee{}).
+		Select("employees.*, ROW_NUMBER() OVER (PARTITION BY department_id ORDER BY name, id) AS position").
+		Where("department_id IN ?", departmentIDs)
+	err := r.db.Unscoped().Table("(?) AS ranked", ranked).
+		Where("position <= ?", limitPerDepartment).
+		Order("name, id").
+		Find(&employees).Error
 	return employees, err
 }
 
+// indexDocuments fills the blind indexes of the employee's CPF and RG before
+// they are written
+func (r *EmployeeRepository) indexDocuments(emp *employee.Employee) {
+	emp.CPFHash = r.indexes.cpf(emp.CPF)
+	emp.CPFPrefixHashes = r.indexes.cpfPrefixes(emp.CPF)
+	emp.RGHash = r.indexes.rgPtr(emp.RG)
+}
+
 func (r *EmployeeRepository) Create(emp *employee.Employee) error {
-	return r.db.Create(emp).Error
+	r.indexDocuments(emp)
+	return translateError(r.db.Create(emp).Error, "employee")
 }
 
 func (r *EmployeeRepository) Update(emp *employee.Employee) error {
-	return r.db.Save(emp).Error
+	r.indexDocuments(emp)
+	return updateVersioned(r.db, emp, &emp.Version, "employee")
+}
+
+func (r *EmployeeRepository) UpdateFields(emp *employee.Employee, fields ...string) error {
+	r.indexDocuments(emp)
+	// A document is never written without its index
+	columns := append([]string(nil), fields...)
+	for _, field := range fields {
+		if field == "cpf" || field == "rg" {
+			columns = append(columns, field+"_hash")
+		}
+		if field == "cpf" {
+			columns = append(columns, "cpf_prefix_hashes")
+		}
+	}
+	return updateVersioned(r.db, emp, &emp.Version, "employee", columns...)
+}
+
+func (r *EmployeeRepository) LockVersion(id uuid.UUID, version int64) error {
+	var ids []uuid.UUID
+	err := r.db.Model(&employee.Employee{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND version = ?", id, version).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return translateError(err, "employee")
+	}
+	if len(ids) == 0 {
+		return versionMismatch("employee")
+	}
+	return nil
 }
 
 func (r *EmployeeRepository) Delete(id uuid.UUID) error {
-	return r.db.Delete(&employee.Employee{}, "id = ?", id).Error
+	return translateError(r.db.Delete(&employee.Employee{}, "id = ?", id).Error, "employee")
 }
 
-func (r *EmployeeRepository) FindWithFilters(filters employee.ListFilters) ([]employee.Employee, int64, error) {
+func (r *EmployeeRepository) ExistsByCPF(cpf string, excludeID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&employee.Employee{}).
+		Where("cpf_hash = ? AND id <> ?", r.indexes.cpf(cpf), excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *EmployeeRepository) ExistsByRG(rg string, excludeID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&employee.Employee{}).
+		Where("rg_hash = ? AND id <> ?", r.indexes.rg(rg), excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *EmployeeRepository) FindDeleted(page, pageSize int) ([]employee.Employee, int64, error) {
 	var employees []employee.Employee
 	var total int64
 
-	query := r.db.Model(&employee.Employee{})
+	query := r.db.Unscoped().Model(&employee.Employee{}).Where("deleted_at IS NOT NULL")
 
-	// Apply filters
-	if filters.Name != nil && *filters.Name != "" {
-		query = query.Where("name ILIKE ?", "%"+*filters.Name+"%")
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
 	}
-	if filters.CPF != nil && *filters.CPF != "" {
-		query = query.Where("cpf = ?", *filters.CPF)
+
+	offset := (page - 1) * pageSize
+	if err := query.Order("deleted_at DESC").Offset(offset).Limit(pageSize).Find(&employees).Error; err != nil {
+		return nil, 0, err
 	}
-	if filters.RG != nil && *filters.RG != "" {
-		query = query.Where("rg = ?", *filters.RG)
+
+	return employees, total, nil
+}
+
+func (r *EmployeeRepository) FindDeletedByID(id uuid.UUID) (*employee.Employee, error) {
+	var emp employee.Employee
+	err := r.db.Unscoped().First(&emp, "id = ? AND deleted_at IS NOT NULL", id).Error
+	if err != nil {
+		return nil, translateError(err, "deleted employee")
 	}
-	if filters.DepartmentID != nil {
-		query = query.Where("department_id = ?", *filters.DepartmentID)
+	return &emp, nil
+}
+
+func (r *EmployeeRepository) FindDeletedByCPF(cpf string) (*employee.Employee, error) {
+	var emp employee.Employee
+	err := r.db.Unscoped().
+		Where("cpf_hash = ? AND deleted_at IS NOT NULL", r.indexes.cpf(cpf)).
+		Order("deleted_at DESC").
+		First(&emp).Error
+	if err != nil {
+		return nil, translateError(err, "deleted employee")
 	}
+	return &emp, nil
+}
+
+func (r *EmployeeRepository) Restore(id uuid.UUID) error {
+	return translateError(r.db.Unscoped().Model(&employee.Employee{}).
+		Where("id = ? AND deleted_at IS NOT NULL AND anonymized_at IS NULL", id).
+		Update("deleted_at", nil).Error, "employee")
+}
+
+func (r *EmployeeRepository) Purge(id uuid.UUID) error {
+	return translateError(r.db.Unscoped().Delete(&employee.Employee{}, "id = ? AND deleted_at IS NOT NULL", id).Error, "employee")
+}
+
+// Anonymize writes a soft-deleted record, which the other updates would not
+// see. A record restored in the meantime is left alone.
+func (r *EmployeeRepository) Anonymize(emp *employee.Employee) error {
+	r.indexDocuments(emp)
+	return updateVersioned(r.db.Unscoped().Where("deleted_at IS NOT NULL"), emp, &emp.Version, "employee",
+		"name", "cpf", "cpf_hash", "cpf_prefix_hashes", "rg", "rg_hash", "anonymized_at")
+}
+
+func (r *EmployeeRepository) RecordEvent(event *employee.AuditEvent) error {
+	return translateError(r.db.Create(event).Error, "employee audit event")
+}
+
+func (r *EmployeeRepository) FindEvents(employeeID uuid.UUID) ([]employee.AuditEvent, error) {
+	var events []employee.AuditEvent
+	err := r.db.Where("employee_id = ?", employeeID).Order("created_at, id").Find(&events).Error
+	return events, err
+}
+
+func (r *EmployeeRepository) Transaction(fn func(repo employee.Repository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&EmployeeRepository{db: tx, indexes: r.indexes})
+	})
+}
+
+// employeeListRow is an employee read by a listing, with the joined columns it
+// may be sorted by
+type employeeListRow struct {
+	employee.Employee
+	DepartmentName string
+}
+
+// employeeSortKeys maps employee.SortFields to their columns
+var employeeSortKeys = map[string]sortKey[employeeListRow]{
+	"name":            {column: "employees.name", value: func(r *employeeListRow) any { return r.Name }},
+	"department_name": {column: "COALESCE(d.name, '')", joined: true, value: func(r *employeeListRow) any { return r.DepartmentName }},
+	"created_at":      {column: "employees.created_at", value: func(r *employeeListRow) any { return r.CreatedAt }},
+	"updated_at":      {column: "employees.updated_at", value: func(r *employeeListRow) any { return r.UpdatedAt }},
+}
+
+// listEmployees selects the employees of a listing, joining their department
+// when the order needs it
+func (r *EmployeeRepository) listEmployees(filters employee.ListFilters) *gorm.DB {
+	query := r.applyFilters(r.db.Model(&employee.Employee{}), filters)
+	if !needsJoin(employeeSortKeys, filters.Sort) {
+		return query.Select("employees.*")
+	}
+	return query.
+		Select("employees.*, COALESCE(d.name, '') AS department_name").
+		Joins("LEFT JOIN departments AS d ON d.id = employees.department_id AND d.deleted_at IS NULL")
+}
+
+func (r *EmployeeRepository) FindWithFilters(filters employee.ListFilters) ([]employee.Employee, int64, error) {
+	var rows []employeeListRow
+	var total int64
 
 	// Count total
-	if err := query.Count(&total).Error; err != nil {
+	if err := r.applyFilters(r.db.Model(&employee.Employee{}), filters).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
-	// Apply pagination
+	// Apply order and pagination
 	offset := (filters.Page - 1) * filters.PageSize
-	if err := query.Offset(offset).Limit(filters.PageSize).Find(&employees).Error; err != nil {
+	query := applySort(r.listEmployees(filters), "employees", employeeSortKeys, filters.Sort, false)
+	if err := query.Offset(offset).Limit(filters.PageSize).Find(&rows).Error; err != nil {
 		return nil, 0, err
 	}
 
+	employees := make([]employee.Employee, len(rows))
+	for i, row := range rows {
+		employees[i] = row.Employee
+	}
 	return employees, total, nil
 }
+
+func (r *EmployeeRepository) FindPageWithFilters(filters employee.ListFilters, keyset pagination.Keyset) ([]employee.Employee, []pagination.Cursor, error) {
+	query, err := applyKeyset(r.listEmployees(filters), "employees", employeeSortKeys, filters.Sort, keyset)
+	if err != nil {
+		return nil, nil, err
+	}
+	var rows []employeeListRow
+	if err := query.Find(&rows).Error; err != nil {
+		return nil, nil, err
+	}
+
+	employees := make([]employee.Employee, len(rows))
+	for i, row := range rows {
+		employees[i] = row.Employee
+	}
+	cursors := rowCursors(rows, func(r *employeeListRow) uuid.UUID { return r.ID }, employeeSortKeys, filters.Sort)
+	return employees, cursors, nil
+}
+
+func (r *EmployeeRepository) CountWithFilters(filters employee.ListFilters) (int64, error) {
+	var total int64
+	err := r.applyFilters(r.db.Model(&employee.Employee{}), filters).Count(&total).Error
+	return total, err
+}
+
+func (r *EmployeeRepository) StreamWithFilters(filters employee.ListFilters, fn func(employee.ExportRow) error) error {
+	query := r.applyFilters(r.db.Model(&employee.Employee{}), filters).
+		Select("employees.*, COALESCE(d.name, '') AS department_name, COALESCE(m.name, '') AS manager_name").
+		Joins("LEFT JOIN departments AS d ON d.id = employees.department_id AND d.deleted_at IS NULL").
+		Joins("LEFT JOIN employees AS m ON m.id = d.manager_id AND m.deleted_at IS NULL").
+		Order("employees.name, employees.id")
+
+	rows, err := query.Rows()
+	if err != nil {
+		return translateError(err, "employee")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row employee.ExportRow
+		if err := r.db.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// applyFilters narrows a query on the employees table down to the list
+// filters; columns are qualified so the query may join other tables. CPF and
+// RG are matched whole, through their blind indexes.
+func (r *EmployeeRepository) applyFilters(query *gorm.DB, filters employee.ListFilters) *gorm.DB {
+	if filters.Name != nil && *filters.Name != "" {
+		query = query.Where("f_unaccent(employees.name) ILIKE f_unaccent(?)", "%"+*filters.Name+"%")
+	}
+	if filters.CPF != nil && *filters.CPF != "" {
+		query = query.Where("employees.cpf_hash = ?", r.indexes.cpf(*filters.CPF))
+	}
+	if filters.RG != nil && *filters.RG != "" {
+		query = query.Where("employees.rg_hash = ?", r.indexes.rg(*filters.RG))
+	}
+	if filters.DepartmentID != nil {
+		query = query.Where("employees.department_id = ?", *filters.DepartmentID)
+	}
+	return query
+}
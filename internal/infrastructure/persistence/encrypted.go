@@ -0,0 +1,111 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"api-employees-and-departments/internal/domain/search"
+	"api-employees-and-departments/internal/domain/validators"
+	"api-employees-and-departments/internal/infrastructure/encryption"
+
+	"gorm.io/gorm/schema"
+)
+
+// RegisterEncryption stores the string fields tagged serializer:encrypted, such
+// as the employee CPF and RG, encrypted with keyring. It must be called before
+// the first query on a model with such fields.
+func RegisterEncryption(keyring *encryption.Keyring) {
+	schema.RegisterSerializer("encrypted", encryptedSerializer{keyring: keyring})
+}
+
+// encryptedSerializer encrypts string and *string fields on the way to the
+// database and decrypts them on the way back. A nil *string stays NULL.
+type encryptedSerializer struct {
+	keyring *encryption.Keyring
+}
+
+func (s encryptedSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var stored string
+	switch v := dbValue.(type) {
+	case nil:
+		field.ReflectValueOf(ctx, dst).Set(reflect.Zero(field.FieldType))
+		return nil
+	case string:
+		stored = v
+	case []byte:
+		stored = string(v)
+	default:
+		return fmt.Errorf("cannot decrypt %T into %s", dbValue, field.Name)
+	}
+
+	plaintext, err := s.keyring.Decrypt(stored)
+	if err != nil {
+		return fmt.Errorf("decrypting %s: %w", field.Name, err)
+	}
+	value := reflect.ValueOf(plaintext)
+	if field.FieldType.Kind() == reflect.Ptr {
+		value = reflect.ValueOf(&plaintext)
+	}
+	field.ReflectValueOf(ctx, dst).Set(value)
+	return nil
+}
+
+func (s encryptedSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	switch v := fieldValue.(type) {
+	case string:
+		return s.keyring.Encrypt(v)
+	case *string:
+		if v == nil {
+			return nil, nil
+		}
+		return s.keyring.Encrypt(*v)
+	default:
+		return nil, fmt.Errorf("cannot encrypt %T of %s", fieldValue, field.Name)
+	}
+}
+
+// documentIndexes computes the blind indexes of employee documents. RGs are
+// indexed normalized, so the same RG typed with or without punctuation is
+// found and kept unique either way.
+type documentIndexes struct {
+	keyring *encryption.Keyring
+}
+
+// cpf indexes a CPF; the empty CPF of an anonymized employee has no index
+func (d documentIndexes) cpf(cpf string) string {
+	if cpf == "" {
+		return ""
+	}
+	return d.keyring.BlindIndex(cpf)
+}
+
+// cpfPrefix indexes the first digits of a CPF. Prefixes are indexed apart
+// from whole documents, so equal digits never share an index across kinds.
+func (d documentIndexes) cpfPrefix(prefix string) string {
+	return d.keyring.BlindIndex("cpf-prefix:" + prefix)
+}
+
+// cpfPrefixes indexes every searchable prefix of a CPF; an empty CPF has none
+func (d documentIndexes) cpfPrefixes(cpf string) []string {
+	indexes := []string{}
+	for _, n := range search.CPFPrefixLengths {
+		if n < len(cpf) {
+			indexes = append(indexes, d.cpfPrefix(cpf[:n]))
+		}
+	}
+	return indexes
+}
+
+func (d documentIndexes) rg(rg string) string {
+	return d.keyring.BlindIndex(validators.NormalizeRG(rg))
+}
+
+// rgPtr indexes an optional RG; a missing or empty one has no index
+func (d documentIndexes) rgPtr(rg *string) *string {
+	if rg == nil || *rg == "" {
+		return nil
+	}
+	index := d.rg(*rg)
+	return &index
+}
@@ -0,0 +1,120 @@
+package persistence
+
+import (
+	"errors"
+	"strings"
+
+	domainErrors "api-employees-and-departments/internal/domain/errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// PostgreSQL error codes
+// See https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+)
+
+// uniqueConstraints maps unique constraints and indexes to the field and domain
+// error they protect
+var uniqueConstraints = map[string]struct {
+	field string
+	code  string
+	err   error
+}{
+	"uk_cpf":                  {"cpf", "cpf.duplicate", domainErrors.ErrDuplicateCPF},
+	"uk_employees_cpf_active": {"cpf", "cpf.duplicate", domainErrors.ErrDuplicateCPF},
+	"uk_rg":                   {"rg", "rg.duplicate", domainErrors.ErrDuplicateRG},
+	"uk_employees_rg_active":  {"rg", "rg.duplicate", domainErrors.ErrDuplicateRG},
+
+	"uk_employees_cpf_hash_active": {"cpf", "cpf.duplicate", domainErrors.ErrDuplicateCPF},
+	"uk_employees_rg_hash_active":  {"rg", "rg.duplicate", domainErrors.ErrDuplicateRG},
+}
+
+// foreignKeyFields maps foreign keys to the field holding the reference
+var foreignKeyFields = map[string]string{
+	"fk_department":        "department_id",
+	"fk_parent_department": "parent_department_id",
+}
+
+// resourceCode turns a resource name into the prefix of its error codes
+// ("deleted employee" becomes "deleted_employee")
+func resourceCode(resource string) string {
+	return strings.ReplaceAll(resource, " ", "_")
+}
+
+// notFound builds the domain error for a missing resource
+func notFound(resource string) error {
+	return &domainErrors.NotFoundError{
+		Code:    resourceCode(resource) + ".not_found",
+		Message: resource + " not found",
+	}
+}
+
+// versionMismatch builds the domain error for a write based on a stale version
+func versionMismatch(resource string) error {
+	return &domainErrors.PreconditionFailedError{
+		Code:    resourceCode(resource) + ".version_mismatch",
+		Message: resource + " was modified by another request",
+	}
+}
+
+// translateError converts database errors into the typed domain errors so the
+// upper layers never have to know about GORM or PostgreSQL. resource names the
+// entity in not found messages.
+func translateError(err error, resource string) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return notFound(resource)
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	switch pgErr.Code {
+	case pgUniqueViolation:
+		if unique, ok := uniqueConstraints[pgErr.ConstraintName]; ok {
+			return &domainErrors.ConflictError{
+				Field:   unique.field,
+				Code:    unique.code,
+				Message: unique.err.Error(),
+				Err:     unique.err,
+			}
+		}
+		return &domainErrors.ConflictError{
+			Code:    resourceCode(resource) + ".already_exists",
+			Message: resource + " already exists",
+		}
+
+	case pgForeignKeyViolation:
+		field := foreignKeyFields[pgErr.ConstraintName]
+		// Deleting a referenced row conflicts with existing data, while inserting
+		// or updating a dangling reference is invalid input
+		if strings.HasPrefix(pgErr.Message, "update or delete") {
+			return &domainErrors.ConflictError{
+				Field:   field,
+				Code:    resourceCode(resource) + ".in_use",
+				Message: resource + " is still referenced by other records",
+			}
+		}
+		referenced := "record"
+		if field != "" {
+			referenced = strings.ReplaceAll(strings.TrimSuffix(field, "_id"), "_", " ")
+		}
+		return &domainErrors.ValidationError{
+			Field:   field,
+			Code:    "reference.not_found",
+			Params:  domainErrors.Params{"reference": referenced},
+			Message: "referenced " + referenced + " does not exist",
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,112 @@
+package persistence
+
+import (
+	"fmt"
+	"strings"
+
+	"api-employees-and-departments/internal/domain/pagination"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// sortKey is a field a listing of rows of type R can be ordered by
+type sortKey[R any] struct {
+	column string // SQL expression, never NULL so it compares with =, < and >
+	joined bool   // the column comes from the joins of the listing
+	value  func(row *R) any
+}
+
+// needsJoin reports whether ordering by sort reads a joined column
+func needsJoin[R any](keys map[string]sortKey[R], sort []pagination.SortField) bool {
+	for _, field := range sort {
+		if keys[field.Field].joined {
+			return true
+		}
+	}
+	return false
+}
+
+// applySort orders query by the sort fields and then by ID, so the order is
+// total. backward reverses every direction, to read keyset pages backwards.
+func applySort[R any](query *gorm.DB, table string, keys map[string]sortKey[R], sort []pagination.SortField, backward bool) *gorm.DB {
+	for _, field := range sort {
+		query = query.Order(keys[field.Field].column + direction(field.Desc != backward))
+	}
+	return query.Order(table + ".id" + direction(backward))
+}
+
+func direction(desc bool) string {
+	if desc {
+		return " DESC"
+	}
+	return " ASC"
+}
+
+// applyKeyset keeps the rows past the cursor in the order of sort and orders
+// them in the direction of the traversal, reading one row more than the page so
+// the caller can tell whether another page follows
+func applyKeyset[R any](query *gorm.DB, table string, keys map[string]sortKey[R], sort []pagination.SortField, keyset pagination.Keyset) (*gorm.DB, error) {
+	if keyset.Cursor != nil {
+		condition, args, err := keysetCondition(table, keys, sort, keyset)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where(condition, args...)
+	}
+	return applySort(query, table, keys, sort, keyset.Backward).Limit(keyset.Limit + 1), nil
+}
+
+// keysetCondition compares the row with the cursor key by key: a row comes
+// after it when it is past the cursor on the first key where they differ, e.g.
+// (a > ?) OR (a = ? AND b < ?) OR (a = ? AND b = ? AND id > ?).
+func keysetCondition[R any](table string, keys map[string]sortKey[R], sort []pagination.SortField, keyset pagination.Keyset) (string, []any, error) {
+	cursor := keyset.Cursor
+	if len(cursor.Values) != len(sort) {
+		return "", nil, fmt.Errorf("cursor has %d sort values, the listing is sorted by %d fields", len(cursor.Values), len(sort))
+	}
+
+	columns := make([]string, 0, len(sort)+1)
+	values := make([]any, 0, len(sort)+1)
+	desc := make([]bool, 0, len(sort)+1)
+	for i, field := range sort {
+		columns = append(columns, keys[field.Field].column)
+		values = append(values, cursor.Values[i])
+		desc = append(desc, field.Desc != keyset.Backward)
+	}
+	columns = append(columns, table+".id")
+	values = append(values, cursor.ID)
+	desc = append(desc, keyset.Backward)
+
+	var alternatives []string
+	var args []any
+	for i := range columns {
+		var terms []string
+		for j := 0; j < i; j++ {
+			terms = append(terms, columns[j]+" = ?")
+			args = append(args, values[j])
+		}
+		op := " > ?"
+		if desc[i] {
+			op = " < ?"
+		}
+		terms = append(terms, columns[i]+op)
+		args = append(args, values[i])
+		alternatives = append(alternatives, "("+strings.Join(terms, " AND ")+")")
+	}
+	return "(" + strings.Join(alternatives, " OR ") + ")", args, nil
+}
+
+// rowCursors returns the cursor of every row for the sort fields
+func rowCursors[R any](rows []R, id func(row *R) uuid.UUID, keys map[string]sortKey[R], sort []pagination.SortField) []pagination.Cursor {
+	cursors := make([]pagination.Cursor, len(rows))
+	for i := range rows {
+		row := &rows[i]
+		values := make([]any, len(sort))
+		for j, field := range sort {
+			values[j] = keys[field.Field].value(row)
+		}
+		cursors[i] = pagination.Cursor{ID: id(row), Values: values}
+	}
+	return cursors
+}
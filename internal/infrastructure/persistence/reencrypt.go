@@ -0,0 +1,139 @@
+package persistence
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"api-employees-and-departments/internal/infrastructure/encryption"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ReencryptStats counts the employees visited by ReencryptEmployees
+type ReencryptStats struct {
+	Scanned int64
+	Updated int64
+}
+
+// CountUnindexedEmployees counts the employees, soft-deleted ones included,
+// whose documents lack a blind index: rows written before the migrations V6
+// and V8, which the lookups, the search and the uniqueness indexes do not see
+// until ReencryptEmployees runs
+func CountUnindexedEmployees(db *gorm.DB) (int64, error) {
+	var count int64
+	err := db.Table("employees").
+		Where("cpf_hash IS NULL OR cpf_prefix_hashes IS NULL OR (rg IS NOT NULL AND rg_hash IS NULL)").
+		Count(&count).Error
+	return count, err
+}
+
+// ReencryptEmployees rewrites the CPF and RG of every employee, soft-deleted
+// ones included, that is still in plaintext or was encrypted with a key other
+// than the active one, and fixes any blind index that is missing or stale.
+// Employees are read in batches by ID and each batch is written in its own
+// transaction, so an interrupted run can simply be started again. progress, if
+// not nil, is called after every batch.
+//
+// The versions and update times are left alone, since the data does not
+// change, and a row changed while its batch runs is skipped: its new values
+// were already written with the active key.
+func ReencryptEmployees(db *gorm.DB, keyring *encryption.Keyring, batchSize int, progress func(ReencryptStats)) (ReencryptStats, error) {
+	indexes := documentIndexes{keyring: keyring}
+	var stats ReencryptStats
+	var last uuid.UUID
+
+	for {
+		// Read through the table, not the model, to see the stored values
+		var rows []struct {
+			ID      uuid.UUID
+			CPF     string
+			RG      *string
+			CPFHash *string
+			RGHash  *string
+			// nil while the column is NULL
+			CPFPrefixHashes []string `gorm:"serializer:json"`
+		}
+		err := db.Table("employees").
+			Select("id, cpf, rg, cpf_hash, rg_hash, cpf_prefix_hashes").
+			Where("id > ?", last).
+			Order("id").
+			Limit(batchSize).
+			Find(&rows).Error
+		if err != nil {
+			return stats, err
+		}
+		if len(rows) == 0 {
+			return stats, nil
+		}
+
+		var updated int64
+		err = db.Transaction(func(tx *gorm.DB) error {
+			updated = 0
+			for _, row := range rows {
+				cpf, err := keyring.Decrypt(row.CPF)
+				if err != nil {
+					return fmt.Errorf("employee %s: %w", row.ID, err)
+				}
+				var rg *string
+				if row.RG != nil {
+					plaintext, err := keyring.Decrypt(*row.RG)
+					if err != nil {
+						return fmt.Errorf("employee %s: %w", row.ID, err)
+					}
+					rg = &plaintext
+				}
+
+				cpfHash, rgHash := indexes.cpf(cpf), indexes.rgPtr(rg)
+				prefixHashes := indexes.cpfPrefixes(cpf)
+				if !keyring.NeedsReencryption(row.CPF) && (row.RG == nil || !keyring.NeedsReencryption(*row.RG)) &&
+					equalPtr(row.CPFHash, &cpfHash) && equalPtr(row.RGHash, rgHash) &&
+					row.CPFPrefixHashes != nil && slices.Equal(row.CPFPrefixHashes, prefixHashes) {
+					continue
+				}
+
+				prefixes, err := json.Marshal(prefixHashes)
+				if err != nil {
+					return err
+				}
+				values := map[string]interface{}{
+					"cpf_hash": cpfHash, "cpf_prefix_hashes": string(prefixes), "rg_hash": rgHash, "rg": nil,
+				}
+				if values["cpf"], err = keyring.Encrypt(cpf); err != nil {
+					return err
+				}
+				if rg != nil {
+					if values["rg"], err = keyring.Encrypt(*rg); err != nil {
+						return err
+					}
+				}
+				result := tx.Table("employees").
+					Where("id = ? AND cpf = ? AND rg IS NOT DISTINCT FROM ?", row.ID, row.CPF, row.RG).
+					Updates(values)
+				if result.Error != nil {
+					return fmt.Errorf("employee %s: %w", row.ID, translateError(result.Error, "employee"))
+				}
+				updated += result.RowsAffected
+			}
+			return nil
+		})
+		if err != nil {
+			return stats, err
+		}
+
+		stats.Scanned += int64(len(rows))
+		stats.Updated += updated
+		last = rows[len(rows)-1].ID
+		if progress != nil {
+			progress(stats)
+		}
+	}
+}
+
+func equalPtr(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,141 @@
+package persistence
+
+import (
+	"database/sql"
+	"strings"
+
+	"api-employees-and-departments/internal/domain/search"
+	"api-employees-and-departments/internal/infrastructure/encryption"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type SearchRepository struct {
+	db      *gorm.DB
+	indexes documentIndexes
+}
+
+func NewSearchRepository(db *gorm.DB, keyring *encryption.Keyring) search.Repository {
+	return &SearchRepository{db: db, indexes: documentIndexes{keyring: keyring}}
+}
+
+// Names are compared with f_unaccent (V5), whose GIN trigram indexes serve the
+// word similarity operator <%: a name matches when some part of it is at least
+// pg_trgm.word_similarity_threshold (0.6 by default) similar to the query.
+const (
+	searchEmployeeNames = `
+		SELECT 'employee' AS kind, e.id, e.name, 'name' AS matched_field,
+			word_similarity(f_unaccent(@text), f_unaccent(e.name)) AS score,
+			e.department_id, COALESCE(d.name, '') AS department_name
+		FROM employees e
+		LEFT JOIN departments d ON d.id = e.department_id AND d.deleted_at IS NULL
+		WHERE e.deleted_at IS NULL
+		AND f_unaccent(@text) <% f_unaccent(e.name)`
+
+	// Documents are encrypted, so they are matched whole by their blind indexes,
+	// and CPFs also by the indexes of their first digits, scored by the share of
+	// the CPF given
+	searchEmployeeCPFs = `
+		SELECT 'employee' AS kind, e.id, e.name, 'cpf' AS matched_field,
+			1::float8 AS score,
+			e.department_id, COALESCE(d.name, '') AS department_name
+		FROM employees e
+		LEFT JOIN departments d ON d.id = e.department_id AND d.deleted_at IS NULL
+		WHERE e.deleted_at IS NULL
+		AND e.cpf_hash = @cpf_hash`
+
+	searchEmployeeCPFPrefixes = `
+		SELECT 'employee' AS kind, e.id, e.name, 'cpf' AS matched_field,
+			CAST(@cpf_prefix_score AS float8) AS score,
+			e.department_id, COALESCE(d.name, '') AS department_name
+		FROM employees e
+		LEFT JOIN departments d ON d.id = e.department_id AND d.deleted_at IS NULL
+		WHERE e.deleted_at IS NULL
+		AND e.cpf_prefix_hashes @> jsonb_build_array(CAST(@cpf_prefix_hash AS text))`
+
+	searchEmployeeRGs = `
+		SELECT 'employee' AS kind, e.id, e.name, 'rg' AS matched_field,
+			1::float8 AS score,
+			e.department_id, COALESCE(d.name, '') AS department_name
+		FROM employees e
+		LEFT JOIN departments d ON d.id = e.department_id AND d.deleted_at IS NULL
+		WHERE e.deleted_at IS NULL
+		AND e.rg_hash = @rg_hash`
+
+	searchDepartmentNames = `
+		SELECT 'department' AS kind, d.id, d.name, 'name' AS matched_field,
+			word_similarity(f_unaccent(@text), f_unaccent(d.name)) AS score,
+			NULL::uuid AS department_id, '' AS department_name
+		FROM departments d
+		WHERE d.deleted_at IS NULL
+		AND f_unaccent(@text) <% f_unaccent(d.name)`
+)
+
+// Search runs one query per searched field and keeps the best match of every
+// record, so an employee found by name and CPF is listed once
+func (r *SearchRepository) Search(query search.Query) ([]search.Result, error) {
+	var parts []string
+	if query.Kind != search.KindDepartment {
+		parts = append(parts, searchEmployeeNames)
+		switch {
+		case len(query.CPF) == 11:
+			parts = append(parts, searchEmployeeCPFs)
+		case query.CPF != "":
+			parts = append(parts, searchEmployeeCPFPrefixes)
+		}
+		if query.RG != "" {
+			parts = append(parts, searchEmployeeRGs)
+		}
+	}
+	if query.Kind != search.KindEmployee {
+		parts = append(parts, searchDepartmentNames)
+	}
+
+	sqlQuery := `
+	WITH matches AS (` + strings.Join(parts, "\n\t\tUNION ALL") + `
+	)
+	SELECT * FROM (
+		SELECT DISTINCT ON (kind, id) *
+		FROM matches
+		ORDER BY kind, id, score DESC
+	) best
+	ORDER BY score DESC, name, id
+	LIMIT @limit
+	`
+
+	var rows []struct {
+		Kind           string
+		ID             uuid.UUID
+		Name           string
+		MatchedField   string
+		Score          float64
+		DepartmentID   *uuid.UUID
+		DepartmentName string
+	}
+	err := r.db.Raw(sqlQuery,
+		sql.Named("text", query.Text),
+		sql.Named("cpf_hash", r.indexes.cpf(query.CPF)),
+		sql.Named("cpf_prefix_hash", r.indexes.cpfPrefix(query.CPF)),
+		sql.Named("cpf_prefix_score", float64(len(query.CPF))/11),
+		sql.Named("rg_hash", r.indexes.rg(query.RG)),
+		sql.Named("limit", query.Limit),
+	).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]search.Result, len(rows))
+	for i, row := range rows {
+		results[i] = search.Result{
+			Kind:           search.Kind(row.Kind),
+			ID:             row.ID,
+			Name:           row.Name,
+			MatchedField:   row.MatchedField,
+			Score:          row.Score,
+			DepartmentID:   row.DepartmentID,
+			DepartmentName: row.DepartmentName,
+		}
+	}
+	return results, nil
+}
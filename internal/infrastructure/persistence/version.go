@@ -0,0 +1,30 @@
+package persistence
+
+import "gorm.io/gorm"
+
+// updateVersioned writes model in a single UPDATE conditioned on the version it
+// was read at, so a write based on a stale version fails instead of silently
+// overwriting the newer one. columns limits the written columns (all of them
+// when empty). On success the version in model is incremented.
+func updateVersioned(db *gorm.DB, model any, version *int64, resource string, columns ...string) error {
+	expected := *version
+	*version = expected + 1
+
+	query := db.Model(model).Where("version = ?", expected)
+	if len(columns) == 0 {
+		query = query.Select("*").Omit("id", "created_at", "deleted_at")
+	} else {
+		query = query.Select(append(columns, "version", "updated_at"))
+	}
+
+	result := query.Updates(model)
+	if result.Error != nil {
+		*version = expected
+		return translateError(result.Error, resource)
+	}
+	if result.RowsAffected == 0 {
+		*version = expected
+		return versionMismatch(resource)
+	}
+	return nil
+}
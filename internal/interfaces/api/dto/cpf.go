@@ -0,0 +1,28 @@
+package dto
+
+import "api-employees-and-departments/internal/domain/validators"
+
+// CPFFormat is how responses write CPFs, chosen with the cpf_format query
+// parameter. Callers without access to personal data only get CPFMasked.
+type CPFFormat string
+
+const (
+	CPFDigits    CPFFormat = "digits"    // 11144477735
+	CPFFormatted CPFFormat = "formatted" // 111.444.777-35
+	CPFMasked    CPFFormat = "masked"    // ***.444.777-**
+)
+
+// CPFFormats are the values accepted by cpf_format
+var CPFFormats = []string{string(CPFDigits), string(CPFFormatted), string(CPFMasked)}
+
+// Apply writes a stored CPF in the format
+func (f CPFFormat) Apply(cpf string) string {
+	switch f {
+	case CPFDigits:
+		return cpf
+	case CPFFormatted:
+		return validators.FormatCPF(cpf)
+	default:
+		return validators.MaskCPF(cpf)
+	}
+}
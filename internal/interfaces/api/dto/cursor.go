@@ -0,0 +1,149 @@
+package dto
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"api-employees-and-departments/internal/domain/pagination"
+
+	"github.com/google/uuid"
+)
+
+// DefaultCursorLimit is the page size of keyset pagination when limit is not sent
+const DefaultCursorLimit = 20
+
+// CursorRequest holds the keyset pagination parameters of a listing, used
+// instead of page/page_size when any of them is sent. After and Before are
+// cursors taken from a previous response.
+type CursorRequest struct {
+	After        *string `json:"after,omitempty"`
+	Before       *string `json:"before,omitempty"`
+	Limit        int     `json:"limit,omitempty" binding:"omitempty,min=1,max=100"`
+	IncludeTotal bool    `json:"include_total,omitempty"`
+}
+
+// UsesCursor reports whether the request asked for keyset pagination
+func (r CursorRequest) UsesCursor() bool {
+	return r.After != nil || r.Before != nil || r.Limit != 0
+}
+
+// CursorResponse is a page of a keyset listing. A nil cursor means there is
+// nothing more in that direction; Total is only sent when include_total was.
+type CursorResponse struct {
+	Data       interface{} `json:"data"`
+	Limit      int         `json:"limit"`
+	NextCursor *string     `json:"next_cursor"`
+	PrevCursor *string     `json:"prev_cursor"`
+	Total      *int64      `json:"total,omitempty"`
+}
+
+// cursorToken is the content of a cursor: the ID of the row, its values for the
+// sort fields and the sort they belong to. It is only ever read back by the
+// API, so clients must treat the encoded form as opaque.
+type cursorToken struct {
+	ID     uuid.UUID `json:"id"`
+	Values []string  `json:"v,omitempty"`
+	Sort   string    `json:"s,omitempty"`
+}
+
+// EncodeCursor returns the token of a cursor read with the sort
+func EncodeCursor(cursor pagination.Cursor, sort []pagination.SortField) string {
+	token := cursorToken{ID: cursor.ID, Sort: FormatSort(sort)}
+	for _, value := range cursor.Values {
+		if t, ok := value.(time.Time); ok {
+			token.Values = append(token.Values, t.UTC().Format(time.RFC3339Nano))
+			continue
+		}
+		token.Values = append(token.Values, fmt.Sprint(value))
+	}
+
+	data, _ := json.Marshal(token)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor reads a cursor token, which must have been issued for the same
+// sort since the position it holds only makes sense in that order. Values of
+// pagination.TimeFields are parsed back into times, so a tampered token is
+// rejected here rather than by the database.
+func DecodeCursor(cursor string, sort []pagination.SortField) (pagination.Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return pagination.Cursor{}, err
+	}
+	var token cursorToken
+	if err := json.Unmarshal(data, &token); err != nil {
+		return pagination.Cursor{}, err
+	}
+	if token.ID == uuid.Nil {
+		return pagination.Cursor{}, errors.New("cursor without id")
+	}
+	if token.Sort != FormatSort(sort) || len(token.Values) != len(sort) {
+		return pagination.Cursor{}, errors.New("cursor issued for another sort")
+	}
+
+	values := make([]any, len(token.Values))
+	for i, value := range token.Values {
+		if !slices.Contains(pagination.TimeFields, sort[i].Field) {
+			values[i] = value
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return pagination.Cursor{}, fmt.Errorf("cursor value for %s: %w", sort[i].Field, err)
+		}
+		values[i] = parsed
+	}
+	return pagination.Cursor{ID: token.ID, Values: values}, nil
+}
+
+// ParseSort reads a comma-separated list of fields, each optionally prefixed
+// with - for descending order, e.g. "name,-created_at". It returns the first
+// entry that is not one of allowed or repeats a field, if any.
+func ParseSort(value string, allowed []string) ([]pagination.SortField, string) {
+	var fields []pagination.SortField
+	seen := make(map[string]bool)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		field := pagination.SortField{Field: strings.TrimPrefix(entry, "-"), Desc: strings.HasPrefix(entry, "-")}
+		if !slices.Contains(allowed, field.Field) || seen[field.Field] {
+			return nil, entry
+		}
+		seen[field.Field] = true
+		fields = append(fields, field)
+	}
+	return fields, ""
+}
+
+// FormatSort writes sort fields back in the syntax of ParseSort
+func FormatSort(fields []pagination.SortField) string {
+	entries := make([]string, len(fields))
+	for i, field := range fields {
+		entries[i] = field.Field
+		if field.Desc {
+			entries[i] = "-" + field.Field
+		}
+	}
+	return strings.Join(entries, ",")
+}
+
+// NewCursorResponse builds the response of a keyset page read with the sort
+func NewCursorResponse(data interface{}, sort []pagination.SortField, keyset pagination.Keyset, info pagination.Info) CursorResponse {
+	response := CursorResponse{Data: data, Limit: keyset.Limit, Total: info.Total}
+	if info.Next != nil {
+		next := EncodeCursor(*info.Next, sort)
+		response.NextCursor = &next
+	}
+	if info.Prev != nil {
+		prev := EncodeCursor(*info.Prev, sort)
+		response.PrevCursor = &prev
+	}
+	return response
+}
@@ -0,0 +1,52 @@
+package dto
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"api-employees-and-departments/internal/domain/pagination"
+
+	"github.com/google/uuid"
+)
+
+func TestDecodeCursor(t *testing.T) {
+	sort := []pagination.SortField{{Field: "created_at", Desc: true}, {Field: "name"}}
+	at := time.Date(2025, 3, 1, 12, 30, 0, 123456789, time.UTC)
+	id := uuid.New()
+
+	t.Run("round trip", func(t *testing.T) {
+		token := EncodeCursor(pagination.Cursor{ID: id, Values: []any{at, "Ana"}}, sort)
+		cursor, err := DecodeCursor(token, sort)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cursor.ID != id {
+			t.Errorf("ID = %v, want %v", cursor.ID, id)
+		}
+		if got, ok := cursor.Values[0].(time.Time); !ok || !got.Equal(at) {
+			t.Errorf("created_at = %#v, want %v", cursor.Values[0], at)
+		}
+		if cursor.Values[1] != "Ana" {
+			t.Errorf("name = %#v, want Ana", cursor.Values[1])
+		}
+	})
+
+	tampered := []struct {
+		name string
+		json string
+	}{
+		{"time that does not parse", `{"id":"` + id.String() + `","v":["yesterday","Ana"],"s":"-created_at,name"}`},
+		{"number instead of text", `{"id":"` + id.String() + `","v":[1,"Ana"],"s":"-created_at,name"}`},
+		{"missing value", `{"id":"` + id.String() + `","v":["2025-03-01T12:30:00Z"],"s":"-created_at,name"}`},
+		{"other sort", `{"id":"` + id.String() + `","v":["2025-03-01T12:30:00Z","Ana"],"s":"created_at,name"}`},
+	}
+	for _, tt := range tampered {
+		t.Run(tt.name, func(t *testing.T) {
+			token := base64.RawURLEncoding.EncodeToString([]byte(tt.json))
+			if _, err := DecodeCursor(token, sort); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
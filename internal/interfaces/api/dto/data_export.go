@@ -0,0 +1,80 @@
+package dto
+
+import (
+	"time"
+
+	"api-employees-and-departments/internal/domain/employee"
+
+	"github.com/google/uuid"
+)
+
+// DepartmentMembershipResponse is a period an employee spent in a department;
+// To is missing while it lasts
+type DepartmentMembershipResponse struct {
+	DepartmentID   uuid.UUID  `json:"department_id"`
+	DepartmentName string     `json:"department_name,omitempty"`
+	From           time.Time  `json:"from"`
+	To             *time.Time `json:"to,omitempty"`
+}
+
+// ManagedDepartmentResponse is a department the employee manages
+type ManagedDepartmentResponse struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+// AuditEventResponse is an entry of an employee's history. Fields names the
+// fields that changed, without their values.
+type AuditEventResponse struct {
+	ID           uuid.UUID `json:"id"`
+	Action       string    `json:"action" example:"updated"`
+	Fields       []string  `json:"fields,omitempty"`
+	DepartmentID uuid.UUID `json:"department_id"`
+	OccurredAt   time.Time `json:"occurred_at"`
+}
+
+// EmployeeDataExportResponse is everything held about an employee, as handed
+// over on a data subject access request
+type EmployeeDataExportResponse struct {
+	GeneratedAt           time.Time                      `json:"generated_at"`
+	Employee              EmployeeResponse               `json:"employee"`
+	DepartmentMemberships []DepartmentMembershipResponse `json:"department_memberships"`
+	ManagedDepartments    []ManagedDepartmentResponse    `json:"managed_departments"`
+	AuditHistory          []AuditEventResponse           `json:"audit_history"`
+}
+
+func ToEmployeeDataExportResponse(export *employee.DataExport, cpf CPFFormat) EmployeeDataExportResponse {
+	memberships := make([]DepartmentMembershipResponse, len(export.Memberships))
+	for i, m := range export.Memberships {
+		memberships[i] = DepartmentMembershipResponse{
+			DepartmentID:   m.DepartmentID,
+			DepartmentName: m.DepartmentName,
+			From:           m.From,
+			To:             m.To,
+		}
+	}
+
+	managed := make([]ManagedDepartmentResponse, len(export.ManagedDepartments))
+	for i, dept := range export.ManagedDepartments {
+		managed[i] = ManagedDepartmentResponse{ID: dept.ID, Name: dept.Name}
+	}
+
+	history := make([]AuditEventResponse, len(export.History))
+	for i, event := range export.History {
+		history[i] = AuditEventResponse{
+			ID:           event.ID,
+			Action:       string(event.Action),
+			Fields:       event.Fields,
+			DepartmentID: event.DepartmentID,
+			OccurredAt:   event.CreatedAt,
+		}
+	}
+
+	return EmployeeDataExportResponse{
+		GeneratedAt:           export.GeneratedAt,
+		Employee:              *ToEmployeeResponse(&export.Employee, cpf),
+		DepartmentMemberships: memberships,
+		ManagedDepartments:    managed,
+		AuditHistory:          history,
+	}
+}
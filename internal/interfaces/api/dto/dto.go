@@ -3,26 +3,45 @@ package dto
 import (
 	"api-employees-and-departments/internal/domain/department"
 	"api-employees-and-departments/internal/domain/employee"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
-// Employee DTOs
+// Employee DTOs. CPFs are accepted with or without their punctuation and are
+// stored as their 11 digits.
 type CreateEmployeeRequest struct {
 	Name         string    `json:"name" binding:"required" example:"João Silva"`
-	CPF          string    `json:"cpf" binding:"required,len=11" example:"11144477735"`
+	CPF          string    `json:"cpf" binding:"required" example:"111.444.777-35"`
 	RG           *string   `json:"rg,omitempty" example:"123456789"`
 	DepartmentID uuid.UUID `json:"department_id" binding:"required" example:"019a35a2-0fa7-79a3-bf4b-231280e082f3"`
 }
 
+// CreateEmployeeQuery decides what to do when the CPF belongs to a deleted employee:
+// unset answers 409 with the previous record, true restores it and false creates a new one
+type CreateEmployeeQuery struct {
+	Rehire *bool `form:"rehire"`
+}
+
 type UpdateEmployeeRequest struct {
 	Name         string    `json:"name" binding:"required" example:"João Silva"`
-	CPF          string    `json:"cpf" binding:"required,len=11" example:"11144477735"`
+	CPF          string    `json:"cpf" binding:"required" example:"111.444.777-35"`
 	RG           *string   `json:"rg,omitempty" example:"123456789"`
 	DepartmentID uuid.UUID `json:"department_id" binding:"required" example:"019a35a2-0fa7-79a3-bf4b-231280e082f3"`
 }
 
+// PatchEmployeeRequest is a JSON Merge Patch (RFC 7396): absent fields are kept,
+// null clears rg and is rejected by validation for the required fields
+type PatchEmployeeRequest struct {
+	Name         NullableString `json:"name" swaggertype:"string" example:"João Silva"`
+	CPF          NullableString `json:"cpf" swaggertype:"string" example:"111.444.777-35"`
+	RG           NullableString `json:"rg" swaggertype:"string" example:"123456789"`
+	DepartmentID NullableUUID   `json:"department_id" swaggertype:"string" example:"019a35a2-0fa7-79a3-bf4b-231280e082f3"`
+}
+
 type EmployeeResponse struct {
 	ID           uuid.UUID  `json:"id"`
 	Name         string     `json:"name"`
@@ -31,6 +50,8 @@ type EmployeeResponse struct {
 	DepartmentID uuid.UUID  `json:"department_id"`
 	CreatedAt    time.Time  `json:"created_at"`
 	UpdatedAt    time.Time  `json:"updated_at"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
+	AnonymizedAt *time.Time `json:"anonymized_at,omitempty"`
 }
 
 type EmployeeWithManagerResponse struct {
@@ -44,6 +65,19 @@ type EmployeeWithManagerResponse struct {
 	UpdatedAt    time.Time  `json:"updated_at"`
 }
 
+type ReportingChainEntryResponse struct {
+	Level          int       `json:"level"`
+	ID             uuid.UUID `json:"id"`
+	Name           string    `json:"name"`
+	DepartmentID   uuid.UUID `json:"department_id"`
+	DepartmentName string    `json:"department_name"`
+}
+
+type ReportingChainResponse struct {
+	EmployeeID uuid.UUID                     `json:"employee_id"`
+	Chain      []ReportingChainEntryResponse `json:"chain"`
+}
+
 // Department DTOs
 type CreateDepartmentRequest struct {
 	Name               string       `json:"name" binding:"required" example:"Tecnologia"`
@@ -57,6 +91,18 @@ type UpdateDepartmentRequest struct {
 	ParentDepartmentID NullableUUID `json:"parent_department_id,omitempty" swaggertype:"string"`
 }
 
+// PatchDepartmentRequest is a JSON Merge Patch (RFC 7396): absent fields are
+// kept and null on parent_department_id turns the department into a root
+type PatchDepartmentRequest struct {
+	Name               NullableString `json:"name" swaggertype:"string" example:"Tecnologia"`
+	ManagerID          NullableUUID   `json:"manager_id" swaggertype:"string" example:"019a35a2-79e8-770f-b92e-48558b88f4b5"`
+	ParentDepartmentID NullableUUID   `json:"parent_department_id" swaggertype:"string"`
+}
+
+type MoveDepartmentRequest struct {
+	ParentDepartmentID NullableUUID `json:"parent_department_id" swaggertype:"string" example:"019a35a2-0fa7-79a3-bf4b-231280e082f3"`
+}
+
 type DepartmentResponse struct {
 	ID                 uuid.UUID  `json:"id"`
 	Name               string     `json:"name"`
@@ -64,6 +110,7 @@ type DepartmentResponse struct {
 	ParentDepartmentID *uuid.UUID `json:"parent_department_id,omitempty"`
 	CreatedAt          time.Time  `json:"created_at"`
 	UpdatedAt          time.Time  `json:"updated_at"`
+	DeletedAt          *time.Time `json:"deleted_at,omitempty"`
 }
 
 type DepartmentWithHierarchyResponse struct {
@@ -73,14 +120,77 @@ type DepartmentWithHierarchyResponse struct {
 	ManagerName        string                             `json:"manager_name"`
 	ParentDepartmentID *uuid.UUID                         `json:"parent_department_id,omitempty"`
 	Subdepartments     []DepartmentWithHierarchyResponse  `json:"subdepartments"`
+	Headcount          *int64                             `json:"headcount,omitempty"`
+	TotalHeadcount     *int64                             `json:"total_headcount,omitempty"`
+	Employees          []HierarchyEmployeeResponse        `json:"employees,omitzero"`
+	MoreEmployees      bool                               `json:"more_employees,omitempty"`
 	CreatedAt          time.Time                          `json:"created_at"`
 	UpdatedAt          time.Time                          `json:"updated_at"`
 }
 
-// Error Response
-type ErrorResponse struct {
-	Error   string `json:"error"`
-	Message string `json:"message,omitempty"`
+// HierarchyEmployeeResponse is an employee listed in a department hierarchy
+type HierarchyEmployeeResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	IsManager bool      `json:"is_manager"`
+}
+
+// GetDepartmentRequest lists, in Include, the optional parts of a department
+// response as comma-separated names. EmployeesLimit caps the employees listed
+// per department when they are included.
+type GetDepartmentRequest struct {
+	Include        string `form:"include"`
+	EmployeesLimit int    `form:"employees_limit,default=20" binding:"min=1,max=100"`
+}
+
+// Optional parts of a department response
+const (
+	IncludeHeadcount = "headcount"
+	IncludeEmployees = "employees"
+)
+
+// DepartmentIncludes are the optional parts accepted by GetDepartmentRequest
+var DepartmentIncludes = []string{IncludeHeadcount, IncludeEmployees}
+
+// ParseIncludes splits a comma-separated include list into a set. It returns
+// the first name missing from allowed, if any.
+func ParseIncludes(value string, allowed []string) (map[string]bool, string) {
+	includes := make(map[string]bool)
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !slices.Contains(allowed, name) {
+			return nil, name
+		}
+		includes[name] = true
+	}
+	return includes, ""
+}
+
+type DepartmentAncestorResponse struct {
+	ID                 uuid.UUID  `json:"id"`
+	Name               string     `json:"name"`
+	ManagerID          uuid.UUID  `json:"manager_id"`
+	ManagerName        string     `json:"manager_name"`
+	ParentDepartmentID *uuid.UUID `json:"parent_department_id,omitempty"`
+	Depth              int        `json:"depth"`
+}
+
+// Department deletion
+type DeleteDepartmentRequest struct {
+	Strategy string `form:"strategy,default=restrict" binding:"oneof=restrict reparent cascade"`
+}
+
+type DeleteDepartmentResponse struct {
+	Strategy             string      `json:"strategy"`
+	DepartmentID         uuid.UUID   `json:"department_id"`
+	NewParentID          *uuid.UUID  `json:"new_parent_id,omitempty"`
+	DeletedDepartmentIDs []uuid.UUID `json:"deleted_department_ids"`
+	DeletedEmployees     int64       `json:"deleted_employees"`
+	MovedDepartmentIDs   []uuid.UUID `json:"moved_department_ids"`
+	MovedEmployees       int64       `json:"moved_employees"`
 }
 
 // Pagination Request
@@ -89,26 +199,103 @@ type PaginationRequest struct {
 	PageSize int `json:"page_size" binding:"min=1,max=100"`
 }
 
-// Employee List Request with filters
+// Employee List Request with filters. Sort takes fields of employee.SortFields
+// as in "name,-created_at". Page and PageSize are required unless the keyset
+// pagination fields of CursorRequest are used.
 type ListEmployeesRequest struct {
 	Name         *string    `json:"name,omitempty"`
 	CPF          *string    `json:"cpf,omitempty"`
 	RG           *string    `json:"rg,omitempty"`
 	DepartmentID *string    `json:"department_id,omitempty"`
-	Page         int        `json:"page" binding:"required,min=1"`
-	PageSize     int        `json:"page_size" binding:"required,min=1,max=100"`
+	Sort         string     `json:"sort,omitempty" example:"name,-created_at"`
+	Page         int        `json:"page,omitempty" binding:"omitempty,min=1"`
+	PageSize     int        `json:"page_size,omitempty" binding:"omitempty,min=1,max=100"`
+	CursorRequest
 }
 
-// Department List Request with filters
+// ExportEmployeesRequest takes the filters of ListEmployeesRequest as query
+// parameters; every matching employee is exported, without pagination
+type ExportEmployeesRequest struct {
+	Name         *string `form:"name"`
+	CPF          *string `form:"cpf"`
+	RG           *string `form:"rg"`
+	DepartmentID *string `form:"department_id"`
+	Format       string  `form:"format" binding:"omitempty,oneof=csv xlsx"`
+}
+
+// DepartmentFilters are the filters shared by department listings (JSON body)
+// and exports (query string). Name and manager_name match part of the name,
+// case-insensitive; root_only is the same as has_parent=false. Depth is the
+// distance from the root, which is at depth 0, headcounts count the active
+// employees of the department itself, and every bound is inclusive. Dates are
+// RFC 3339.
+type DepartmentFilters struct {
+	Name               *string    `json:"name,omitempty" form:"name"`
+	ManagerName        *string    `json:"manager_name,omitempty" form:"manager_name"`
+	ParentDepartmentID *string    `json:"parent_department_id,omitempty" form:"parent_department_id"`
+	HasParent          *bool      `json:"has_parent,omitempty" form:"has_parent"`
+	RootOnly           bool       `json:"root_only,omitempty" form:"root_only"`
+	MinDepth           *int       `json:"min_depth,omitempty" form:"min_depth" binding:"omitempty,min=0"`
+	MaxDepth           *int       `json:"max_depth,omitempty" form:"max_depth" binding:"omitempty,min=0"`
+	MinHeadcount       *int64     `json:"min_headcount,omitempty" form:"min_headcount" binding:"omitempty,min=0"`
+	MaxHeadcount       *int64     `json:"max_headcount,omitempty" form:"max_headcount" binding:"omitempty,min=0"`
+	CreatedFrom        *time.Time `json:"created_from,omitempty" form:"created_from" example:"2025-01-01T00:00:00Z"`
+	CreatedTo          *time.Time `json:"created_to,omitempty" form:"created_to"`
+	UpdatedFrom        *time.Time `json:"updated_from,omitempty" form:"updated_from"`
+	UpdatedTo          *time.Time `json:"updated_to,omitempty" form:"updated_to"`
+}
+
+// Department List Request with filters. Sort takes fields of
+// department.SortFields as in "manager_name,name". Page and PageSize are
+// required unless the keyset pagination fields of CursorRequest are used.
 type ListDepartmentsRequest struct {
-	Name                 *string `json:"name,omitempty"`
-	ManagerName          *string `json:"manager_name,omitempty"`
-	ParentDepartmentID   *string `json:"parent_department_id,omitempty"`
-	Page                 int     `json:"page" binding:"required,min=1"`
-	PageSize             int     `json:"page_size" binding:"required,min=1,max=100"`
+	DepartmentFilters
+	Sort                 string  `json:"sort,omitempty" example:"manager_name,name"`
+	Page                 int     `json:"page,omitempty" binding:"omitempty,min=1"`
+	PageSize             int     `json:"page_size,omitempty" binding:"omitempty,min=1,max=100"`
+	CursorRequest
+}
+
+// ExportDepartmentsRequest takes the filters of ListDepartmentsRequest as query
+// parameters; every matching department is exported, without pagination
+type ExportDepartmentsRequest struct {
+	DepartmentFilters
+	Format string `form:"format" binding:"omitempty,oneof=csv xlsx"`
+}
+
+// OrgChartRequest picks the output format of an org chart
+type OrgChartRequest struct {
+	Format string `form:"format,default=svg" binding:"oneof=svg dot mermaid"`
+}
+
+// Subordinate List Request with filters (query string)
+type ListSubordinatesRequest struct {
+	Name         *string `form:"name"`
+	DepartmentID *string `form:"department_id"`
+	DirectOnly   bool    `form:"direct_only"`
+	Page         int     `form:"page,default=1" binding:"min=1"`
+	PageSize     int     `form:"page_size,default=20" binding:"min=1,max=100"`
+}
+
+type SubordinateResponse struct {
+	ID             uuid.UUID `json:"id"`
+	Name           string    `json:"name"`
+	CPF            string    `json:"cpf"`
+	RG             *string   `json:"rg,omitempty"`
+	DepartmentID   uuid.UUID `json:"department_id"`
+	DepartmentName string    `json:"department_name"`
+	Direct         bool      `json:"direct"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
 }
 
 // Paginated Response
+// ListDeletedRequest pages through soft-deleted records
+type ListDeletedRequest struct {
+	Page     int `form:"page,default=1" binding:"min=1"`
+	PageSize int `form:"page_size,default=20" binding:"min=1,max=100"`
+}
+
 type PaginatedResponse struct {
 	Data       interface{} `json:"data"`
 	Page       int         `json:"page"`
@@ -136,22 +323,76 @@ func ToEmployeeEntityFromUpdate(req *UpdateEmployeeRequest) *employee.Employee {
 	}
 }
 
-func ToEmployeeResponse(emp *employee.Employee) *EmployeeResponse {
+// ToEmployeePatch keeps only the fields present in the request. null on a
+// required field becomes its zero value so the service reports it as missing.
+func ToEmployeePatch(req *PatchEmployeeRequest) employee.Patch {
+	var patch employee.Patch
+	if req.Name.Set {
+		patch.Name = &req.Name.String
+	}
+	if req.CPF.Set {
+		patch.CPF = &req.CPF.String
+	}
+	if req.RG.Set {
+		patch.RG = req.RG.ToStringPointer()
+		patch.RGSet = true
+	}
+	if req.DepartmentID.Set {
+		patch.DepartmentID = &req.DepartmentID.UUID
+	}
+	return patch
+}
+
+func ToEmployeeResponse(emp *employee.Employee, cpf CPFFormat) *EmployeeResponse {
 	return &EmployeeResponse{
 		ID:           emp.ID,
 		Name:         emp.Name,
-		CPF:          emp.CPF,
+		CPF:          cpf.Apply(emp.CPF),
 		RG:           emp.RG,
 		DepartmentID: emp.DepartmentID,
 		CreatedAt:    emp.CreatedAt,
 		UpdatedAt:    emp.UpdatedAt,
+		DeletedAt:    toDeletedAt(emp.DeletedAt),
+		AnonymizedAt: emp.AnonymizedAt,
 	}
 }
 
-func ToEmployeeResponseList(employees []employee.Employee) []EmployeeResponse {
+func ToEmployeeResponseList(employees []employee.Employee, cpf CPFFormat) []EmployeeResponse {
 	responses := make([]EmployeeResponse, len(employees))
 	for i, emp := range employees {
-		responses[i] = *ToEmployeeResponse(&emp)
+		responses[i] = *ToEmployeeResponse(&emp, cpf)
+	}
+	return responses
+}
+
+func ToReportingChainResponse(employeeID uuid.UUID, chain []employee.ReportingChainEntry) ReportingChainResponse {
+	entries := make([]ReportingChainEntryResponse, len(chain))
+	for i, entry := range chain {
+		entries[i] = ReportingChainEntryResponse{
+			Level:          entry.Level,
+			ID:             entry.ID,
+			Name:           entry.Name,
+			DepartmentID:   entry.DepartmentID,
+			DepartmentName: entry.DepartmentName,
+		}
+	}
+	return ReportingChainResponse{EmployeeID: employeeID, Chain: entries}
+}
+
+func ToSubordinateResponseList(subordinates []employee.Subordinate, cpf CPFFormat) []SubordinateResponse {
+	responses := make([]SubordinateResponse, len(subordinates))
+	for i, sub := range subordinates {
+		responses[i] = SubordinateResponse{
+			ID:             sub.ID,
+			Name:           sub.Name,
+			CPF:            cpf.Apply(sub.CPF),
+			RG:             sub.RG,
+			DepartmentID:   sub.DepartmentID,
+			DepartmentName: sub.DepartmentName,
+			Direct:         sub.Direct,
+			CreatedAt:      sub.CreatedAt,
+			UpdatedAt:      sub.UpdatedAt,
+		}
 	}
 	return responses
 }
@@ -173,6 +414,23 @@ func ToDepartmentEntityFromUpdate(req *UpdateDepartmentRequest) *department.Depa
 	}
 }
 
+// ToDepartmentPatch keeps only the fields present in the request. null on a
+// required field becomes its zero value so the service reports it as missing.
+func ToDepartmentPatch(req *PatchDepartmentRequest) department.Patch {
+	var patch department.Patch
+	if req.Name.Set {
+		patch.Name = &req.Name.String
+	}
+	if req.ManagerID.Set {
+		patch.ManagerID = &req.ManagerID.UUID
+	}
+	if req.ParentDepartmentID.Set {
+		patch.ParentDepartmentID = req.ParentDepartmentID.ToUUIDPointer()
+		patch.ParentSet = true
+	}
+	return patch
+}
+
 func ToDepartmentResponse(dept *department.Department) *DepartmentResponse {
 	return &DepartmentResponse{
 		ID:                 dept.ID,
@@ -181,7 +439,42 @@ func ToDepartmentResponse(dept *department.Department) *DepartmentResponse {
 		ParentDepartmentID: dept.ParentDepartmentID,
 		CreatedAt:          dept.CreatedAt,
 		UpdatedAt:          dept.UpdatedAt,
+		DeletedAt:          toDeletedAt(dept.DeletedAt),
+	}
+}
+
+func ToDepartmentAncestorResponseList(ancestors []department.DepartmentAncestor) []DepartmentAncestorResponse {
+	responses := make([]DepartmentAncestorResponse, len(ancestors))
+	for i, a := range ancestors {
+		responses[i] = DepartmentAncestorResponse{
+			ID:                 a.ID,
+			Name:               a.Name,
+			ManagerID:          a.ManagerID,
+			ManagerName:        a.ManagerName,
+			ParentDepartmentID: a.ParentDepartmentID,
+			Depth:              a.Depth,
+		}
 	}
+	return responses
+}
+
+func ToDeleteDepartmentResponse(summary *department.DeleteSummary) DeleteDepartmentResponse {
+	response := DeleteDepartmentResponse{
+		Strategy:             string(summary.Strategy),
+		DepartmentID:         summary.DepartmentID,
+		NewParentID:          summary.NewParentID,
+		DeletedDepartmentIDs: summary.DeletedDepartmentIDs,
+		DeletedEmployees:     summary.DeletedEmployees,
+		MovedDepartmentIDs:   summary.MovedDepartmentIDs,
+		MovedEmployees:       summary.MovedEmployees,
+	}
+	if response.DeletedDepartmentIDs == nil {
+		response.DeletedDepartmentIDs = []uuid.UUID{}
+	}
+	if response.MovedDepartmentIDs == nil {
+		response.MovedDepartmentIDs = []uuid.UUID{}
+	}
+	return response
 }
 
 func ToDepartmentResponseList(departments []department.Department) []DepartmentResponse {
@@ -191,3 +484,11 @@ func ToDepartmentResponseList(departments []department.Department) []DepartmentR
 	}
 	return responses
 }
+
+// toDeletedAt exposes the soft-delete timestamp only for deleted records
+func toDeletedAt(deletedAt gorm.DeletedAt) *time.Time {
+	if !deletedAt.Valid {
+		return nil
+	}
+	return &deletedAt.Time
+}
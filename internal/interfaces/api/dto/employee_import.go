@@ -0,0 +1,205 @@
+package dto
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"api-employees-and-departments/internal/domain/employee"
+	domainErrors "api-employees-and-departments/internal/domain/errors"
+)
+
+// ImportContentType is the media type of employee import files
+const ImportContentType = "text/csv"
+
+// MaxImportRows bounds the size of a single import
+const MaxImportRows = 5000
+
+// ImportEmployeesQuery controls how an import is applied: dry_run only
+// validates, and atomic=false creates the valid rows even if others fail
+type ImportEmployeesQuery struct {
+	DryRun bool  `form:"dry_run"`
+	Atomic *bool `form:"atomic"`
+}
+
+// ImportRowResponse is the outcome of one CSV row. Status is created, valid
+// (would be created, but the import was a dry run or was rejected) or invalid.
+type ImportRowResponse struct {
+	Line     int               `json:"line" example:"2"`
+	Status   string            `json:"status" example:"created"`
+	Employee *EmployeeResponse `json:"employee,omitempty"`
+	Errors   []FieldError      `json:"errors,omitempty"`
+}
+
+type ImportEmployeesResponse struct {
+	DryRun  bool                `json:"dry_run"`
+	Total   int                 `json:"total" example:"3"`
+	Valid   int                 `json:"valid" example:"2"`
+	Invalid int                 `json:"invalid" example:"1"`
+	Created int                 `json:"created" example:"0"`
+	Rows    []ImportRowResponse `json:"rows"`
+}
+
+// ImportRejectedProblem is returned when no row was written because of invalid
+// rows, with the same per-row report as a successful import
+type ImportRejectedProblem struct {
+	ProblemDetails
+	Report ImportEmployeesResponse `json:"report"`
+}
+
+// importColumns maps the accepted header names to the ImportRow field they fill
+var importColumns = map[string]string{
+	"name":            "name",
+	"nome":            "name",
+	"cpf":             "cpf",
+	"rg":              "rg",
+	"department":      "department",
+	"department_id":   "department",
+	"department_name": "department",
+	"departamento":    "department",
+}
+
+// ParseEmployeeImportCSV reads an import file. The first line is a header naming
+// the columns (name, cpf, rg and department, which takes an ID or a name), in
+// any order; rg is optional. Both comma and semicolon separated files are
+// accepted, the latter being what spreadsheets export in pt-BR locales.
+func ParseEmployeeImportCSV(r io.Reader) ([]employee.ImportRow, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	text := strings.TrimPrefix(string(content), "\ufeff")
+
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.Comma = detectDelimiter(text)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, importError("", "import.empty", nil, "the file has no rows")
+	}
+	if err != nil {
+		return nil, invalidCSV(err)
+	}
+
+	positions := make(map[string]int)
+	for i, name := range header {
+		if field, ok := importColumns[strings.ToLower(strings.TrimSpace(name))]; ok {
+			if _, dup := positions[field]; !dup {
+				positions[field] = i
+			}
+		}
+	}
+	for _, required := range []string{"name", "cpf", "department"} {
+		if _, ok := positions[required]; !ok {
+			return nil, importError(required, "import.missing_column",
+				domainErrors.Params{"column": required}, "missing column "+required)
+		}
+	}
+
+	column := func(record []string, field string) (string, bool) {
+		i, ok := positions[field]
+		if !ok || i >= len(record) {
+			return "", false
+		}
+		return record[i], true
+	}
+
+	var rows []employee.ImportRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, invalidCSV(err)
+		}
+		if isBlankRecord(record) {
+			continue
+		}
+		if len(rows) == MaxImportRows {
+			return nil, importError("", "import.too_many_rows",
+				domainErrors.Params{"max": MaxImportRows}, fmt.Sprintf("the file has more than %d rows", MaxImportRows))
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := employee.ImportRow{Line: line}
+		row.Name, _ = column(record, "name")
+		row.CPF, _ = column(record, "cpf")
+		row.Department, _ = column(record, "department")
+		if rg, ok := column(record, "rg"); ok && strings.TrimSpace(rg) != "" {
+			row.RG = &rg
+		}
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil, importError("", "import.empty", nil, "the file has no rows")
+	}
+	return rows, nil
+}
+
+// detectDelimiter picks semicolon when the header uses it and has no comma
+func detectDelimiter(text string) rune {
+	header, _, _ := strings.Cut(text, "\n")
+	if strings.Contains(header, ";") && !strings.Contains(header, ",") {
+		return ';'
+	}
+	return ','
+}
+
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func invalidCSV(err error) error {
+	line := 0
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		line = parseErr.Line
+	}
+	return importError("", "import.invalid_csv", domainErrors.Params{"line": line},
+		fmt.Sprintf("malformed CSV: %v", err))
+}
+
+func importError(field, code string, params domainErrors.Params, message string) error {
+	return &domainErrors.ValidationError{Field: field, Code: code, Params: params, Message: message}
+}
+
+// ToImportEmployeesResponse converts an import report; errors are rendered by
+// the caller so they can be localized
+func ToImportEmployeesResponse(report *employee.ImportReport, dryRun bool, cpf CPFFormat, fieldErrors func(error) FieldError) ImportEmployeesResponse {
+	response := ImportEmployeesResponse{
+		DryRun:  dryRun,
+		Total:   len(report.Rows),
+		Valid:   report.Valid,
+		Invalid: report.Invalid,
+		Created: report.Created,
+		Rows:    make([]ImportRowResponse, len(report.Rows)),
+	}
+
+	for i, row := range report.Rows {
+		item := ImportRowResponse{Line: row.Line, Status: "valid"}
+		switch {
+		case len(row.Errors) > 0:
+			item.Status = "invalid"
+			for _, err := range row.Errors {
+				item.Errors = append(item.Errors, fieldErrors(err))
+			}
+		case row.Created:
+			item.Status = "created"
+			item.Employee = ToEmployeeResponse(row.Employee, cpf)
+		}
+		response.Rows[i] = item
+	}
+
+	return response
+}
@@ -0,0 +1,32 @@
+package dto
+
+import (
+	"api-employees-and-departments/internal/domain/department"
+	"api-employees-and-departments/internal/domain/employee"
+)
+
+// EmployeeExportHeader names the columns of employee exports, after the fields
+// of EmployeeResponse
+var EmployeeExportHeader = []any{
+	"id", "name", "cpf", "rg", "department_id", "department_name", "manager_name", "created_at", "updated_at",
+}
+
+// ToEmployeeExportRecord lays out an employee in EmployeeExportHeader order
+func ToEmployeeExportRecord(row employee.ExportRow, cpf CPFFormat) []any {
+	return []any{
+		row.ID, row.Name, cpf.Apply(row.CPF), row.RG, row.DepartmentID, row.DepartmentName, row.ManagerName, row.CreatedAt, row.UpdatedAt,
+	}
+}
+
+// DepartmentExportHeader names the columns of department exports, after the
+// fields of DepartmentResponse
+var DepartmentExportHeader = []any{
+	"id", "name", "manager_id", "manager_name", "parent_department_id", "parent_department_name", "created_at", "updated_at",
+}
+
+// ToDepartmentExportRecord lays out a department in DepartmentExportHeader order
+func ToDepartmentExportRecord(row department.ExportRow) []any {
+	return []any{
+		row.ID, row.Name, row.ManagerID, row.ManagerName, row.ParentDepartmentID, row.ParentDepartmentName, row.CreatedAt, row.UpdatedAt,
+	}
+}
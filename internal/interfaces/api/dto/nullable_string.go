@@ -0,0 +1,45 @@
+package dto
+
+import "encoding/json"
+
+// NullableString is a string that tells apart a missing field, an explicit null
+// and a value, as JSON Merge Patch (RFC 7396) requires
+type NullableString struct {
+	String string
+	Valid  bool // Valid is true if the value is not null
+	Set    bool // Set is true if the field was present in the JSON payload (even as null)
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+// Accepts: null or a string
+func (ns *NullableString) UnmarshalJSON(data []byte) error {
+	ns.Set = true
+
+	if string(data) == "null" {
+		ns.Valid = false
+		ns.String = ""
+		return nil
+	}
+
+	if err := json.Unmarshal(data, &ns.String); err != nil {
+		return err
+	}
+	ns.Valid = true
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler
+func (ns NullableString) MarshalJSON() ([]byte, error) {
+	if !ns.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(ns.String)
+}
+
+// ToStringPointer converts NullableString to *string, nil for null
+func (ns NullableString) ToStringPointer() *string {
+	if !ns.Valid {
+		return nil
+	}
+	return &ns.String
+}
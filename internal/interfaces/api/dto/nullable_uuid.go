@@ -11,11 +11,14 @@ import (
 type NullableUUID struct {
 	UUID  uuid.UUID
 	Valid bool // Valid is true if UUID is not NULL or empty
+	Set   bool // Set is true if the field was present in the JSON payload (even as null)
 }
 
 // UnmarshalJSON implements json.Unmarshaler
 // Accepts: null, "", or a valid UUID string
 func (nu *NullableUUID) UnmarshalJSON(data []byte) error {
+	nu.Set = true
+
 	// Handle null
 	if string(data) == "null" {
 		nu.Valid = false
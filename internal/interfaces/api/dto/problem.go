@@ -0,0 +1,49 @@
+package dto
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProblemContentType is the media type of ProblemDetails responses (RFC 9457)
+const ProblemContentType = "application/problem+json"
+
+// ProblemDetails describes an error response as defined by RFC 9457
+type ProblemDetails struct {
+	// Type identifies the kind of problem, e.g. /problems/validation-error
+	Type   string `json:"type" example:"/problems/validation-error"`
+	Title  string `json:"title" example:"Unprocessable Entity"`
+	Status int    `json:"status" example:"422"`
+	Detail string `json:"detail,omitempty" example:"invalid CPF"`
+	// Instance is the request ID, to correlate the response with the logs
+	Instance string       `json:"instance,omitempty" example:"1730000000000000000"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError points to a single invalid field of the request
+type FieldError struct {
+	Field   string `json:"field" example:"cpf"`
+	Code    string `json:"code" example:"len"`
+	Message string `json:"message" example:"must be exactly 11 characters long"`
+}
+
+// NewProblem builds a ProblemDetails whose type is derived from code
+// (validation_error becomes /problems/validation-error)
+func NewProblem(status int, code, detail string) ProblemDetails {
+	return ProblemDetails{
+		Type:   "/problems/" + strings.ReplaceAll(code, "_", "-"),
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// RehireConflictProblem points to the soft-deleted record that shares the CPF
+type RehireConflictProblem struct {
+	ProblemDetails
+	PreviousEmployeeID uuid.UUID `json:"previous_employee_id"`
+	DeletedAt          time.Time `json:"deleted_at"`
+}
@@ -0,0 +1,49 @@
+package dto
+
+import (
+	"api-employees-and-departments/internal/domain/search"
+
+	"github.com/google/uuid"
+)
+
+// SearchRequest is a free-text search over employees and departments. Type
+// narrows it to one kind of record; Limit defaults to search.DefaultLimit.
+type SearchRequest struct {
+	Q     string `form:"q" binding:"required" example:"joao"`
+	Type  string `form:"type" binding:"omitempty,oneof=employee department"`
+	Limit int    `form:"limit" binding:"omitempty,min=1,max=50"`
+}
+
+// SearchResultResponse is a record found by a search. MatchedField is name,
+// cpf or rg, and Score goes from 0 to 1.
+type SearchResultResponse struct {
+	Type           string     `json:"type" example:"employee"`
+	ID             uuid.UUID  `json:"id"`
+	Name           string     `json:"name"`
+	MatchedField   string     `json:"matched_field" example:"name"`
+	Score          float64    `json:"score" example:"0.8"`
+	DepartmentID   *uuid.UUID `json:"department_id,omitempty"`
+	DepartmentName string     `json:"department_name,omitempty"`
+}
+
+// SearchResponse lists the results of a search, best matches first
+type SearchResponse struct {
+	Query string                 `json:"query"`
+	Data  []SearchResultResponse `json:"data"`
+}
+
+func ToSearchResponse(query string, results []search.Result) SearchResponse {
+	data := make([]SearchResultResponse, len(results))
+	for i, result := range results {
+		data[i] = SearchResultResponse{
+			Type:           string(result.Kind),
+			ID:             result.ID,
+			Name:           result.Name,
+			MatchedField:   result.MatchedField,
+			Score:          result.Score,
+			DepartmentID:   result.DepartmentID,
+			DepartmentName: result.DepartmentName,
+		}
+	}
+	return SearchResponse{Query: query, Data: data}
+}
@@ -0,0 +1,215 @@
+// Package export writes tabular data as CSV or XLSX spreadsheets
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xuri/excelize/v2"
+)
+
+// Format is a spreadsheet file format
+type Format string
+
+const (
+	CSV  Format = "csv"
+	XLSX Format = "xlsx"
+)
+
+// MIME types of the supported formats
+const (
+	CSVContentType  = "text/csv"
+	XLSXContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+// ContentType returns the media type of the format
+func (f Format) ContentType() string {
+	if f == XLSX {
+		return XLSXContentType
+	}
+	return CSVContentType + "; charset=utf-8"
+}
+
+// FormatFromContentType maps a negotiated media type back to its format
+func FormatFromContentType(contentType string) (Format, bool) {
+	switch contentType {
+	case CSVContentType:
+		return CSV, true
+	case XLSXContentType:
+		return XLSX, true
+	}
+	return "", false
+}
+
+// Writer receives the rows of a table, starting with the header. Values may be
+// strings, numbers, booleans, time.Time or nil; pointers are dereferenced.
+type Writer interface {
+	Write(values ...any) error
+	// Close writes anything still buffered. The output is only complete after it.
+	Close() error
+}
+
+// NewWriter returns a writer for format writing to w
+func NewWriter(format Format, w io.Writer, sheet string) (Writer, error) {
+	if format == XLSX {
+		return newXLSXWriter(w, sheet)
+	}
+	return newCSVWriter(w), nil
+}
+
+// csvWriter streams rows as they come, flushing regularly so large exports are
+// not held in memory
+type csvWriter struct {
+	out  *bufio.Writer
+	csv  *csv.Writer
+	rows int
+}
+
+// flushEvery is how many CSV rows are buffered before being sent
+const flushEvery = 500
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	out := bufio.NewWriter(w)
+	// The BOM makes spreadsheet applications read the file as UTF-8
+	_, _ = out.WriteString("\ufeff")
+	return &csvWriter{out: out, csv: csv.NewWriter(out)}
+}
+
+func (w *csvWriter) Write(values ...any) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		record[i] = formatValue(value)
+	}
+	if err := w.csv.Write(record); err != nil {
+		return err
+	}
+
+	w.rows++
+	if w.rows%flushEvery == 0 {
+		return w.flush()
+	}
+	return nil
+}
+
+func (w *csvWriter) Close() error {
+	return w.flush()
+}
+
+func (w *csvWriter) flush() error {
+	w.csv.Flush()
+	if err := w.csv.Error(); err != nil {
+		return err
+	}
+	return w.out.Flush()
+}
+
+// formatValue renders a cell of a CSV file
+func formatValue(value any) string {
+	switch v := deref(value).(type) {
+	case nil:
+		return ""
+	case string:
+		return escapeFormula(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// escapeFormula keeps spreadsheet applications from evaluating a text cell as a
+// formula (CSV injection) by prefixing it with an apostrophe
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// xlsxWriter builds a workbook with a single sheet. XLSX files are zip archives,
+// so the content is only sent on Close; excelize keeps large sheets on disk
+// meanwhile.
+type xlsxWriter struct {
+	out    io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+	date   int
+}
+
+func newXLSXWriter(w io.Writer, sheet string) (*xlsxWriter, error) {
+	file := excelize.NewFile()
+	if err := file.SetSheetName("Sheet1", sheet); err != nil {
+		return nil, err
+	}
+	stream, err := file.NewStreamWriter(sheet)
+	if err != nil {
+		return nil, err
+	}
+	date, err := file.NewStyle(&excelize.Style{NumFmt: 22}) // m/d/yy h:mm, localized by the reader
+	if err != nil {
+		return nil, err
+	}
+	return &xlsxWriter{out: w, file: file, stream: stream, date: date}, nil
+}
+
+func (w *xlsxWriter) Write(values ...any) error {
+	w.row++
+	cells := make([]any, len(values))
+	for i, value := range values {
+		switch v := deref(value).(type) {
+		case time.Time:
+			cells[i] = excelize.Cell{StyleID: w.date, Value: v.UTC()}
+		case nil:
+			cells[i] = nil
+		default:
+			cells[i] = v
+		}
+	}
+
+	cell, err := excelize.CoordinatesToCellName(1, w.row)
+	if err != nil {
+		return err
+	}
+	return w.stream.SetRow(cell, cells)
+}
+
+func (w *xlsxWriter) Close() error {
+	defer w.file.Close()
+	if err := w.stream.Flush(); err != nil {
+		return err
+	}
+	_, err := w.file.WriteTo(w.out)
+	return err
+}
+
+// deref unwraps the pointer types used by optional fields
+func deref(value any) any {
+	switch v := value.(type) {
+	case *string:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case *time.Time:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case *uuid.UUID:
+		if v == nil {
+			return nil
+		}
+		return v.String()
+	case time.Time:
+		return v
+	case fmt.Stringer:
+		return v.String()
+	}
+	return value
+}
@@ -0,0 +1,75 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(CSV, &buf, "employees")
+	if err != nil {
+		t.Fatalf("NewWriter() returned error: %v", err)
+	}
+
+	id := uuid.MustParse("019a35a2-0fa7-79a3-bf4b-231280e082f3")
+	created := time.Date(2025, 3, 1, 12, 30, 0, 0, time.FixedZone("BRT", -3*60*60))
+	var noRG *string
+	var noParent *uuid.UUID
+
+	_ = w.Write("id", "name", "rg", "parent", "created_at")
+	_ = w.Write(id, "Silva, João", noRG, noParent, created)
+	_ = w.Write(id, "=HYPERLINK(\"http://x\")", noRG, &id, created)
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() returned error: %v", err)
+	}
+
+	want := "\ufeffid,name,rg,parent,created_at\n" +
+		"019a35a2-0fa7-79a3-bf4b-231280e082f3,\"Silva, João\",,,2025-03-01T15:30:00Z\n" +
+		"019a35a2-0fa7-79a3-bf4b-231280e082f3,\"'=HYPERLINK(\"\"http://x\"\")\",,019a35a2-0fa7-79a3-bf4b-231280e082f3,2025-03-01T15:30:00Z\n"
+	if got := buf.String(); got != want {
+		t.Errorf("CSV output =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestXLSXWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(XLSX, &buf, "departments")
+	if err != nil {
+		t.Fatalf("NewWriter() returned error: %v", err)
+	}
+
+	_ = w.Write("name", "manager_name")
+	_ = w.Write("Tecnologia", "=1+1")
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() returned error: %v", err)
+	}
+
+	file, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatalf("output is not a valid XLSX file: %v", err)
+	}
+	defer file.Close()
+
+	rows, err := file.GetRows("departments")
+	if err != nil {
+		t.Fatalf("GetRows() returned error: %v", err)
+	}
+	if len(rows) != 2 || strings.Join(rows[1], "|") != "Tecnologia|=1+1" {
+		t.Errorf("rows = %v, want the header and one literal row", rows)
+	}
+}
+
+func TestFormatFromContentType(t *testing.T) {
+	if f, ok := FormatFromContentType(XLSXContentType); !ok || f != XLSX {
+		t.Errorf("FormatFromContentType(xlsx) = %q, %v", f, ok)
+	}
+	if _, ok := FormatFromContentType("application/json"); ok {
+		t.Error("FormatFromContentType(application/json) should not match")
+	}
+}
@@ -0,0 +1,400 @@
+package i18n
+
+import "net/http"
+
+// catalog holds the messages of every stable error code. Codes are part of the
+// API contract: add new ones freely, but never rename or reuse an existing one.
+var catalog = map[string]map[Lang]string{
+	// Request parsing
+	"request.invalid": {
+		English:    "The request is invalid",
+		Portuguese: "A requisição é inválida",
+	},
+	"request.invalid_fields": {
+		English:    "The request has invalid fields",
+		Portuguese: "A requisição possui campos inválidos",
+	},
+	"request.invalid_json": {
+		English:    "The request body is not valid JSON",
+		Portuguese: "O corpo da requisição não é um JSON válido",
+	},
+	"request.empty_body": {
+		English:    "The request body is empty",
+		Portuguese: "O corpo da requisição está vazio",
+	},
+	"request.unsupported_media_type": {
+		English:    "Content-Type must be {type}",
+		Portuguese: "o Content-Type deve ser {type}",
+	},
+	"request.not_acceptable": {
+		English:    "the response can only be sent as {types}",
+		Portuguese: "a resposta só pode ser enviada como {types}",
+	},
+	"request.invalid_include": {
+		English:    "unknown include {value}; expected one of {allowed}",
+		Portuguese: "include desconhecido {value}; use um de {allowed}",
+	},
+	"request.invalid_sort": {
+		English:    "cannot sort by {value}; use each of {allowed} at most once, prefixed with - for descending order",
+		Portuguese: "não é possível ordenar por {value}; use cada um de {allowed} no máximo uma vez, com - na frente para ordem decrescente",
+	},
+	"request.invalid_cpf_format": {
+		English:    "unknown cpf_format {value}; expected one of {allowed}",
+		Portuguese: "cpf_format desconhecido {value}; use um de {allowed}",
+	},
+	"cpf.access_denied": {
+		English:    "full CPFs are only shown to API keys with access to personal data; use cpf_format=masked",
+		Portuguese: "CPFs completos só são exibidos para chaves de API com acesso a dados pessoais; use cpf_format=masked",
+	},
+	"filter.pii_access_denied": {
+		English:    "filtering by CPF or RG needs an API key with access to personal data",
+		Portuguese: "filtrar por CPF ou RG exige uma chave de API com acesso a dados pessoais",
+	},
+	"pii.access_required": {
+		English:    "this operation needs an API key with access to personal data",
+		Portuguese: "esta operação exige uma chave de API com acesso a dados pessoais",
+	},
+	"request.invalid_id": {
+		English:    "invalid {resource} ID format",
+		Portuguese: "o ID informado não é um UUID válido",
+	},
+	"pagination.page_required": {
+		English:    "page and page_size are required unless after, before or limit is sent",
+		Portuguese: "page e page_size são obrigatórios, a menos que after, before ou limit seja enviado",
+	},
+	"pagination.mixed_modes": {
+		English:    "page/page_size cannot be combined with after, before or limit",
+		Portuguese: "page/page_size não podem ser combinados com after, before ou limit",
+	},
+	"pagination.after_and_before": {
+		English:    "after and before cannot be sent together",
+		Portuguese: "after e before não podem ser enviados juntos",
+	},
+	"pagination.invalid_cursor": {
+		English:    "invalid cursor",
+		Portuguese: "cursor inválido",
+	},
+	"filter.invalid_department_id": {
+		English:    "invalid department ID format",
+		Portuguese: "o ID de departamento do filtro não é um UUID válido",
+	},
+	"filter.invalid_parent_department_id": {
+		English:    "invalid parent department ID format",
+		Portuguese: "o ID de departamento superior do filtro não é um UUID válido",
+	},
+	"filter.root_only_conflict": {
+		English:    "root_only cannot be combined with has_parent=true or parent_department_id",
+		Portuguese: "root_only não pode ser combinado com has_parent=true ou parent_department_id",
+	},
+	"filter.invalid_range": {
+		English:    "{min} cannot be greater than {max}",
+		Portuguese: "{min} não pode ser maior que {max}",
+	},
+	"search.query_too_short": {
+		English:    "the search query must have at least {min} characters",
+		Portuguese: "a busca deve ter pelo menos {min} caracteres",
+	},
+	"internal_error": {
+		English:    "An unexpected error occurred",
+		Portuguese: "Ocorreu um erro inesperado",
+	},
+
+	// Field validation (gin binding)
+	"validation.required": {
+		English:    "is required",
+		Portuguese: "é obrigatório",
+	},
+	"validation.len": {
+		English:    "must be exactly {param}",
+		Portuguese: "deve ser exatamente {param}",
+	},
+	"validation.len_chars": {
+		English:    "must be exactly {param} characters long",
+		Portuguese: "deve ter exatamente {param} caracteres",
+	},
+	"validation.min": {
+		English:    "must be at least {param}",
+		Portuguese: "deve ser no mínimo {param}",
+	},
+	"validation.min_chars": {
+		English:    "must be at least {param} characters long",
+		Portuguese: "deve ter no mínimo {param} caracteres",
+	},
+	"validation.max": {
+		English:    "must be at most {param}",
+		Portuguese: "deve ser no máximo {param}",
+	},
+	"validation.max_chars": {
+		English:    "must be at most {param} characters long",
+		Portuguese: "deve ter no máximo {param} caracteres",
+	},
+	"validation.oneof": {
+		English:    "must be one of: {param}",
+		Portuguese: "deve ser um de: {param}",
+	},
+	"validation.uuid": {
+		English:    "must be a valid UUID",
+		Portuguese: "deve ser um UUID válido",
+	},
+	"validation.type": {
+		English:    "must be of type {type}",
+		Portuguese: "deve ser do tipo {type}",
+	},
+	"validation.failed": {
+		English:    "failed the {tag} validation",
+		Portuguese: "falhou na validação {tag}",
+	},
+
+	// Persistence
+	"employee.not_found": {
+		English:    "employee not found",
+		Portuguese: "colaborador não encontrado",
+	},
+	"department.not_found": {
+		English:    "department not found",
+		Portuguese: "departamento não encontrado",
+	},
+	"deleted_employee.not_found": {
+		English:    "deleted employee not found",
+		Portuguese: "colaborador removido não encontrado",
+	},
+	"deleted_department.not_found": {
+		English:    "deleted department not found",
+		Portuguese: "departamento removido não encontrado",
+	},
+	"employee.already_exists": {
+		English:    "employee already exists",
+		Portuguese: "colaborador já existe",
+	},
+	"department.already_exists": {
+		English:    "department already exists",
+		Portuguese: "departamento já existe",
+	},
+	"employee.in_use": {
+		English:    "employee is still referenced by other records",
+		Portuguese: "colaborador ainda é referenciado por outros registros",
+	},
+	"department.in_use": {
+		English:    "department is still referenced by other records",
+		Portuguese: "departamento ainda é referenciado por outros registros",
+	},
+	"employee.version_mismatch": {
+		English:    "employee was modified by another request",
+		Portuguese: "o colaborador foi alterado por outra requisição",
+	},
+	"department.version_mismatch": {
+		English:    "department was modified by another request",
+		Portuguese: "o departamento foi alterado por outra requisição",
+	},
+	"reference.not_found": {
+		English:    "referenced {reference} does not exist",
+		Portuguese: "o registro referenciado não existe",
+	},
+
+	// Employees
+	"employee.invalid_id": {
+		English:    "invalid employee id",
+		Portuguese: "ID de colaborador inválido",
+	},
+	"manager.invalid_id": {
+		English:    "invalid manager id",
+		Portuguese: "ID de gerente inválido",
+	},
+	"employee.name_required": {
+		English:    "employee name is required",
+		Portuguese: "o nome do colaborador é obrigatório",
+	},
+	"employee.cpf_required": {
+		English:    "employee CPF is required",
+		Portuguese: "o CPF do colaborador é obrigatório",
+	},
+	"employee.department_required": {
+		English:    "employee department is required",
+		Portuguese: "o departamento do colaborador é obrigatório",
+	},
+	"employee.department_not_found": {
+		English:    "department not found",
+		Portuguese: "departamento não encontrado",
+	},
+	"employee.department_gone": {
+		English:    "department no longer exists",
+		Portuguese: "o departamento não existe mais",
+	},
+	"employee.purge_requires_deleted": {
+		English:    "deleted employee not found (only soft-deleted employees can be purged)",
+		Portuguese: "colaborador removido não encontrado (apenas colaboradores removidos podem ser excluídos definitivamente)",
+	},
+	"employee.still_manages_departments": {
+		English:    "employee still manages {count} departments, deleted ones included",
+		Portuguese: "o colaborador ainda gerencia {count} departamento(s), incluindo removidos",
+	},
+	"employee.anonymize_requires_deleted": {
+		English:    "only deleted employees can be anonymized; delete the employee first",
+		Portuguese: "apenas colaboradores removidos podem ser anonimizados; remova o colaborador primeiro",
+	},
+	"employee.already_anonymized": {
+		English:    "employee is already anonymized",
+		Portuguese: "o colaborador já foi anonimizado",
+	},
+	"employee.retention_period": {
+		English:    "the retention period of the employee data ends at {until}",
+		Portuguese: "o período de retenção dos dados do colaborador termina em {until}",
+	},
+	"employee.anonymized": {
+		English:    "anonymized employees cannot be restored",
+		Portuguese: "colaboradores anonimizados não podem ser restaurados",
+	},
+	"employee.previous_record_exists": {
+		English:    "a deleted employee with this CPF already exists ({previous_id}); restore it or create a new record",
+		Portuguese: "já existe um colaborador removido com este CPF ({previous_id}); restaure-o ou crie um novo registro",
+	},
+	"cpf.invalid": {
+		English:    "invalid CPF",
+		Portuguese: "CPF inválido",
+	},
+	"cpf.duplicate": {
+		English:    "CPF already exists",
+		Portuguese: "CPF já cadastrado",
+	},
+	"rg.duplicate": {
+		English:    "RG already exists",
+		Portuguese: "RG já cadastrado",
+	},
+
+	// Employee import
+	"import.file_required": {
+		English:    "the CSV must be sent in the file field",
+		Portuguese: "o CSV deve ser enviado no campo file",
+	},
+	"import.too_large": {
+		English:    "the file is larger than {max} bytes",
+		Portuguese: "o arquivo é maior que {max} bytes",
+	},
+	"import.invalid_csv": {
+		English:    "malformed CSV at line {line}",
+		Portuguese: "CSV malformado na linha {line}",
+	},
+	"import.empty": {
+		English:    "the file has no rows",
+		Portuguese: "o arquivo não possui linhas",
+	},
+	"import.missing_column": {
+		English:    "missing column {column}",
+		Portuguese: "coluna {column} ausente",
+	},
+	"import.too_many_rows": {
+		English:    "the file has more than {max} rows",
+		Portuguese: "o arquivo possui mais de {max} linhas",
+	},
+	"import.department_not_found": {
+		English:    "department {department} not found",
+		Portuguese: "departamento {department} não encontrado",
+	},
+	"import.department_ambiguous": {
+		English:    "{count} departments are named {department}; use the department ID",
+		Portuguese: "{count} departamentos se chamam {department}; use o ID do departamento",
+	},
+	"import.cpf_repeated": {
+		English:    "same CPF as line {line}",
+		Portuguese: "mesmo CPF da linha {line}",
+	},
+	"import.rg_repeated": {
+		English:    "same RG as line {line}",
+		Portuguese: "mesmo RG da linha {line}",
+	},
+	"import.rejected": {
+		English:    "{invalid} rows are invalid; no employee was imported",
+		Portuguese: "{invalid} linha(s) inválida(s); nenhum colaborador foi importado",
+	},
+
+	// Org chart labels
+	"orgchart.manager": {
+		English:    "Manager: {name}",
+		Portuguese: "Gerente: {name}",
+	},
+	"orgchart.headcount": {
+		English:    "{count} employees",
+		Portuguese: "{count} colaborador(es)",
+	},
+	"orgchart.total_headcount": {
+		English:    "{count} in total",
+		Portuguese: "{count} no total",
+	},
+
+	// Departments
+	"department.invalid_id": {
+		English:    "invalid department id",
+		Portuguese: "ID de departamento inválido",
+	},
+	"department.name_required": {
+		English:    "department name is required",
+		Portuguese: "o nome do departamento é obrigatório",
+	},
+	"department.manager_required": {
+		English:    "department manager is required",
+		Portuguese: "o gerente do departamento é obrigatório",
+	},
+	"department.manager_not_found": {
+		English:    "manager not found",
+		Portuguese: "gerente não encontrado",
+	},
+	"department.manager_not_in_department": {
+		English:    "manager must be linked to the same department",
+		Portuguese: "o gerente deve estar vinculado ao mesmo departamento",
+	},
+	"department.parent_not_found": {
+		English:    "parent department not found",
+		Portuguese: "departamento superior não encontrado",
+	},
+	"department.parent_gone": {
+		English:    "parent department no longer exists; restore or move it first",
+		Portuguese: "o departamento superior não existe mais; restaure-o ou mova este departamento antes",
+	},
+	"department.parent_required": {
+		English:    "parent_department_id is required (use null to move to the root)",
+		Portuguese: "parent_department_id é obrigatório (use null para mover para a raiz)",
+	},
+	"department.own_parent": {
+		English:    "department cannot be its own parent",
+		Portuguese: "um departamento não pode ser superior de si mesmo",
+	},
+	"department.cycle_detected": {
+		English:    "cycle detected in department hierarchy",
+		Portuguese: "ciclo detectado na hierarquia de departamentos",
+	},
+	"department.invalid_delete_strategy": {
+		English:    `invalid delete strategy "{strategy}" (use restrict, reparent or cascade)`,
+		Portuguese: `estratégia de remoção "{strategy}" inválida (use restrict, reparent ou cascade)`,
+	},
+	"department.delete_restricted": {
+		English:    "department still has {employees} employees and {children} sub-departments; use the reparent or cascade strategy",
+		Portuguese: "o departamento ainda possui {employees} colaborador(es) e {children} subdepartamento(s); use a estratégia reparent ou cascade",
+	},
+	"department.root_employees_not_reparentable": {
+		English:    "employees of a root department cannot be reparented; move them first or use the cascade strategy",
+		Portuguese: "colaboradores de um departamento raiz não podem ser movidos para o pai; mova-os antes ou use a estratégia cascade",
+	},
+	"department.purge_requires_deleted": {
+		English:    "deleted department not found (only soft-deleted departments can be purged)",
+		Portuguese: "departamento removido não encontrado (apenas departamentos removidos podem ser excluídos definitivamente)",
+	},
+	"department.still_referenced": {
+		English:    "department is still referenced by {employees} employees and {children} sub-departments (including deleted ones); purge them first",
+		Portuguese: "o departamento ainda é referenciado por {employees} colaborador(es) e {children} subdepartamento(s) (incluindo removidos); exclua-os definitivamente antes",
+	},
+}
+
+// titles translates the reason phrases used as problem titles
+var titles = map[Lang]map[int]string{
+	Portuguese: {
+		http.StatusBadRequest:           "Requisição inválida",
+		http.StatusForbidden:            "Proibido",
+		http.StatusNotFound:             "Não encontrado",
+		http.StatusNotAcceptable:        "Não aceitável",
+		http.StatusConflict:             "Conflito",
+		http.StatusPreconditionFailed:   "Pré-condição falhou",
+		http.StatusUnsupportedMediaType: "Tipo de mídia não suportado",
+		http.StatusUnprocessableEntity:  "Entidade não processável",
+		http.StatusInternalServerError:  "Erro interno do servidor",
+	},
+}
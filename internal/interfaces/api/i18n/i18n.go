@@ -0,0 +1,118 @@
+package i18n
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Lang is a language tag supported by the catalog
+type Lang string
+
+const (
+	English    Lang = "en"
+	Portuguese Lang = "pt-BR"
+
+	// Default is used when the client does not ask for a supported language
+	Default = English
+)
+
+// Supported lists the languages every catalog entry is translated to
+var Supported = []Lang{English, Portuguese}
+
+// FromAcceptLanguage picks the supported language the client prefers, honoring
+// q-values (RFC 9110). Regional variants fall back to their base language, so
+// pt-PT gets pt-BR and en-GB gets en.
+func FromAcceptLanguage(header string) Lang {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	var ranges []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+		ranges = append(ranges, weighted{tag: tag, q: q})
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].q > ranges[j].q })
+
+	for _, r := range ranges {
+		if r.tag == "*" {
+			return Default
+		}
+		if lang, ok := match(r.tag); ok {
+			return lang
+		}
+	}
+	return Default
+}
+
+// match finds the supported language for a lowercase tag, first exactly and
+// then by its primary subtag
+func match(tag string) (Lang, bool) {
+	for _, lang := range Supported {
+		if strings.ToLower(string(lang)) == tag {
+			return lang, true
+		}
+	}
+	primary, _, _ := strings.Cut(tag, "-")
+	for _, lang := range Supported {
+		base, _, _ := strings.Cut(strings.ToLower(string(lang)), "-")
+		if base == primary {
+			return lang, true
+		}
+	}
+	return "", false
+}
+
+// Message returns the translation of code, filling {name} placeholders from
+// params. fallback is returned when the code is not in the catalog, so callers
+// can pass the original English message.
+func Message(lang Lang, code string, params map[string]any, fallback string) string {
+	translations, ok := catalog[code]
+	if !ok {
+		return fallback
+	}
+	text, ok := translations[lang]
+	if !ok {
+		text = translations[Default]
+	}
+
+	if len(params) == 0 {
+		return text
+	}
+	pairs := make([]string, 0, len(params)*2)
+	for name, value := range params {
+		pairs = append(pairs, "{"+name+"}", fmt.Sprint(value))
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+// Title returns the localized reason phrase of an HTTP status
+func Title(lang Lang, status int) string {
+	if lang != English {
+		if title, ok := titles[lang][status]; ok {
+			return title
+		}
+	}
+	return http.StatusText(status)
+}
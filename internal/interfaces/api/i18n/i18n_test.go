@@ -0,0 +1,56 @@
+package i18n
+
+import "testing"
+
+func TestFromAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		expected Lang
+	}{
+		{name: "empty header", header: "", expected: English},
+		{name: "exact pt-BR", header: "pt-BR", expected: Portuguese},
+		{name: "case insensitive", header: "PT-br", expected: Portuguese},
+		{name: "base language", header: "pt", expected: Portuguese},
+		{name: "other region", header: "pt-PT", expected: Portuguese},
+		{name: "english region", header: "en-GB", expected: English},
+		{name: "q-values", header: "en;q=0.5, pt-BR;q=0.9", expected: Portuguese},
+		{name: "unsupported first", header: "fr-FR, pt;q=0.8, en;q=0.7", expected: Portuguese},
+		{name: "excluded language", header: "pt-BR;q=0, en", expected: English},
+		{name: "wildcard", header: "*", expected: English},
+		{name: "unsupported only", header: "de, fr", expected: English},
+		{name: "invalid q-value", header: "pt-BR;q=abc, en", expected: English},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FromAcceptLanguage(tt.header); got != tt.expected {
+				t.Errorf("FromAcceptLanguage(%q) = %q, want %q", tt.header, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMessage(t *testing.T) {
+	params := map[string]any{"employees": 2, "children": 1}
+
+	got := Message(Portuguese, "department.delete_restricted", params, "fallback")
+	want := "o departamento ainda possui 2 colaborador(es) e 1 subdepartamento(s); use a estratégia reparent ou cascade"
+	if got != want {
+		t.Errorf("Message() = %q, want %q", got, want)
+	}
+
+	if got := Message(Portuguese, "unknown.code", nil, "fallback"); got != "fallback" {
+		t.Errorf("Message() for unknown code = %q, want fallback", got)
+	}
+}
+
+func TestCatalogIsComplete(t *testing.T) {
+	for code, translations := range catalog {
+		for _, lang := range Supported {
+			if translations[lang] == "" {
+				t.Errorf("code %q has no %s translation", code, lang)
+			}
+		}
+	}
+}
@@ -0,0 +1,122 @@
+// Package orgchart renders a department tree as Graphviz DOT, Mermaid or SVG
+package orgchart
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Format is an org chart output format
+type Format string
+
+const (
+	DOT     Format = "dot"
+	Mermaid Format = "mermaid"
+	SVG     Format = "svg"
+)
+
+// ContentType returns the media type of the format
+func (f Format) ContentType() string {
+	switch f {
+	case DOT:
+		return "text/vnd.graphviz; charset=utf-8"
+	case Mermaid:
+		return "text/plain; charset=utf-8"
+	}
+	return "image/svg+xml"
+}
+
+// Node is a box of the chart: a title followed by detail lines
+type Node struct {
+	Title    string
+	Details  []string
+	Children []*Node
+}
+
+// Render writes the chart of the tree rooted at root in format
+func Render(w io.Writer, format Format, root *Node) error {
+	out := bufio.NewWriter(w)
+	switch format {
+	case DOT:
+		renderDOT(out, root)
+	case Mermaid:
+		renderMermaid(out, root)
+	case SVG:
+		renderSVG(out, root)
+	default:
+		return fmt.Errorf("unsupported org chart format %q", format)
+	}
+	return out.Flush()
+}
+
+// walk visits the tree depth-first, numbering the nodes in visiting order
+func walk(root *Node, visit func(id int, node *Node, parent int)) {
+	next := 0
+	var visitNode func(node *Node, parent int)
+	visitNode = func(node *Node, parent int) {
+		id := next
+		next++
+		visit(id, node, parent)
+		for _, child := range node.Children {
+			visitNode(child, id)
+		}
+	}
+	visitNode(root, -1)
+}
+
+func renderDOT(out *bufio.Writer, root *Node) {
+	out.WriteString("digraph orgchart {\n")
+	out.WriteString("  graph [rankdir=TB, splines=ortho, nodesep=0.4];\n")
+	out.WriteString("  node [shape=box, style=\"rounded,filled\", fillcolor=\"#f5f7fa\", color=\"#4a5568\", fontname=\"Helvetica\"];\n")
+	out.WriteString("  edge [color=\"#4a5568\", arrowhead=none];\n")
+
+	var edges []string
+	walk(root, func(id int, node *Node, parent int) {
+		lines := append([]string{node.Title}, node.Details...)
+		for i, line := range lines {
+			lines[i] = dotEscape(line)
+		}
+		fmt.Fprintf(out, "  n%d [label=\"%s\"];\n", id, strings.Join(lines, `\n`))
+		if parent >= 0 {
+			edges = append(edges, fmt.Sprintf("  n%d -> n%d;\n", parent, id))
+		}
+	})
+	for _, edge := range edges {
+		out.WriteString(edge)
+	}
+	out.WriteString("}\n")
+}
+
+// dotEscape escapes a value for a double-quoted DOT string
+func dotEscape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", " ", "\r", "").Replace(value)
+}
+
+func renderMermaid(out *bufio.Writer, root *Node) {
+	out.WriteString("flowchart TD\n")
+
+	var edges []string
+	walk(root, func(id int, node *Node, parent int) {
+		lines := []string{"<b>" + mermaidEscape(node.Title) + "</b>"}
+		for _, detail := range node.Details {
+			lines = append(lines, mermaidEscape(detail))
+		}
+		fmt.Fprintf(out, "  n%d[\"%s\"]\n", id, strings.Join(lines, "<br/>"))
+		if parent >= 0 {
+			edges = append(edges, fmt.Sprintf("  n%d --> n%d\n", parent, id))
+		}
+	})
+	for _, edge := range edges {
+		out.WriteString(edge)
+	}
+}
+
+// mermaidEscape replaces the characters that would end or alter a quoted
+// Mermaid label with their entity codes
+func mermaidEscape(value string) string {
+	return strings.NewReplacer(
+		`"`, "#quot;", "<", "#lt;", ">", "#gt;", "&", "#amp;", "#", "#35;", "\n", " ", "\r", "",
+	).Replace(value)
+}
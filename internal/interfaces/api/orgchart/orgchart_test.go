@@ -0,0 +1,112 @@
+package orgchart
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+func sampleTree() *Node {
+	return &Node{
+		Title:   "Diretoria",
+		Details: []string{"Manager: Ana", "2 employees"},
+		Children: []*Node{
+			{Title: `Tecnologia "TI"`, Details: []string{"Manager: Bruno", "5 employees"}, Children: []*Node{
+				{Title: "Plataforma", Details: []string{"1 employees"}},
+				{Title: "Dados & BI", Details: []string{"3 employees"}},
+			}},
+			{Title: "Finanças <Corp>", Details: []string{"0 employees"}},
+		},
+	}
+}
+
+func render(t *testing.T, format Format) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Render(&buf, format, sampleTree()); err != nil {
+		t.Fatalf("Render(%s) returned error: %v", format, err)
+	}
+	return buf.String()
+}
+
+func TestRenderDOT(t *testing.T) {
+	out := render(t, DOT)
+
+	for _, want := range []string{
+		"digraph orgchart {",
+		`n0 [label="Diretoria\nManager: Ana\n2 employees"];`,
+		`n1 [label="Tecnologia \"TI\"\nManager: Bruno\n5 employees"];`,
+		"n0 -> n1;", "n1 -> n2;", "n1 -> n3;", "n0 -> n4;",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("DOT output is missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRenderMermaid(t *testing.T) {
+	out := render(t, Mermaid)
+
+	for _, want := range []string{
+		"flowchart TD\n",
+		`n1["<b>Tecnologia #quot;TI#quot;</b><br/>Manager: Bruno<br/>5 employees"]`,
+		`n4["<b>Finanças #lt;Corp#gt;</b><br/>0 employees"]`,
+		"n1 --> n3",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Mermaid output is missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRenderSVG(t *testing.T) {
+	out := render(t, SVG)
+
+	// The document must be well-formed XML with one box per department
+	decoder := xml.NewDecoder(strings.NewReader(out))
+	boxes := 0
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("SVG is not well-formed: %v\n%s", err, out)
+		}
+		if start, ok := token.(xml.StartElement); ok && start.Name.Local == "rect" {
+			boxes++
+		}
+	}
+	// One rect is the background
+	if boxes != 6 {
+		t.Errorf("SVG has %d boxes, expected 5 departments and the background", boxes-1)
+	}
+	if !strings.Contains(out, "Dados &amp; BI") {
+		t.Error("SVG should escape text")
+	}
+}
+
+func TestLayoutCentersParents(t *testing.T) {
+	root, width := layout(sampleTree(), 60)
+
+	tech := root.children[0]
+	if tech.x != (tech.children[0].x+tech.children[1].x)/2 {
+		t.Errorf("parent at %.1f is not centered over its children", tech.x)
+	}
+	if root.children[1].x <= tech.children[1].x {
+		t.Error("siblings should not overlap")
+	}
+	// three leaves side by side
+	if want := float64(2*margin + 3*boxWidth + 2*gapX); width != want {
+		t.Errorf("width = %.0f, want %.0f", width, want)
+	}
+}
+
+func TestSVGTextIsTruncated(t *testing.T) {
+	got := svgText(strings.Repeat("a", maxChars+10))
+	if n := len([]rune(got)); n != maxChars {
+		t.Errorf("svgText() kept %d characters, want %d", n, maxChars)
+	}
+}
@@ -0,0 +1,116 @@
+package orgchart
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Box and spacing sizes of the SVG layout, in pixels
+const (
+	boxWidth   = 220
+	padding    = 10
+	lineHeight = 18
+	fontSize   = 13
+	gapX       = 24
+	gapY       = 48
+	margin     = 20
+
+	// maxChars is roughly how many characters of the font fit in a box
+	maxChars = (boxWidth - 2*padding) / 7
+)
+
+// placed is a node with its position, x being the center of its box
+type placed struct {
+	node     *Node
+	x, y     float64
+	children []*placed
+}
+
+// layout positions the tree top-down: leaves are laid out left to right and
+// every parent is centered above its children. It returns the placed root and
+// the width of the chart.
+func layout(root *Node, boxHeight float64) (*placed, float64) {
+	nextLeft := float64(margin)
+
+	var place func(node *Node, depth int) *placed
+	place = func(node *Node, depth int) *placed {
+		p := &placed{node: node, y: margin + float64(depth)*(boxHeight+gapY)}
+		if len(node.Children) == 0 {
+			p.x = nextLeft + boxWidth/2
+			nextLeft += boxWidth + gapX
+			return p
+		}
+		for _, child := range node.Children {
+			p.children = append(p.children, place(child, depth+1))
+		}
+		p.x = (p.children[0].x + p.children[len(p.children)-1].x) / 2
+		return p
+	}
+
+	placedRoot := place(root, 0)
+	return placedRoot, nextLeft - gapX + margin
+}
+
+func renderSVG(out *bufio.Writer, root *Node) {
+	lines := 1
+	depth := 0
+	var measure func(node *Node, level int)
+	measure = func(node *Node, level int) {
+		lines = max(lines, 1+len(node.Details))
+		depth = max(depth, level)
+		for _, child := range node.Children {
+			measure(child, level+1)
+		}
+	}
+	measure(root, 0)
+
+	boxHeight := float64(2*padding + lines*lineHeight)
+	placedRoot, width := layout(root, boxHeight)
+	height := 2*margin + float64(depth+1)*boxHeight + float64(depth)*gapY
+
+	fmt.Fprintf(out, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f" font-family="Helvetica, Arial, sans-serif" font-size="%d">`+"\n",
+		width, height, width, height, fontSize)
+	out.WriteString(`<rect width="100%" height="100%" fill="#ffffff"/>` + "\n")
+
+	var draw func(p *placed)
+	draw = func(p *placed) {
+		bottom := p.y + boxHeight
+		for _, child := range p.children {
+			middle := bottom + gapY/2
+			fmt.Fprintf(out, `<path d="M %.1f %.1f V %.1f H %.1f V %.1f" fill="none" stroke="#4a5568" stroke-width="1.5"/>`+"\n",
+				p.x, bottom, middle, child.x, child.y)
+		}
+
+		left := p.x - boxWidth/2
+		fmt.Fprintf(out, `<g><rect x="%.1f" y="%.1f" width="%d" height="%.0f" rx="6" fill="#f5f7fa" stroke="#4a5568" stroke-width="1.5"/>`,
+			left, p.y, boxWidth, boxHeight)
+		baseline := p.y + padding + fontSize
+		fmt.Fprintf(out, `<text x="%.1f" y="%.1f" text-anchor="middle" font-weight="bold" fill="#1a202c">%s</text>`,
+			p.x, baseline, svgText(p.node.Title))
+		for i, detail := range p.node.Details {
+			fmt.Fprintf(out, `<text x="%.1f" y="%.1f" text-anchor="middle" fill="#4a5568">%s</text>`,
+				p.x, baseline+float64((i+1)*lineHeight), svgText(detail))
+		}
+		out.WriteString("</g>\n")
+
+		for _, child := range p.children {
+			draw(child)
+		}
+	}
+	draw(placedRoot)
+
+	out.WriteString("</svg>\n")
+}
+
+// svgText shortens a line to fit its box and escapes it for XML
+func svgText(value string) string {
+	if utf8.RuneCountInString(value) > maxChars {
+		value = string([]rune(value)[:maxChars-1]) + "…"
+	}
+	var escaped strings.Builder
+	_ = xml.EscapeText(&escaped, []byte(value))
+	return escaped.String()
+}